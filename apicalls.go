@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// errAPICallBudget fails the requests made after --max-api-calls was used up.
+// retryWithBackoff returns it without retrying, and the circuit breaker skips
+// the projects it hits, so the run ends with what was fetched so far.
+var errAPICallBudget = errors.New("API call budget used up")
+
+// apiCallCounter counts the API requests of a run per endpoint category, for
+// the summary --debug prints at the end.
+type apiCallCounter struct {
+	mu         sync.Mutex
+	categories map[string]*apiCallCategory
+	// sent counts the requests let through (or refused) for --max-api-calls.
+	sent atomic.Int64
+}
+
+type apiCallCategory struct {
+	Calls    int
+	Failed   int
+	Duration time.Duration
+}
+
+func (c *apiCallCounter) record(category string, failed bool, duration time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.categories == nil {
+		c.categories = make(map[string]*apiCallCategory)
+	}
+	stats := c.categories[category]
+	if stats == nil {
+		stats = &apiCallCategory{}
+		c.categories[category] = stats
+	}
+	stats.Calls++
+	stats.Duration += duration
+	if failed {
+		stats.Failed++
+	}
+}
+
+// snapshot returns the categories by name.
+func (c *apiCallCounter) snapshot() map[string]apiCallCategory {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	snapshot := make(map[string]apiCallCategory, len(c.categories))
+	for name, stats := range c.categories {
+		snapshot[name] = *stats
+	}
+	return snapshot
+}
+
+// apiCallCategoryOf sorts a request path into the category it is counted
+// under. Nested resources count as the innermost one that costs a call per
+// item: a merge request's notes are "notes", its approval state "approvals".
+func apiCallCategoryOf(path string) string {
+	path = strings.ToLower(path)
+	switch {
+	case strings.HasSuffix(path, "/graphql"):
+		return "graphql"
+	case strings.HasPrefix(path, "/search/") || strings.Contains(path, "/api/v3/search/"):
+		return "search"
+	case strings.Contains(path, "/notes") || strings.Contains(path, "/discussions") || strings.Contains(path, "/comments") || strings.Contains(path, "/reviews"):
+		return "notes"
+	case strings.Contains(path, "/approval"):
+		return "approvals"
+	case strings.Contains(path, "/merge_requests") || strings.Contains(path, "/pulls"):
+		return "merge requests"
+	case strings.Contains(path, "/issues"):
+		return "issues"
+	case strings.Contains(path, "/labels"):
+		return "labels"
+	case strings.Contains(path, "/projects") || strings.HasPrefix(path, "/repos") || strings.HasPrefix(path, "/api/v3/repos"):
+		return "projects"
+	case strings.Contains(path, "/user") || strings.Contains(path, "/personal_access_tokens"):
+		return "user"
+	}
+	return "other"
+}
+
+// apiCallTransport counts every request in config.apiCalls and refuses those
+// past config.maxAPICalls.
+type apiCallTransport struct {
+	next http.RoundTripper
+}
+
+func (t apiCallTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if limit := int64(config.maxAPICalls); limit > 0 {
+		if sent := config.apiCalls.sent.Add(1); sent > limit {
+			if sent == limit+1 {
+				if config.debugMode {
+					fmt.Printf("  [API] All %d calls of --max-api-calls used, skipping the remaining requests\n", limit)
+				}
+				logRun(slog.LevelWarn, "api call budget used up", "max_api_calls", limit)
+			}
+			return nil, fmt.Errorf("%w (--max-api-calls %d)", errAPICallBudget, limit)
+		}
+	}
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	failed := err != nil || resp.StatusCode >= http.StatusBadRequest
+	config.apiCalls.record(apiCallCategoryOf(req.URL.Path), failed, time.Since(start))
+	return resp, err
+}
+
+// writeAPICallSummary prints the request counts per category, busiest first,
+// with how many failed (retries included) and the time spent waiting on them.
+func writeAPICallSummary(out io.Writer, categories map[string]apiCallCategory) {
+	if len(categories) == 0 {
+		return
+	}
+	names := make([]string, 0, len(categories))
+	total := apiCallCategory{}
+	for name, stats := range categories {
+		names = append(names, name)
+		total.Calls += stats.Calls
+		total.Failed += stats.Failed
+		total.Duration += stats.Duration
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if categories[names[i]].Calls != categories[names[j]].Calls {
+			return categories[names[i]].Calls > categories[names[j]].Calls
+		}
+		return names[i] < names[j]
+	})
+
+	fmt.Fprintf(out, "API calls:\n")
+	fmt.Fprintf(out, "  %-16s %7s %7s %10s\n", "Category", "Calls", "Failed", "Time")
+	for _, name := range names {
+		stats := categories[name]
+		fmt.Fprintf(out, "  %-16s %7d %7d %10s\n", name, stats.Calls, stats.Failed, stats.Duration.Round(time.Millisecond))
+	}
+	fmt.Fprintf(out, "  %-16s %7d %7d %10s\n", "total", total.Calls, total.Failed, total.Duration.Round(time.Millisecond))
+}
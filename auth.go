@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+	"golang.org/x/term"
+)
+
+const keyringService = "git-feed"
+
+// keyringAccount names the keyring entry for a platform. GitLab tokens are
+// stored per instance, so a self-managed host and gitlab.com can both be
+// logged in at the same time.
+func keyringAccount(platform, gitlabBaseURL string) string {
+	if platform == "gitlab" {
+		return "gitlab:" + gitlabBaseURL
+	}
+	return "github"
+}
+
+// keyringToken returns the stored token, or "" when none is stored. An error
+// usually means no keyring is available (e.g. Linux without Secret Service).
+func keyringToken(platform, gitlabBaseURL string) (string, error) {
+	token, err := keyring.Get(keyringService, keyringAccount(platform, gitlabBaseURL))
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(token), nil
+}
+
+func platformDisplayName(platform string) string {
+	if platform == "gitlab" {
+		return "GitLab"
+	}
+	return "GitHub"
+}
+
+// runAuthCommand implements `auth login` and `auth logout`. The token is read
+// without echo from a terminal, or as the first line of piped input.
+func runAuthCommand(args []string, platform, gitlabBaseURL string, in *os.File, w io.Writer) error {
+	usage := fmt.Errorf("usage: %s [--platform github|gitlab] auth login|logout", filepath.Base(os.Args[0]))
+	if len(args) != 1 {
+		return usage
+	}
+
+	account := keyringAccount(platform, gitlabBaseURL)
+	target := platformDisplayName(platform)
+	if platform == "gitlab" {
+		target += " (" + gitlabBaseURL + ")"
+	}
+
+	switch args[0] {
+	case "login":
+		token, err := readToken(in, bufio.NewReader(in), w, fmt.Sprintf("%s token: ", target))
+		if err != nil {
+			return err
+		}
+		if err := keyring.Set(keyringService, account, token); err != nil {
+			return fmt.Errorf("failed to store token in the system keyring: %w", err)
+		}
+		fmt.Fprintf(w, "Stored %s token in the system keyring\n", target)
+	case "logout":
+		if err := keyring.Delete(keyringService, account); err != nil {
+			if errors.Is(err, keyring.ErrNotFound) {
+				return fmt.Errorf("no %s token stored in the system keyring", target)
+			}
+			return fmt.Errorf("failed to remove token from the system keyring: %w", err)
+		}
+		fmt.Fprintf(w, "Removed %s token from the system keyring\n", target)
+	default:
+		return usage
+	}
+	return nil
+}
+
+// readToken reads a token without echo from a terminal, or otherwise as the
+// next line of lines, which reads from in.
+func readToken(in *os.File, lines *bufio.Reader, w io.Writer, prompt string) (string, error) {
+	var token string
+	if term.IsTerminal(int(in.Fd())) {
+		fmt.Fprint(w, prompt)
+		raw, err := term.ReadPassword(int(in.Fd()))
+		fmt.Fprintln(w)
+		if err != nil {
+			return "", fmt.Errorf("failed to read token: %w", err)
+		}
+		token = string(raw)
+	} else {
+		line, err := lines.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", fmt.Errorf("failed to read token: %w", err)
+		}
+		token = line
+	}
+
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return "", fmt.Errorf("no token given")
+	}
+	return token, nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// browserCommand is the command that opens url: $BROWSER when set, otherwise
+// the platform's URL handler.
+func browserCommand(url string) *exec.Cmd {
+	if browser := strings.TrimSpace(os.Getenv("BROWSER")); browser != "" {
+		return exec.Command(browser, url)
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", url)
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		return exec.Command("xdg-open", url)
+	}
+}
+
+// openInBrowser starts the browser without waiting for it to exit.
+func openInBrowser(url string) error {
+	if url == "" {
+		return fmt.Errorf("the item has no URL")
+	}
+	cmd := browserCommand(url)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to open %s: %w", url, err)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+	bolt "go.etcd.io/bbolt"
+	"golang.org/x/crypto/nacl/secretbox"
+)
+
+// cacheKeyAccount is the keyring entry holding the cache encryption key, next
+// to the tokens stored by `auth login`.
+const cacheKeyAccount = "cache-encryption-key"
+
+// encryptedValuePrefix marks a sealed value. JSON never starts with it, so
+// plaintext values written before encryption was enabled are still readable.
+var encryptedValuePrefix = []byte("gfenc1")
+
+var encryptionMetaKey = []byte("encryption")
+
+var errCacheEncrypted = errors.New("the cache is encrypted; set CACHE_ENCRYPTION=on to read it or run with --clean to start over")
+
+// resolveCacheEncryption reads CACHE_ENCRYPTION (on/off, default off).
+func resolveCacheEncryption() (bool, error) {
+	switch value := strings.ToLower(strings.TrimSpace(os.Getenv("CACHE_ENCRYPTION"))); value {
+	case "", "off", "false", "0":
+		return false, nil
+	case "on", "true", "1":
+		return true, nil
+	default:
+		return false, fmt.Errorf("invalid CACHE_ENCRYPTION %q (allowed: on, off)", value)
+	}
+}
+
+// loadCacheKey reads the cache key from the system keyring and creates it on
+// first use. The key never touches the disk, so a copied cache file is
+// unreadable without the keyring it was written with.
+func loadCacheKey() (*[32]byte, error) {
+	encoded, err := keyring.Get(keyringService, cacheKeyAccount)
+	if errors.Is(err, keyring.ErrNotFound) {
+		var key [32]byte
+		if _, err := rand.Read(key[:]); err != nil {
+			return nil, fmt.Errorf("failed to generate cache key: %w", err)
+		}
+		if err := keyring.Set(keyringService, cacheKeyAccount, base64.StdEncoding.EncodeToString(key[:])); err != nil {
+			return nil, fmt.Errorf("failed to store cache key in the system keyring: %w", err)
+		}
+		return &key, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache key from the system keyring: %w", err)
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(decoded) != 32 {
+		return nil, fmt.Errorf("invalid cache key in the system keyring (%s/%s)", keyringService, cacheKeyAccount)
+	}
+	var key [32]byte
+	copy(key[:], decoded)
+	return &key, nil
+}
+
+// sealCacheValue encrypts value with a fresh random nonce, stored in front of
+// the ciphertext.
+func sealCacheValue(key *[32]byte, value []byte) ([]byte, error) {
+	var nonce [24]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := make([]byte, 0, len(encryptedValuePrefix)+len(nonce)+len(value)+secretbox.Overhead)
+	sealed = append(sealed, encryptedValuePrefix...)
+	sealed = append(sealed, nonce[:]...)
+	return secretbox.Seal(sealed, value, &nonce, key), nil
+}
+
+// openCacheValue returns plaintext values unchanged and decrypts sealed ones.
+func openCacheValue(key *[32]byte, value []byte) ([]byte, error) {
+	if !bytes.HasPrefix(value, encryptedValuePrefix) {
+		return value, nil
+	}
+	if key == nil {
+		return nil, errCacheEncrypted
+	}
+
+	sealed := value[len(encryptedValuePrefix):]
+	if len(sealed) < 24 {
+		return nil, errors.New("truncated encrypted cache value")
+	}
+	var nonce [24]byte
+	copy(nonce[:], sealed[:24])
+	plaintext, ok := secretbox.Open(nil, sealed[24:], &nonce, key)
+	if !ok {
+		return nil, errors.New("failed to decrypt cache value (was the keyring entry replaced?)")
+	}
+	return plaintext, nil
+}
+
+// prepareCacheEncryption matches the file to the setting. A plaintext cache
+// opened with a key is encrypted in place, once; an encrypted cache opened
+// without one is refused rather than having plaintext mixed into it.
+func prepareCacheEncryption(tx *bolt.Tx, key *[32]byte) error {
+	meta := tx.Bucket(metaBkt)
+	encrypted := meta.Get(encryptionMetaKey) != nil
+	switch {
+	case key == nil && encrypted:
+		return errCacheEncrypted
+	case key == nil || encrypted:
+		return nil
+	}
+
+	for _, bucket := range [][]byte{gitlabMergeRequestsBkt, gitlabIssuesBkt, gitlabNotesBkt, githubPullRequestsBkt, githubIssuesBkt, githubCommentsBkt, gitlabProjectsBkt, gitlabApprovalsBkt} {
+		b := tx.Bucket(bucket)
+		plaintext := make(map[string][]byte)
+		err := b.ForEach(func(k, v []byte) error {
+			if !bytes.HasPrefix(v, encryptedValuePrefix) {
+				plaintext[string(k)] = append([]byte(nil), v...)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		// Values are rewritten after the scan; a bucket cannot be modified
+		// while it is being iterated.
+		for k, v := range plaintext {
+			sealed, err := sealCacheValue(key, v)
+			if err != nil {
+				return err
+			}
+			if err := b.Put([]byte(k), sealed); err != nil {
+				return err
+			}
+		}
+	}
+	return meta.Put(encryptionMetaKey, []byte("secretbox"))
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// syncCheckpointTTL is how long an interrupted run's checkpoints are resumed
+// from. Older ones are ignored, so a run after a long break fetches everything
+// again instead of trusting labels derived days ago.
+const syncCheckpointTTL = 24 * time.Hour
+
+var syncCheckpointsKey = []byte("sync_checkpoints")
+
+// syncCheckpointBatch is how many labeled items advance the checkpoints
+// before they are written to the cache; flush writes the rest once a
+// listing page is done.
+const syncCheckpointBatch = 50
+
+// syncCheckpoint is how far an online fetch got in one project. Items are
+// labeled in order of their update time, so every listed merge request updated
+// up to MergeRequests (and every issue updated up to Issues) is already in
+// the cache, labeled, for the window starting at Cutoff.
+type syncCheckpoint struct {
+	Cutoff        time.Time `json:"cutoff"`
+	MergeRequests time.Time `json:"merge_requests"`
+	Issues        time.Time `json:"issues"`
+	SavedAt       time.Time `json:"saved_at"`
+}
+
+// syncCheckpoints tracks the checkpoints of a GitLab fetch. The checkpoints of
+// the previous run are only left behind when it was interrupted or failed;
+// resuming from one lists the project's items updated after it and takes the
+// older ones from the cache, so the pages already worked through are not
+// fetched again.
+type syncCheckpoints struct {
+	db      Database
+	resumed map[string]syncCheckpoint
+	current map[string]syncCheckpoint
+	// pending counts the advances not yet written to the cache.
+	pending int
+	// The cached items of resumed projects, by lowercased project path.
+	cachedMRs    map[string][]PRActivity
+	cachedIssues map[string][]IssueActivity
+}
+
+// loadSyncCheckpoints reads the checkpoints left by an unfinished run. A nil
+// db (no cache) disables checkpoints.
+func loadSyncCheckpoints(db Database, now time.Time) *syncCheckpoints {
+	if db == nil {
+		return nil
+	}
+	c := &syncCheckpoints{db: db, resumed: map[string]syncCheckpoint{}, current: map[string]syncCheckpoint{}}
+	saved, err := db.SyncCheckpoints()
+	if err != nil {
+		recordDBWarning("Failed to read sync checkpoints: %v", err)
+		return c
+	}
+	for path, checkpoint := range saved {
+		if now.Sub(checkpoint.SavedAt) <= syncCheckpointTTL {
+			c.resumed[path] = checkpoint
+		}
+	}
+	return c
+}
+
+// resumeFrom returns the checkpoint projectPath resumes from, if the previous
+// run left one that covers the window starting at cutoff. It only reads the
+// previous run's checkpoints, so the listing goroutine may call it.
+func (c *syncCheckpoints) resumeFrom(projectPath string, cutoff time.Time) (syncCheckpoint, bool) {
+	if c == nil {
+		return syncCheckpoint{}, false
+	}
+	checkpoint, ok := c.resumed[normalizeProjectPathWithNamespace(projectPath)]
+	if !ok || checkpoint.Cutoff.After(cutoff) {
+		return syncCheckpoint{}, false
+	}
+	return checkpoint, true
+}
+
+// listingCutoffs are the update times a project's merge requests and issues
+// are listed from: cutoff, or the checkpoint when resuming.
+func (c *syncCheckpoints) listingCutoffs(projectPath string, cutoff time.Time) (time.Time, time.Time) {
+	checkpoint, ok := c.resumeFrom(projectPath, cutoff)
+	if !ok {
+		return cutoff, cutoff
+	}
+	return laterTime(cutoff, checkpoint.MergeRequests), laterTime(cutoff, checkpoint.Issues)
+}
+
+// start begins the checkpoint of a project about to be labeled.
+func (c *syncCheckpoints) start(projectPath string, cutoff time.Time) {
+	if c == nil {
+		return
+	}
+	checkpoint, ok := c.resumeFrom(projectPath, cutoff)
+	if !ok {
+		checkpoint = syncCheckpoint{Cutoff: cutoff}
+	}
+	c.current[normalizeProjectPathWithNamespace(projectPath)] = checkpoint
+}
+
+// advance records that the project's merge requests ("mr") or issues
+// ("issue") updated up to updatedAt are labeled and cached. It writes the
+// checkpoints every syncCheckpointBatch items; see flush.
+func (c *syncCheckpoints) advance(projectPath, itemType string, updatedAt time.Time) {
+	if c == nil {
+		return
+	}
+	path := normalizeProjectPathWithNamespace(projectPath)
+	checkpoint := c.current[path]
+	if itemType == "mr" {
+		checkpoint.MergeRequests = laterTime(checkpoint.MergeRequests, updatedAt)
+	} else {
+		checkpoint.Issues = laterTime(checkpoint.Issues, updatedAt)
+	}
+	checkpoint.SavedAt = time.Now()
+	c.current[path] = checkpoint
+	c.pending++
+	if c.pending >= syncCheckpointBatch {
+		c.flush()
+	}
+}
+
+// flush writes the checkpoints advanced since the last write. The fetch calls
+// it after each listing page, so an interrupted run loses at most the items of
+// the page it was working through.
+func (c *syncCheckpoints) flush() {
+	if c == nil || c.pending == 0 {
+		return
+	}
+	c.pending = 0
+	if err := c.db.SetSyncCheckpoints(c.current); err != nil {
+		recordDBWarning("Failed to save sync checkpoints: %v", err)
+	}
+}
+
+// finish drops the checkpoints once the fetch is complete. Those of projects
+// that failed are kept, so the next run resumes them.
+func (c *syncCheckpoints) finish(failures []projectFailure) {
+	if c == nil {
+		return
+	}
+	kept := make(map[string]syncCheckpoint)
+	for _, failure := range failures {
+		path := normalizeProjectPathWithNamespace(failure.Project)
+		if checkpoint, ok := c.current[path]; ok {
+			kept[path] = checkpoint
+		} else if checkpoint, ok := c.resumed[path]; ok {
+			kept[path] = checkpoint
+		}
+	}
+	c.pending = 0
+	if err := c.db.SetSyncCheckpoints(kept); err != nil {
+		recordDBWarning("Failed to clear sync checkpoints: %v", err)
+	}
+}
+
+// resumedItems returns the cached items of a resumed project that were labeled
+// up to its checkpoint and are still inside cutoff. Items at the checkpoint
+// may be listed again; the caller skips those it already has.
+func (c *syncCheckpoints) resumedItems(projectPath string, cutoff time.Time) ([]PRActivity, []IssueActivity) {
+	checkpoint, ok := c.resumeFrom(projectPath, cutoff)
+	if !ok {
+		return nil, nil
+	}
+	if c.cachedMRs == nil {
+		activities, issueActivities, err := loadGitLabCachedItems(c.db, time.Time{})
+		if err != nil {
+			recordDBWarning("Failed to read cached items to resume from: %v", err)
+		}
+		c.cachedMRs = make(map[string][]PRActivity)
+		c.cachedIssues = make(map[string][]IssueActivity)
+		for _, activity := range activities {
+			path := strings.ToLower(gitLabProjectPath(activity.Owner, activity.Repo))
+			c.cachedMRs[path] = append(c.cachedMRs[path], activity)
+		}
+		for _, issue := range issueActivities {
+			path := strings.ToLower(gitLabProjectPath(issue.Owner, issue.Repo))
+			c.cachedIssues[path] = append(c.cachedIssues[path], issue)
+		}
+	}
+
+	path := strings.ToLower(normalizeProjectPathWithNamespace(projectPath))
+	var activities []PRActivity
+	for _, activity := range c.cachedMRs[path] {
+		if !activity.UpdatedAt.Before(cutoff) && !activity.UpdatedAt.After(checkpoint.MergeRequests) {
+			activities = append(activities, activity)
+		}
+	}
+	var issueActivities []IssueActivity
+	for _, issue := range c.cachedIssues[path] {
+		if !issue.UpdatedAt.Before(cutoff) && !issue.UpdatedAt.After(checkpoint.Issues) {
+			issueActivities = append(issueActivities, issue)
+		}
+	}
+	return activities, issueActivities
+}
+
+// logResume reports a project resumed from a checkpoint.
+func logResume(projectPath string, checkpoint syncCheckpoint) {
+	if config.debugMode {
+		fmt.Printf("  [GitLab] Resuming %s from the last run (merge requests from %s, issues from %s)\n",
+			projectPath, formatCheckpointTime(checkpoint.MergeRequests), formatCheckpointTime(checkpoint.Issues))
+	}
+	logRun(slog.LevelInfo, "project resumed", "project", projectPath,
+		"merge_requests", checkpoint.MergeRequests, "issues", checkpoint.Issues)
+}
+
+func formatCheckpointTime(t time.Time) string {
+	if t.IsZero() {
+		return "the start"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// sortGitLabItemsByUpdate orders a listing oldest update first, the order
+// checkpoints rely on.
+func sortGitLabItemsByUpdate(mergeRequests []*gitlab.BasicMergeRequest, issues []*gitlab.Issue) {
+	mergeRequestUpdate := func(item *gitlab.BasicMergeRequest) time.Time {
+		if item == nil || item.UpdatedAt == nil {
+			return time.Time{}
+		}
+		return *item.UpdatedAt
+	}
+	issueUpdate := func(item *gitlab.Issue) time.Time {
+		if item == nil || item.UpdatedAt == nil {
+			return time.Time{}
+		}
+		return *item.UpdatedAt
+	}
+	sort.SliceStable(mergeRequests, func(i, j int) bool {
+		return mergeRequestUpdate(mergeRequests[i]).Before(mergeRequestUpdate(mergeRequests[j]))
+	})
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issueUpdate(issues[i]).Before(issueUpdate(issues[j]))
+	})
+}
+
+func laterTime(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+func earlierTime(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
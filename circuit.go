@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+)
+
+// maxConsecutiveServerErrors bounds how often retryWithBackoff retries a call
+// that keeps failing with 5xx. Rate limits are still waited out indefinitely.
+const maxConsecutiveServerErrors = 5
+
+var (
+	errGitLabServerUnavailable = errors.New("GitLab kept returning server errors")
+	errGitHubServerUnavailable = errors.New("GitHub kept returning server errors")
+)
+
+// projectCircuitBreaker stops calling a project once one of its API calls gave
+// up on server errors, or once fetching it failed otherwise, so a single
+// broken project is skipped instead of stalling or aborting the whole run. It
+// is not safe for concurrent use.
+type projectCircuitBreaker struct {
+	open map[string]error
+}
+
+func newProjectCircuitBreaker() *projectCircuitBreaker {
+	return &projectCircuitBreaker{open: make(map[string]error)}
+}
+
+// trip opens the breaker for projectPath when err is a server-unavailable
+// error and reports whether it did; other errors are left to the caller.
+func (b *projectCircuitBreaker) trip(projectPath string, err error) bool {
+	if !errors.Is(err, errGitLabServerUnavailable) {
+		return false
+	}
+	return b.skip(projectPath, err)
+}
+
+// skip opens the breaker for projectPath after any error that leaves the
+// project out of the feed or cuts it short, so the run goes on with the other
+// projects, and reports whether it did. Cancellation ends the whole run and is
+// left to the caller, as is everything on a nil breaker.
+func (b *projectCircuitBreaker) skip(projectPath string, err error) bool {
+	if b == nil || err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	key := strings.ToLower(normalizeProjectPathWithNamespace(projectPath))
+	if _, exists := b.open[key]; exists {
+		return true
+	}
+	config.apiErrorCount.Add(1)
+	if config.debugMode {
+		fmt.Printf("  [GitLab] Skipping the rest of %s: %v\n", projectPath, err)
+	}
+	logRun(slog.LevelError, "project skipped", "project", projectPath, "error", err.Error())
+	b.open[key] = err
+	return true
+}
+
+func (b *projectCircuitBreaker) isOpen(projectPath string) bool {
+	_, open := b.open[strings.ToLower(normalizeProjectPathWithNamespace(projectPath))]
+	return open
+}
+
+// projectFailure is a project skipped by the breaker and the error that
+// opened it.
+type projectFailure struct {
+	Project string
+	Err     error
+}
+
+func (b *projectCircuitBreaker) failures() []projectFailure {
+	failures := make([]projectFailure, 0, len(b.open))
+	for project, err := range b.open {
+		failures = append(failures, projectFailure{Project: project, Err: err})
+	}
+	sort.Slice(failures, func(i, j int) bool { return failures[i].Project < failures[j].Project })
+	return failures
+}
+
+// allFailed returns an error when each of the requested projects was skipped:
+// a feed without any project is an outage (e.g. a revoked token), not a
+// partial result.
+func (b *projectCircuitBreaker) allFailed(requested int) error {
+	if requested == 0 || len(b.open) < requested {
+		return nil
+	}
+	first := b.failures()[0]
+	return fmt.Errorf("all %d projects failed (%s: %w)", requested, first.Project, first.Err)
+}
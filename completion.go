@@ -0,0 +1,291 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type completionFlag struct {
+	Name   string
+	Usage  string
+	IsBool bool
+	Values []string
+	List   bool
+}
+
+type completionCommand struct {
+	Name   string
+	Usage  string
+	Args   []string
+	Flags  []string
+	Values map[string][]string
+}
+
+// completionData collects everything the generated scripts offer. Project
+// paths come from the cache databases, so completions only know projects the
+// feed has already seen.
+type completionData struct {
+	Flags    []completionFlag
+	Commands []completionCommand
+}
+
+func completionLabelKeys() []string {
+	keys := make([]string, 0, len(labelGroupOrder))
+	for _, label := range labelGroupOrder {
+		keys = append(keys, strings.ReplaceAll(normalizeLabelKey(label), " ", "-")+"=")
+	}
+	return keys
+}
+
+func buildCompletionData(flags *flag.FlagSet, projects []string) completionData {
+	values := map[string][]string{
+		"platform":      platformNames(),
+		"state":         {"open", "closed", "merged"},
+		"group-by":      {"project", "label"},
+		"api":           {"rest", "graphql"},
+		"github-source": {"search", "notifications"},
+		"digest":        {"weekly"},
+		"tz":            {"local", "UTC"},
+		"sla":           completionLabelKeys(),
+		"allowed-repos": projects,
+		"exclude-repos": projects,
+	}
+	lists := map[string]bool{"state": true, "sla": true, "allowed-repos": true, "exclude-repos": true}
+
+	var data completionData
+	flags.VisitAll(func(f *flag.Flag) {
+		boolFlag, ok := f.Value.(interface{ IsBoolFlag() bool })
+		data.Flags = append(data.Flags, completionFlag{
+			Name:   f.Name,
+			Usage:  f.Usage,
+			IsBool: ok && boolFlag.IsBoolFlag(),
+			Values: values[f.Name],
+			List:   lists[f.Name],
+		})
+	})
+
+	data.Commands = []completionCommand{
+		{Name: "merge", Usage: "Merge a GitLab MR after approval, pipeline and conflict checks", Flags: []string{"when-pipeline-succeeds"}},
+		{Name: "share", Usage: "Upload the feed as a GitLab snippet", Flags: []string{"visibility"}, Values: map[string][]string{"visibility": {"private", "internal", "public"}}},
+		{Name: "report", Usage: "Summarize open GitLab MRs across the allowed projects", Args: []string{"reviewers", "latency"}},
+		{Name: "doctor", Usage: "Check the GitLab setup and suggest fixes"},
+		{Name: "export", Usage: "Print the feed as JSON", Flags: []string{"anonymize"}},
+		{Name: "sync", Usage: "Update the cache without printing the feed"},
+		{Name: "web", Usage: "Serve the cached feed as a web dashboard", Flags: []string{"listen", "refresh"}},
+		{Name: "pick", Usage: "Fuzzy-find an item and open it in the browser", Flags: []string{"copy"}},
+		{Name: "open", Usage: "Open item N of the last feed in the browser", Flags: []string{"copy"}},
+		{Name: "completion", Usage: "Generate a shell completion script", Args: []string{"bash", "zsh", "fish"}},
+		{Name: "config", Usage: "Read or change config.yaml", Args: []string{"get", "set", "unset", "list"}, Flags: []string{"show-secrets"}},
+		{Name: "auth", Usage: "Store or remove the token in the system keyring", Args: []string{"login", "logout"}},
+		{Name: "clean", Usage: "Delete cached items older than the retention", Flags: []string{"older-than"}},
+		{Name: "db", Usage: "Maintain the cache database", Args: []string{"compact", "stats", "metrics"}},
+	}
+	return data
+}
+
+// cachedCompletionProjects reads project paths from whichever cache databases
+// exist (including per-profile ones), without creating new ones. Encrypted
+// caches are skipped, since opening them needs the keyring.
+func cachedCompletionProjects(configDir string) []string {
+	seen := make(map[string]bool)
+	paths, _ := filepath.Glob(filepath.Join(configDir, "*.db"))
+	sqlitePaths, _ := filepath.Glob(filepath.Join(configDir, "*.sqlite"))
+	paths = append(paths, sqlitePaths...)
+	for _, path := range paths {
+		db, err := OpenDatabase(path, nil)
+		if err != nil {
+			continue
+		}
+		projects, err := db.CachedProjectPaths()
+		_ = db.Close()
+		if err != nil {
+			continue
+		}
+		for _, project := range projects {
+			seen[project] = true
+		}
+	}
+
+	projects := make([]string, 0, len(seen))
+	for project := range seen {
+		projects = append(projects, project)
+	}
+	sort.Strings(projects)
+	return projects
+}
+
+func runCompletionCommand(args []string, configDir string, w io.Writer) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: %s completion bash|zsh|fish", filepath.Base(os.Args[0]))
+	}
+
+	data := buildCompletionData(flag.CommandLine, cachedCompletionProjects(configDir))
+	switch args[0] {
+	case "bash":
+		writeBashCompletion(w, data)
+	case "zsh":
+		writeZshCompletion(w, data)
+	case "fish":
+		writeFishCompletion(w, data)
+	default:
+		return fmt.Errorf("unsupported shell %q (allowed: bash|zsh|fish)", args[0])
+	}
+	return nil
+}
+
+func completionWords(values []string) string {
+	return strings.Join(values, " ")
+}
+
+func writeBashCompletion(w io.Writer, data completionData) {
+	var flagNames, commandNames []string
+	for _, f := range data.Flags {
+		flagNames = append(flagNames, "--"+f.Name)
+	}
+	for _, command := range data.Commands {
+		commandNames = append(commandNames, command.Name)
+	}
+
+	fmt.Fprintln(w, "# bash completion for git-feed. Load with: source <(git-feed completion bash)")
+	fmt.Fprintln(w, "_git_feed() {")
+	fmt.Fprintln(w, `    local cur="${COMP_WORDS[COMP_CWORD]}" prev="${COMP_WORDS[COMP_CWORD-1]}" prefix="" command="" word`)
+	fmt.Fprintln(w, `    for word in "${COMP_WORDS[@]:1:COMP_CWORD-1}"; do`)
+	fmt.Fprintf(w, "        case \"$word\" in %s) command=\"$word\" ;; esac\n", strings.Join(commandNames, "|"))
+	fmt.Fprintln(w, "    done")
+	fmt.Fprintln(w, `    if [[ "$cur" == *,* ]]; then prefix="${cur%,*},"; cur="${cur##*,}"; fi`)
+	fmt.Fprintln(w, `    case "$command:$prev" in`)
+	for _, command := range data.Commands {
+		for _, name := range command.Flags {
+			if values := command.Values[name]; len(values) > 0 {
+				fmt.Fprintf(w, "        %s:--%s) COMPREPLY=( $(compgen -W %q -- \"$cur\") ); return ;;\n", command.Name, name, completionWords(values))
+			}
+		}
+	}
+	for _, f := range data.Flags {
+		if f.IsBool {
+			continue
+		}
+		if len(f.Values) == 0 {
+			fmt.Fprintf(w, "        :--%s) return ;;\n", f.Name)
+			continue
+		}
+		prefix := ""
+		if f.List {
+			prefix = ` -P "$prefix"`
+		}
+		fmt.Fprintf(w, "        :--%s) compopt -o nospace 2>/dev/null; COMPREPLY=( $(compgen%s -W %q -- \"$cur\") ); return ;;\n", f.Name, prefix, completionWords(f.Values))
+	}
+	fmt.Fprintln(w, "    esac")
+	fmt.Fprintln(w, `    case "$command" in`)
+	for _, command := range data.Commands {
+		var words []string
+		for _, name := range command.Flags {
+			words = append(words, "--"+name)
+		}
+		words = append(words, command.Args...)
+		fmt.Fprintf(w, "        %s) COMPREPLY=( $(compgen -W %q -- \"$cur\") ); return ;;\n", command.Name, completionWords(words))
+	}
+	fmt.Fprintln(w, "    esac")
+	fmt.Fprintf(w, "    if [[ \"$cur\" == -* ]]; then COMPREPLY=( $(compgen -W %q -- \"$cur\") ); return; fi\n", completionWords(flagNames))
+	fmt.Fprintf(w, "    COMPREPLY=( $(compgen -W %q -- \"$cur\") )\n", completionWords(commandNames))
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w, "complete -F _git_feed git-feed")
+}
+
+var zshDescriptionEscaper = strings.NewReplacer(`'`, `'\''`, "[", `\[`, "]", `\]`, ":", `\:`)
+
+func writeZshCompletion(w io.Writer, data completionData) {
+	fmt.Fprintln(w, "#compdef git-feed")
+	fmt.Fprintln(w, "# zsh completion for git-feed. Save as _git-feed in a directory on $fpath.")
+	fmt.Fprintln(w, "_git_feed() {")
+	fmt.Fprintln(w, "  local curcontext=\"$curcontext\" state line")
+	fmt.Fprintln(w, "  _arguments -C \\")
+	for _, f := range data.Flags {
+		spec := fmt.Sprintf("--%s[%s]", f.Name, zshDescriptionEscaper.Replace(f.Usage))
+		if !f.IsBool {
+			action := " "
+			switch {
+			case len(f.Values) > 0 && f.List:
+				action = "_sequence compadd - " + completionWords(f.Values)
+			case len(f.Values) > 0:
+				action = "(" + completionWords(f.Values) + ")"
+			}
+			spec += ":" + f.Name + ":" + action
+		}
+		fmt.Fprintf(w, "    '%s' \\\n", spec)
+	}
+	fmt.Fprintln(w, "    '1:command:->command' \\")
+	fmt.Fprintln(w, "    '*::arg:->args'")
+	fmt.Fprintln(w, "  case $state in")
+	fmt.Fprintln(w, "    command)")
+	fmt.Fprintln(w, "      local -a commands")
+	fmt.Fprintln(w, "      commands=(")
+	for _, command := range data.Commands {
+		fmt.Fprintf(w, "        '%s:%s'\n", command.Name, zshDescriptionEscaper.Replace(command.Usage))
+	}
+	fmt.Fprintln(w, "      )")
+	fmt.Fprintln(w, "      _describe command commands ;;")
+	fmt.Fprintln(w, "    args)")
+	fmt.Fprintln(w, "      case $line[1] in")
+	for _, command := range data.Commands {
+		var specs []string
+		for _, name := range command.Flags {
+			spec := "'--" + name
+			if values := command.Values[name]; len(values) > 0 {
+				spec += ":" + name + ":(" + completionWords(values) + ")"
+			}
+			specs = append(specs, spec+"'")
+		}
+		if len(command.Args) > 0 {
+			specs = append(specs, "'1:"+command.Name+":("+completionWords(command.Args)+")'")
+		}
+		fmt.Fprintf(w, "        %s) _arguments %s ;;\n", command.Name, strings.Join(specs, " "))
+	}
+	fmt.Fprintln(w, "      esac ;;")
+	fmt.Fprintln(w, "  esac")
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w, `_git_feed "$@"`)
+}
+
+func fishQuote(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
+func writeFishCompletion(w io.Writer, data completionData) {
+	var commandNames []string
+	for _, command := range data.Commands {
+		commandNames = append(commandNames, command.Name)
+	}
+	commands := completionWords(commandNames)
+
+	fmt.Fprintln(w, "# fish completion for git-feed. Save as ~/.config/fish/completions/git-feed.fish")
+	fmt.Fprintln(w, "complete -c git-feed -f")
+	for _, f := range data.Flags {
+		line := fmt.Sprintf("complete -c git-feed -n 'not __fish_seen_subcommand_from %s' -l %s -d %s", commands, f.Name, fishQuote(f.Usage))
+		if !f.IsBool {
+			line += " -x"
+			if len(f.Values) > 0 {
+				line += " -a " + fishQuote(completionWords(f.Values))
+			}
+		}
+		fmt.Fprintln(w, line)
+	}
+	for _, command := range data.Commands {
+		fmt.Fprintf(w, "complete -c git-feed -n 'not __fish_seen_subcommand_from %s' -a %s -d %s\n", commands, command.Name, fishQuote(command.Usage))
+		for _, name := range command.Flags {
+			line := fmt.Sprintf("complete -c git-feed -n '__fish_seen_subcommand_from %s' -l %s", command.Name, name)
+			if values := command.Values[name]; len(values) > 0 {
+				line += " -x -a " + fishQuote(completionWords(values))
+			}
+			fmt.Fprintln(w, line)
+		}
+		if len(command.Args) > 0 {
+			fmt.Fprintf(w, "complete -c git-feed -n '__fish_seen_subcommand_from %s' -a %s\n", command.Name, fishQuote(completionWords(command.Args)))
+		}
+	}
+}
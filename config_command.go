@@ -0,0 +1,424 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// runConfigCommand implements `config get|set|unset|list` on config.yaml.
+// Keys are dotted paths through the file, e.g. gitlab.allowed_repos,
+// sla.review-requested or repos.team/service.exclude; a key of the platform's
+// section may drop the prefix (allowed_repos for gitlab.allowed_repos with
+// --platform gitlab). With --profile the key is looked up under profiles.NAME.
+// Tokens are masked unless --show-secrets is given.
+func runConfigCommand(args []string, path, profile, platform string, w io.Writer) error {
+	usage := fmt.Errorf("usage: %s config get KEY [--show-secrets] | set KEY VALUE | unset KEY | list", filepath.Base(os.Args[0]))
+	showSecrets := false
+	for i, arg := range args {
+		if arg == "--show-secrets" {
+			showSecrets = true
+			args = append(args[:i:i], args[i+1:]...)
+			break
+		}
+	}
+	if len(args) == 0 {
+		return usage
+	}
+
+	doc, err := readConfigDocument(path)
+	if err != nil {
+		return err
+	}
+
+	prefix := ""
+	if profile != "" {
+		prefix = "profiles." + profile + "."
+	}
+	resolveKey := func(key string) ([]string, reflect.Type, error) {
+		segments, leaf, err := resolveConfigKey(prefix + key)
+		if err != nil && !strings.Contains(key, ".") {
+			if segments, leaf, shortErr := resolveConfigKey(prefix + platform + "." + key); shortErr == nil {
+				return segments, leaf, nil
+			}
+		}
+		return segments, leaf, err
+	}
+
+	switch args[0] {
+	case "list":
+		if len(args) != 1 {
+			return usage
+		}
+		for _, entry := range flattenConfigNode(doc.Content[0], "") {
+			fmt.Fprintf(w, "%s = %s\n", entry.key, entry.display(showSecrets))
+		}
+		return nil
+	case "get":
+		if len(args) != 2 {
+			return usage
+		}
+		segments, _, err := resolveKey(args[1])
+		if err != nil {
+			return err
+		}
+		node := lookupConfigNode(doc.Content[0], segments)
+		if node == nil {
+			return fmt.Errorf("%s is not set in %s", args[1], path)
+		}
+		if node.Kind != yaml.MappingNode {
+			entry := configEntry{key: strings.Join(segments, "."), value: configNodeValue(node, showSecrets)}
+			fmt.Fprintln(w, entry.display(showSecrets))
+			return nil
+		}
+		fmt.Fprintln(w, configNodeValue(node, showSecrets))
+		return nil
+	case "set":
+		if len(args) != 3 {
+			return usage
+		}
+		segments, leaf, err := resolveKey(args[1])
+		if err != nil {
+			return err
+		}
+		value, err := configValueNode(leaf, args[2])
+		if err != nil {
+			return fmt.Errorf("%s: %w", args[1], err)
+		}
+		setConfigNode(doc.Content[0], segments, value)
+	case "unset":
+		if len(args) != 2 {
+			return usage
+		}
+		segments, _, err := resolveKey(args[1])
+		if err != nil {
+			return err
+		}
+		if !unsetConfigNode(doc.Content[0], segments) {
+			return fmt.Errorf("%s is not set in %s", args[1], path)
+		}
+	default:
+		return usage
+	}
+
+	return writeConfigDocument(path, doc)
+}
+
+func readConfigDocument(path string) (*yaml.Node, error) {
+	doc := &yaml.Node{Kind: yaml.DocumentNode}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		if err := yaml.Unmarshal(data, doc); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if len(doc.Content) == 0 {
+		doc.Kind = yaml.DocumentNode
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s: top level must be a mapping", path)
+	}
+	return doc, nil
+}
+
+// writeConfigDocument validates the edited document the same way the program
+// reads it, so a bad value is rejected instead of breaking the next run. The
+// file is replaced through a temporary file, so an interrupted write never
+// leaves a truncated config.yaml, and it always ends up with mode 0600 since it
+// may hold a token.
+func writeConfigDocument(path string, doc *yaml.Node) error {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+
+	var cfg configFile
+	decoder := yaml.NewDecoder(bytes.NewReader(buf.Bytes()))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	if err := validateConfigFile(cfg); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
+
+func validateConfigFile(cfg configFile) error {
+	if _, err := parseSLATargets(joinSLASettings(cfg.SLA)); err != nil {
+		return fmt.Errorf("sla: %w", err)
+	}
+	if _, err := parseRepoOptions(cfg.Repos); err != nil {
+		return err
+	}
+	if _, err := parseColorTheme(cfg.Colors.colorEntries()); err != nil {
+		return err
+	}
+	if name := strings.TrimSpace(cfg.Timezone); name != "" && !strings.EqualFold(name, "local") {
+		if _, err := time.LoadLocation(name); err != nil {
+			return fmt.Errorf("timezone: %w", err)
+		}
+	}
+	switch backend := strings.ToLower(strings.TrimSpace(cfg.Cache.Backend)); backend {
+	case "", cacheBackendBolt, cacheBackendSQLite:
+	default:
+		return fmt.Errorf("cache.backend: invalid backend %q (allowed: bolt, sqlite)", backend)
+	}
+	if retention := strings.ToLower(strings.TrimSpace(cfg.Cache.Retention)); retention != "" && retention != "off" && retention != "0" {
+		if _, err := parseTimeRange(retention); err != nil {
+			return fmt.Errorf("cache.retention: %w", err)
+		}
+	}
+	for _, raw := range []string{cfg.GitLab.Host, cfg.GitLab.BaseURL} {
+		if strings.TrimSpace(raw) == "" {
+			continue
+		}
+		if _, err := normalizeGitLabBaseURL(raw); err != nil {
+			return fmt.Errorf("gitlab: %w", err)
+		}
+	}
+	for _, repos := range [][]string{cfg.GitLab.AllowedRepos, cfg.GitLab.ExcludedRepos, cfg.GitHub.AllowedRepos, cfg.GitHub.ExcludedRepos} {
+		for _, repo := range repos {
+			if !strings.Contains(strings.Trim(strings.TrimSpace(repo), "/"), "/") {
+				return fmt.Errorf("invalid repository %q (expected owner/repo or group[/subgroup]/repo)", repo)
+			}
+		}
+	}
+	for name, profile := range cfg.Profiles {
+		if len(profile.Profiles) > 0 {
+			return fmt.Errorf("profiles.%s: profiles cannot be nested", name)
+		}
+		if err := validateConfigFile(profile); err != nil {
+			return fmt.Errorf("profiles.%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// resolveConfigKey splits a dotted key along the configFile schema. Map keys
+// may contain dots themselves (repos.group/my.repo.exclude), so each map key
+// takes as many segments as needed for the rest to match the value type.
+func resolveConfigKey(key string) ([]string, reflect.Type, error) {
+	segments := strings.Split(strings.TrimSpace(key), ".")
+	path, leaf, ok := walkConfigSchema(reflect.TypeOf(configFile{}), segments)
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown config key %q", key)
+	}
+	return path, leaf, nil
+}
+
+func walkConfigSchema(t reflect.Type, segments []string) ([]string, reflect.Type, bool) {
+	switch t.Kind() {
+	case reflect.Struct:
+		if len(segments) == 0 {
+			return nil, nil, false
+		}
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).Tag.Get("yaml") != segments[0] {
+				continue
+			}
+			rest, leaf, ok := walkConfigSchema(t.Field(i).Type, segments[1:])
+			if !ok {
+				return nil, nil, false
+			}
+			return append([]string{segments[0]}, rest...), leaf, true
+		}
+		return nil, nil, false
+	case reflect.Map:
+		for n := 1; n <= len(segments); n++ {
+			name := strings.Join(segments[:n], ".")
+			if name == "" {
+				continue
+			}
+			rest, leaf, ok := walkConfigSchema(t.Elem(), segments[n:])
+			if ok {
+				return append([]string{name}, rest...), leaf, true
+			}
+		}
+		return nil, nil, false
+	default:
+		if len(segments) != 0 {
+			return nil, nil, false
+		}
+		return nil, t, true
+	}
+}
+
+func configValueNode(leaf reflect.Type, raw string) (*yaml.Node, error) {
+	raw = strings.TrimSpace(raw)
+	switch leaf.Kind() {
+	case reflect.Bool:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("expected true or false, got %q", raw)
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(value)}, nil
+	case reflect.Slice:
+		seq := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle}
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				seq.Content = append(seq.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: item})
+			}
+		}
+		return seq, nil
+	default:
+		if raw == "" {
+			return nil, fmt.Errorf("value is empty (use config unset to remove it)")
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: raw}, nil
+	}
+}
+
+func mappingValue(mapping *yaml.Node, key string) (int, *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i, mapping.Content[i+1]
+		}
+	}
+	return -1, nil
+}
+
+func lookupConfigNode(node *yaml.Node, segments []string) *yaml.Node {
+	for _, segment := range segments {
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		if _, node = mappingValue(node, segment); node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+func setConfigNode(node *yaml.Node, segments []string, value *yaml.Node) {
+	for i, segment := range segments {
+		_, child := mappingValue(node, segment)
+		if i == len(segments)-1 {
+			if child != nil {
+				value.HeadComment, value.LineComment = child.HeadComment, child.LineComment
+				*child = *value
+				return
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: segment}, value)
+			return
+		}
+		if child == nil || child.Kind != yaml.MappingNode {
+			mapping := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			if child != nil {
+				*child = *mapping
+			} else {
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: segment}, mapping)
+				child = mapping
+			}
+		}
+		node = child
+	}
+}
+
+// unsetConfigNode removes the key and any mappings it leaves empty.
+func unsetConfigNode(node *yaml.Node, segments []string) bool {
+	if node.Kind != yaml.MappingNode || len(segments) == 0 {
+		return false
+	}
+	index, child := mappingValue(node, segments[0])
+	if child == nil {
+		return false
+	}
+	if len(segments) > 1 {
+		if !unsetConfigNode(child, segments[1:]) {
+			return false
+		}
+		if child.Kind != yaml.MappingNode || len(child.Content) > 0 {
+			return true
+		}
+	}
+	node.Content = append(node.Content[:index], node.Content[index+2:]...)
+	return true
+}
+
+func configNodeValue(node *yaml.Node, showSecrets bool) string {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		values := make([]string, 0, len(node.Content))
+		for _, item := range node.Content {
+			values = append(values, item.Value)
+		}
+		return strings.Join(values, ",")
+	case yaml.MappingNode:
+		var lines []string
+		for _, entry := range flattenConfigNode(node, "") {
+			lines = append(lines, entry.key+" = "+entry.display(showSecrets))
+		}
+		return strings.Join(lines, "\n")
+	default:
+		return node.Value
+	}
+}
+
+type configEntry struct {
+	key   string
+	value string
+}
+
+// display masks tokens so `config list` and `config get` output can be pasted
+// into bug reports.
+func (e configEntry) display(showSecrets bool) string {
+	if !showSecrets && (e.key == "token" || strings.HasSuffix(e.key, ".token")) {
+		return "********"
+	}
+	return e.value
+}
+
+func flattenConfigNode(node *yaml.Node, prefix string) []configEntry {
+	if node.Kind != yaml.MappingNode {
+		return []configEntry{{key: prefix, value: configNodeValue(node, true)}}
+	}
+
+	var entries []configEntry
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i].Value
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		entries = append(entries, flattenConfigNode(node.Content[i+1], key)...)
+	}
+	return entries
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configFile is the structured alternative to the .env file, read from
+// config.yaml in the config directory. Flat settings are exported as the
+// environment variables the rest of the code already reads; nested ones
+// (colors and per-repo options) are applied directly.
+type configFile struct {
+	GitLab      platformSettings        `yaml:"gitlab"`
+	GitHub      platformSettings        `yaml:"github"`
+	SLA         map[string]string       `yaml:"sla"`
+	Timezone    string                  `yaml:"timezone"`
+	UserAliases []string                `yaml:"user_aliases"`
+	NoColor     bool                    `yaml:"no_color"`
+	Colors      colorSettings           `yaml:"colors"`
+	Repos       map[string]repoSettings `yaml:"repos"`
+	Cache       cacheSettings           `yaml:"cache"`
+	Profiles    map[string]configFile   `yaml:"profiles"`
+}
+
+type platformSettings struct {
+	Token         string   `yaml:"token"`
+	TokenCommand  string   `yaml:"token_command"`
+	Username      string   `yaml:"username"`
+	Host          string   `yaml:"host"`
+	BaseURL       string   `yaml:"base_url"`
+	AllowedRepos  []string `yaml:"allowed_repos"`
+	ExcludedRepos []string `yaml:"excluded_repos"`
+	UserAliases   []string `yaml:"user_aliases"`
+}
+
+type cacheSettings struct {
+	Backend   string `yaml:"backend"`
+	Retention string `yaml:"retention"`
+	Encrypt   bool   `yaml:"encrypt"`
+}
+
+type colorSettings struct {
+	Labels map[string]string `yaml:"labels"`
+	States map[string]string `yaml:"states"`
+	Users  []string          `yaml:"users"`
+}
+
+type repoSettings struct {
+	Exclude bool              `yaml:"exclude"`
+	SLA     map[string]string `yaml:"sla"`
+	Time    string            `yaml:"time"`
+}
+
+// repoOptions is the parsed form of a repos: entry, keyed by lowercased path
+// in config.repoOptions.
+type repoOptions struct {
+	exclude    bool
+	slaTargets map[string]time.Duration
+	// timeRange replaces --time for the project when non-zero.
+	timeRange time.Duration
+}
+
+// loadConfigFile reads config.yaml. A missing file is not an error; unknown
+// keys are, so typos don't silently fall back to defaults.
+func loadConfigFile(path string) (configFile, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return configFile{}, nil
+	}
+	if err != nil {
+		return configFile{}, err
+	}
+
+	var cfg configFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return configFile{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// withProfile overlays the named profile on the top-level settings. Maps are
+// merged key by key; any other non-empty profile value replaces the top-level one.
+func (c configFile) withProfile(profile string) (configFile, bool) {
+	overlay, ok := c.Profiles[profile]
+	if profile == "" || !ok {
+		return c, false
+	}
+
+	merged := c
+	merged.Profiles = nil
+	merged.GitLab = c.GitLab.withOverlay(overlay.GitLab)
+	merged.GitHub = c.GitHub.withOverlay(overlay.GitHub)
+	merged.SLA = mergeStringMaps(c.SLA, overlay.SLA)
+	if overlay.Timezone != "" {
+		merged.Timezone = overlay.Timezone
+	}
+	if len(overlay.UserAliases) > 0 {
+		merged.UserAliases = overlay.UserAliases
+	}
+	merged.NoColor = c.NoColor || overlay.NoColor
+	merged.Colors.Labels = mergeStringMaps(c.Colors.Labels, overlay.Colors.Labels)
+	merged.Colors.States = mergeStringMaps(c.Colors.States, overlay.Colors.States)
+	if len(overlay.Colors.Users) > 0 {
+		merged.Colors.Users = overlay.Colors.Users
+	}
+	if overlay.Cache.Backend != "" {
+		merged.Cache.Backend = overlay.Cache.Backend
+	}
+	if overlay.Cache.Retention != "" {
+		merged.Cache.Retention = overlay.Cache.Retention
+	}
+	merged.Cache.Encrypt = c.Cache.Encrypt || overlay.Cache.Encrypt
+	merged.Repos = make(map[string]repoSettings, len(c.Repos)+len(overlay.Repos))
+	for path, settings := range c.Repos {
+		merged.Repos[path] = settings
+	}
+	for path, settings := range overlay.Repos {
+		merged.Repos[path] = settings
+	}
+	return merged, true
+}
+
+func (s platformSettings) withOverlay(overlay platformSettings) platformSettings {
+	if overlay.Token != "" {
+		s.Token = overlay.Token
+	}
+	if overlay.TokenCommand != "" {
+		s.TokenCommand = overlay.TokenCommand
+	}
+	if overlay.Username != "" {
+		s.Username = overlay.Username
+	}
+	if overlay.Host != "" {
+		s.Host = overlay.Host
+	}
+	if overlay.BaseURL != "" {
+		s.BaseURL = overlay.BaseURL
+	}
+	if len(overlay.AllowedRepos) > 0 {
+		s.AllowedRepos = overlay.AllowedRepos
+	}
+	if len(overlay.ExcludedRepos) > 0 {
+		s.ExcludedRepos = overlay.ExcludedRepos
+	}
+	if len(overlay.UserAliases) > 0 {
+		s.UserAliases = overlay.UserAliases
+	}
+	return s
+}
+
+func mergeStringMaps(base, overlay map[string]string) map[string]string {
+	if len(overlay) == 0 {
+		return base
+	}
+	merged := make(map[string]string, len(base)+len(overlay))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range overlay {
+		merged[key] = value
+	}
+	return merged
+}
+
+// envVars maps the flat settings onto the environment variables read by the
+// rest of the program.
+func (c configFile) envVars() map[string]string {
+	vars := make(map[string]string)
+	set := func(key, value string) {
+		if value = strings.TrimSpace(value); value != "" {
+			vars[key] = value
+		}
+	}
+
+	set("GITLAB_TOKEN", c.GitLab.Token)
+	set("GITLAB_TOKEN_COMMAND", c.GitLab.TokenCommand)
+	set("GITLAB_USERNAME", c.GitLab.Username)
+	set("GITLAB_HOST", c.GitLab.Host)
+	set("GITLAB_BASE_URL", c.GitLab.BaseURL)
+	set("GITLAB_ALLOWED_REPOS", strings.Join(c.GitLab.AllowedRepos, ","))
+	set("GITLAB_EXCLUDED_REPOS", strings.Join(c.GitLab.ExcludedRepos, ","))
+	set("GITLAB_USER_ALIASES", strings.Join(c.GitLab.UserAliases, ","))
+	set("GITHUB_TOKEN", c.GitHub.Token)
+	set("GITHUB_TOKEN_COMMAND", c.GitHub.TokenCommand)
+	set("GITHUB_USERNAME", c.GitHub.Username)
+	set("GITHUB_ALLOWED_REPOS", strings.Join(c.GitHub.AllowedRepos, ","))
+	set("GITHUB_EXCLUDED_REPOS", strings.Join(c.GitHub.ExcludedRepos, ","))
+	set("GITHUB_USER_ALIASES", strings.Join(c.GitHub.UserAliases, ","))
+	set("USER_ALIASES", strings.Join(c.UserAliases, ","))
+	set("SLA_TARGETS", joinSLASettings(c.SLA))
+	set("TZ", c.Timezone)
+	set("CACHE_BACKEND", c.Cache.Backend)
+	set("CACHE_RETENTION", c.Cache.Retention)
+	if c.NoColor {
+		vars["NO_COLOR"] = "1"
+	}
+	if c.Cache.Encrypt {
+		vars["CACHE_ENCRYPTION"] = "on"
+	}
+	return vars
+}
+
+func joinSLASettings(targets map[string]string) string {
+	entries := make([]string, 0, len(targets))
+	for label, duration := range targets {
+		entries = append(entries, label+"="+duration)
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, ",")
+}
+
+// applyConfigFileEnv exports the config file settings without overriding
+// variables that are already set, exactly like loadEnvFile.
+func applyConfigFileEnv(cfg configFile) {
+	for key, value := range cfg.envVars() {
+		if _, exists := os.LookupEnv(key); exists {
+			continue
+		}
+		os.Setenv(key, value)
+	}
+}
+
+// colorEntries converts the colors: block into the key/value form of the .env
+// [colors] section so both go through parseColorTheme.
+func (c colorSettings) colorEntries() map[string]string {
+	entries := make(map[string]string)
+	for label, spec := range c.Labels {
+		entries["label."+label] = spec
+	}
+	for state, spec := range c.States {
+		entries["state."+state] = spec
+	}
+	if len(c.Users) > 0 {
+		entries["users"] = strings.Join(c.Users, ",")
+	}
+	return entries
+}
+
+func parseRepoOptions(repos map[string]repoSettings) (map[string]repoOptions, error) {
+	if len(repos) == 0 {
+		return nil, nil
+	}
+
+	options := make(map[string]repoOptions, len(repos))
+	for path, settings := range repos {
+		key := strings.ToLower(strings.Trim(strings.TrimSpace(path), "/"))
+		if key == "" {
+			return nil, fmt.Errorf("repos: empty repository path")
+		}
+		targets, err := parseSLATargets(joinSLASettings(settings.SLA))
+		if err != nil {
+			return nil, fmt.Errorf("repos.%s.sla: %w", path, err)
+		}
+		var timeRange time.Duration
+		if value := strings.TrimSpace(settings.Time); value != "" {
+			timeRange, err = parseTimeRange(value)
+			if err != nil {
+				return nil, fmt.Errorf("repos.%s.time: %w", path, err)
+			}
+		}
+		options[key] = repoOptions{exclude: settings.Exclude, slaTargets: targets, timeRange: timeRange}
+	}
+	return options, nil
+}
+
+func repoOptionsFor(projectPath string) (repoOptions, bool) {
+	if len(config.repoOptions) == 0 {
+		return repoOptions{}, false
+	}
+	options, ok := config.repoOptions[strings.ToLower(strings.Trim(strings.TrimSpace(projectPath), "/"))]
+	return options, ok
+}
@@ -2,11 +2,15 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	gitlab "gitlab.com/gitlab-org/api/client-go"
 	bolt "go.etcd.io/bbolt"
 )
 
@@ -17,10 +21,101 @@ var (
 	githubPullRequestsBkt  = []byte("pull_requests")
 	githubIssuesBkt        = []byte("issues")
 	githubCommentsBkt      = []byte("comments")
+	gitlabProjectsBkt      = []byte("gitlab_projects")
+	gitlabApprovalsBkt     = []byte("gitlab_approvals")
+	metaBkt                = []byte("meta")
 )
 
-type Database struct {
-	db *bolt.DB
+// cachedItemBuckets hold the merge requests, pull requests and issues, as
+// opposed to their notes and comments.
+var cachedItemBuckets = [][]byte{gitlabMergeRequestsBkt, gitlabIssuesBkt, githubPullRequestsBkt, githubIssuesBkt}
+
+// Database is the local cache behind --local and offline cross-reference
+// linking. Items are keyed the same way in every implementation (see the
+// build*Key helpers). bbolt is the default; CACHE_BACKEND=sqlite selects
+// sqliteDatabase, whose tables can also be queried with ad-hoc SQL.
+type Database interface {
+	Close() error
+
+	SaveGitLabMergeRequestWithLabel(pathWithNamespace string, mr MergeRequestModel, label string, debugMode bool) error
+	SaveGitLabIssueWithLabel(pathWithNamespace string, issue IssueModel, label string, debugMode bool) error
+	SaveGitLabNote(note GitLabNoteRecord, debugMode bool) error
+	SaveGitHubPullRequestWithLabel(owner, repo string, pr MergeRequestModel, label string, debugMode bool) error
+	SaveGitHubIssueWithLabel(owner, repo string, issue IssueModel, label string, debugMode bool) error
+	SaveGitHubPRReviewComment(comment GitHubPRReviewCommentRecord, debugMode bool) error
+
+	GetAllGitLabMergeRequestsWithLabels(debugMode bool) (map[string]MergeRequestModel, map[string]string, error)
+	GetAllGitLabIssuesWithLabels(debugMode bool) (map[string]IssueModel, map[string]string, error)
+	GetAllGitHubPullRequestsWithLabels(debugMode bool) (map[string]MergeRequestModel, map[string]string, error)
+	GetAllGitHubIssuesWithLabels(debugMode bool) (map[string]IssueModel, map[string]string, error)
+	HasGitLabData() (bool, error)
+	GetGitLabNotes(pathWithNamespace, itemType string, iid int) ([]GitLabNoteRecord, error)
+	GetGitHubPRReviewComments(owner, repo string, prNumber int) ([]GitHubPRReviewCommentRecord, error)
+	CachedProjectPaths() ([]string, error)
+	SaveGitLabProject(pathWithNamespace string, project GitLabProjectRecord) error
+	GetGitLabProject(pathWithNamespace string) (GitLabProjectRecord, bool, error)
+	SaveGitLabApprovalState(pathWithNamespace string, iid int64, approval GitLabApprovalRecord) error
+	GetGitLabApprovalState(pathWithNamespace string, iid int64) (GitLabApprovalRecord, bool, error)
+
+	Prune(cutoff, now time.Time) (pruneStats, error)
+	LastPrune() (time.Time, error)
+	SetLastSync(at time.Time) error
+	LastSync() (time.Time, error)
+	SetLastItems(items []numberedItem) error
+	LastItems() ([]numberedItem, error)
+	SetSyncCheckpoints(checkpoints map[string]syncCheckpoint) error
+	SyncCheckpoints() (map[string]syncCheckpoint, error)
+	SetSyncMetrics(metrics syncMetrics) error
+	SyncMetrics() (syncMetrics, error)
+	Stats() (cacheStats, error)
+}
+
+// boltDatabase stores JSON values, sealed with key when cache encryption is on.
+type boltDatabase struct {
+	db  *bolt.DB
+	key *[32]byte
+	// Set when another run held the cache's lock: db is then a private copy
+	// of the cache at snapshot, and every write is also queued in queue for
+	// the next run that opens the cache itself (see openLockedBoltDatabase).
+	snapshot string
+	queue    *bolt.DB
+	queued   atomic.Bool
+}
+
+// update runs fn in a write transaction, and again on the queue of a locked
+// cache. fn must only write, since the queue starts out empty.
+func (d *boltDatabase) update(fn func(tx *bolt.Tx) error) error {
+	if err := d.db.Update(fn); err != nil {
+		return err
+	}
+	if d.queue == nil {
+		return nil
+	}
+	if err := d.queue.Update(fn); err != nil {
+		return fmt.Errorf("queue write for the locked cache: %w", err)
+	}
+	d.queued.Store(true)
+	return nil
+}
+
+// sharesLockedCache reports whether db is a snapshot of a cache that another
+// run holds open.
+func sharesLockedCache(db Database) bool {
+	d, ok := db.(*boltDatabase)
+	return ok && d.queue != nil
+}
+
+// encode seals a marshaled value when the cache is encrypted.
+func (d *boltDatabase) encode(value []byte) ([]byte, error) {
+	if d.key == nil {
+		return value, nil
+	}
+	return sealCacheValue(d.key, value)
+}
+
+// decode returns the JSON of a stored value.
+func (d *boltDatabase) decode(value []byte) ([]byte, error) {
+	return openCacheValue(d.key, value)
 }
 
 func buildGitLabMergeRequestKey(pathWithNamespace string, iid int) string {
@@ -41,6 +136,14 @@ func buildGitLabNoteKey(pathWithNamespace, itemType string, iid int, noteID int6
 	)
 }
 
+// gitLabNoteItemKey is the key of the merge request or issue a note belongs to.
+func gitLabNoteItemKey(note GitLabNoteRecord) string {
+	if note.ItemType == "issue" {
+		return buildGitLabIssueKey(note.ProjectPath, note.ItemIID)
+	}
+	return buildGitLabMergeRequestKey(note.ProjectPath, note.ItemIID)
+}
+
 func buildGitHubItemKey(owner, repo string, number int) string {
 	return fmt.Sprintf("%s/%s#%d", strings.TrimSpace(owner), strings.TrimSpace(repo), number)
 }
@@ -49,7 +152,7 @@ func buildGitHubPRReviewCommentKey(owner, repo string, prNumber int, commentID i
 	return fmt.Sprintf("%s/%s#%d/pr_review_comment/%d", strings.TrimSpace(owner), strings.TrimSpace(repo), prNumber, commentID)
 }
 
-func (d *Database) save(bucket []byte, key string, data interface{}, debugMode bool, itemType string) error {
+func (d *boltDatabase) save(bucket []byte, key string, data interface{}, debugMode bool, itemType string) error {
 	jsonData, err := json.Marshal(data)
 	if err != nil {
 		if debugMode {
@@ -57,8 +160,12 @@ func (d *Database) save(bucket []byte, key string, data interface{}, debugMode b
 		}
 		return fmt.Errorf("failed to marshal %s: %w", itemType, err)
 	}
+	jsonData, err = d.encode(jsonData)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt %s: %w", itemType, err)
+	}
 
-	err = d.db.Update(func(tx *bolt.Tx) error {
+	err = d.update(func(tx *bolt.Tx) error {
 		b := tx.Bucket(bucket)
 		return b.Put([]byte(key), jsonData)
 	})
@@ -75,8 +182,31 @@ func (d *Database) save(bucket []byte, key string, data interface{}, debugMode b
 	return nil
 }
 
-func OpenDatabase(path string) (*Database, error) {
+// OpenDatabase opens the cache at path: SQLite for .sqlite files, bbolt
+// otherwise. A non-nil key encrypts the bbolt values (CACHE_ENCRYPTION).
+func OpenDatabase(path string, key *[32]byte) (Database, error) {
+	if isSQLiteCachePath(path) {
+		if key != nil {
+			return nil, fmt.Errorf("CACHE_ENCRYPTION is only supported by the bolt cache backend")
+		}
+		db, err := openSQLiteDatabase(path)
+		if err != nil {
+			return nil, err
+		}
+		return db, nil
+	}
+	db, err := openBoltDatabase(path, key)
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+func openBoltDatabase(path string, key *[32]byte) (*boltDatabase, error) {
 	db, err := bolt.Open(path, 0666, &bolt.Options{Timeout: 1 * time.Second})
+	if errors.Is(err, bolt.ErrTimeout) {
+		return openLockedBoltDatabase(path, key)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -86,7 +216,17 @@ func OpenDatabase(path string) (*Database, error) {
 		return nil, fmt.Errorf("failed to set database permissions: %w", err)
 	}
 
-	err = db.Update(func(tx *bolt.Tx) error {
+	if err := prepareBoltBuckets(db, key); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	applyQueuedCacheWrites(db, path, key)
+
+	return &boltDatabase{db: db, key: key}, nil
+}
+
+func prepareBoltBuckets(db *bolt.DB, key *[32]byte) error {
+	return db.Update(func(tx *bolt.Tx) error {
 		buckets := [][]byte{
 			gitlabMergeRequestsBkt,
 			gitlabIssuesBkt,
@@ -94,6 +234,9 @@ func OpenDatabase(path string) (*Database, error) {
 			githubPullRequestsBkt,
 			githubIssuesBkt,
 			githubCommentsBkt,
+			gitlabProjectsBkt,
+			gitlabApprovalsBkt,
+			metaBkt,
 		}
 		for _, bucket := range buckets {
 			_, err := tx.CreateBucketIfNotExists(bucket)
@@ -101,18 +244,171 @@ func OpenDatabase(path string) (*Database, error) {
 				return fmt.Errorf("failed to create bucket %s: %w", string(bucket), err)
 			}
 		}
-		return nil
+		return prepareCacheEncryption(tx, key)
 	})
+}
+
+func (d *boltDatabase) Close() error {
+	err := d.db.Close()
+	if d.snapshot != "" {
+		_ = os.Remove(d.snapshot)
+	}
+	if d.queue != nil {
+		queuePath := d.queue.Path()
+		if closeErr := d.queue.Close(); err == nil {
+			err = closeErr
+		}
+		if !d.queued.Load() {
+			_ = os.Remove(queuePath)
+		}
+	}
+	return err
+}
+
+var (
+	lastSyncKey    = []byte("last_sync")
+	lastItemsKey   = []byte("last_items")
+	syncMetricsKey = []byte("sync_metrics")
+)
+
+// recordLastSync notes a successful online fetch, shown by `db stats`.
+func recordLastSync(db Database, at time.Time) {
+	if db == nil {
+		return
+	}
+	if err := db.SetLastSync(at); err != nil {
+		recordDBWarning("Failed to record sync time: %v", err)
+	}
+}
+
+// SetLastSync records the start of the last successful online fetch.
+func (d *boltDatabase) SetLastSync(at time.Time) error {
+	return d.update(func(tx *bolt.Tx) error {
+		return tx.Bucket(metaBkt).Put(lastSyncKey, []byte(at.UTC().Format(time.RFC3339)))
+	})
+}
+
+// LastSync reports when the last successful online fetch started; zero if never.
+func (d *boltDatabase) LastSync() (time.Time, error) {
+	return d.metaTime(lastSyncKey)
+}
+
+// SetLastItems replaces the numbering of the last displayed feed.
+func (d *boltDatabase) SetLastItems(items []numberedItem) error {
+	data, err := json.Marshal(items)
 	if err != nil {
-		_ = db.Close()
-		return nil, err
+		return err
+	}
+	if data, err = d.encode(data); err != nil {
+		return err
+	}
+	return d.update(func(tx *bolt.Tx) error {
+		return tx.Bucket(metaBkt).Put(lastItemsKey, data)
+	})
+}
+
+// LastItems returns the numbering of the last displayed feed; nil if none.
+func (d *boltDatabase) LastItems() ([]numberedItem, error) {
+	var items []numberedItem
+	err := d.db.View(func(tx *bolt.Tx) error {
+		raw := tx.Bucket(metaBkt).Get(lastItemsKey)
+		if raw == nil {
+			return nil
+		}
+		data, err := d.decode(raw)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(data, &items)
+	})
+	return items, err
+}
+
+// SetSyncCheckpoints replaces the per-project progress of the current fetch.
+func (d *boltDatabase) SetSyncCheckpoints(checkpoints map[string]syncCheckpoint) error {
+	data, err := json.Marshal(checkpoints)
+	if err != nil {
+		return err
+	}
+	if data, err = d.encode(data); err != nil {
+		return err
+	}
+	return d.update(func(tx *bolt.Tx) error {
+		return tx.Bucket(metaBkt).Put(syncCheckpointsKey, data)
+	})
+}
+
+// SyncCheckpoints returns the per-project progress left by the last fetch;
+// empty unless it was interrupted or failed.
+func (d *boltDatabase) SyncCheckpoints() (map[string]syncCheckpoint, error) {
+	var checkpoints map[string]syncCheckpoint
+	err := d.db.View(func(tx *bolt.Tx) error {
+		raw := tx.Bucket(metaBkt).Get(syncCheckpointsKey)
+		if raw == nil {
+			return nil
+		}
+		data, err := d.decode(raw)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(data, &checkpoints)
+	})
+	return checkpoints, err
+}
+
+// SetSyncMetrics replaces the totals of the online fetches (see syncMetrics).
+func (d *boltDatabase) SetSyncMetrics(metrics syncMetrics) error {
+	data, err := json.Marshal(metrics)
+	if err != nil {
+		return err
 	}
+	if data, err = d.encode(data); err != nil {
+		return err
+	}
+	return d.update(func(tx *bolt.Tx) error {
+		return tx.Bucket(metaBkt).Put(syncMetricsKey, data)
+	})
+}
 
-	return &Database{db: db}, nil
+// SyncMetrics returns the totals of the online fetches; zero if none.
+func (d *boltDatabase) SyncMetrics() (syncMetrics, error) {
+	var metrics syncMetrics
+	err := d.db.View(func(tx *bolt.Tx) error {
+		raw := tx.Bucket(metaBkt).Get(syncMetricsKey)
+		if raw == nil {
+			return nil
+		}
+		data, err := d.decode(raw)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(data, &metrics)
+	})
+	return metrics, err
 }
 
-func (d *Database) Close() error {
-	return d.db.Close()
+func (d *boltDatabase) metaTime(key []byte) (time.Time, error) {
+	var value time.Time
+	err := d.db.View(func(tx *bolt.Tx) error {
+		var err error
+		value, err = metaTimeTx(tx, key)
+		return err
+	})
+	return value, err
+}
+
+// metaTimeTx reads a time from the meta bucket; zero if it is not set or the
+// cache has no meta bucket yet.
+func metaTimeTx(tx *bolt.Tx, key []byte) (time.Time, error) {
+	meta := tx.Bucket(metaBkt)
+	if meta == nil {
+		return time.Time{}, nil
+	}
+	raw := meta.Get(key)
+	if raw == nil {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, string(raw))
 }
 
 type GitLabMRWithLabel struct {
@@ -125,6 +421,8 @@ type GitLabIssueWithLabel struct {
 	Label string
 }
 
+// GitLabNoteRecord is a cached note. CreatedAt, UpdatedAt and AuthorName are
+// zero for notes cached before they were recorded.
 type GitLabNoteRecord struct {
 	ProjectPath    string
 	ItemType       string
@@ -132,7 +430,28 @@ type GitLabNoteRecord struct {
 	NoteID         int64
 	Body           string
 	AuthorUsername string
+	AuthorName     string
 	AuthorID       int64
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// GitLabProjectRecord caches what GetProject returned for an allowed repo, keyed
+// by the normalized path it was requested with. PathWithNamespace is the
+// project's current path, which differs from the key after a rename or move.
+type GitLabProjectRecord struct {
+	ID                int64
+	PathWithNamespace string
+	Topics            []string
+	MergeMethod       string
+	ResolvedAt        time.Time
+}
+
+// GitLabApprovalRecord caches an MR's approval state, keyed like the MR. It is
+// only reused while the MR's UpdatedAt still matches.
+type GitLabApprovalRecord struct {
+	UpdatedAt time.Time
+	State     *gitlab.MergeRequestApprovalState
 }
 
 type GitHubPRWithLabel struct {
@@ -155,41 +474,41 @@ type GitHubPRReviewCommentRecord struct {
 	AuthorID       int64
 }
 
-func (d *Database) SaveGitLabMergeRequestWithLabel(pathWithNamespace string, mr MergeRequestModel, label string, debugMode bool) error {
+func (d *boltDatabase) SaveGitLabMergeRequestWithLabel(pathWithNamespace string, mr MergeRequestModel, label string, debugMode bool) error {
 	key := buildGitLabMergeRequestKey(pathWithNamespace, mr.Number)
 	item := GitLabMRWithLabel{MR: mr, Label: label}
 	return d.save(gitlabMergeRequestsBkt, key, item, debugMode, fmt.Sprintf("gitlab merge request with label %s", label))
 }
 
-func (d *Database) SaveGitLabIssueWithLabel(pathWithNamespace string, issue IssueModel, label string, debugMode bool) error {
+func (d *boltDatabase) SaveGitLabIssueWithLabel(pathWithNamespace string, issue IssueModel, label string, debugMode bool) error {
 	key := buildGitLabIssueKey(pathWithNamespace, issue.Number)
 	item := GitLabIssueWithLabel{Issue: issue, Label: label}
 	return d.save(gitlabIssuesBkt, key, item, debugMode, fmt.Sprintf("gitlab issue with label %s", label))
 }
 
-func (d *Database) SaveGitLabNote(note GitLabNoteRecord, debugMode bool) error {
+func (d *boltDatabase) SaveGitLabNote(note GitLabNoteRecord, debugMode bool) error {
 	key := buildGitLabNoteKey(note.ProjectPath, note.ItemType, note.ItemIID, note.NoteID)
 	return d.save(gitlabNotesBkt, key, note, debugMode, "gitlab note")
 }
 
-func (d *Database) SaveGitHubPullRequestWithLabel(owner, repo string, pr MergeRequestModel, label string, debugMode bool) error {
+func (d *boltDatabase) SaveGitHubPullRequestWithLabel(owner, repo string, pr MergeRequestModel, label string, debugMode bool) error {
 	key := buildGitHubItemKey(owner, repo, pr.Number)
 	item := GitHubPRWithLabel{PR: pr, Label: label}
 	return d.save(githubPullRequestsBkt, key, item, debugMode, fmt.Sprintf("github pull request with label %s", label))
 }
 
-func (d *Database) SaveGitHubIssueWithLabel(owner, repo string, issue IssueModel, label string, debugMode bool) error {
+func (d *boltDatabase) SaveGitHubIssueWithLabel(owner, repo string, issue IssueModel, label string, debugMode bool) error {
 	key := buildGitHubItemKey(owner, repo, issue.Number)
 	item := GitHubIssueWithLabel{Issue: issue, Label: label}
 	return d.save(githubIssuesBkt, key, item, debugMode, fmt.Sprintf("github issue with label %s", label))
 }
 
-func (d *Database) SaveGitHubPRReviewComment(comment GitHubPRReviewCommentRecord, debugMode bool) error {
+func (d *boltDatabase) SaveGitHubPRReviewComment(comment GitHubPRReviewCommentRecord, debugMode bool) error {
 	key := buildGitHubPRReviewCommentKey(comment.Owner, comment.Repo, comment.PRNumber, comment.CommentID)
 	return d.save(githubCommentsBkt, key, comment, debugMode, "github pr review comment")
 }
 
-func (d *Database) GetAllGitLabMergeRequestsWithLabels(debugMode bool) (map[string]MergeRequestModel, map[string]string, error) {
+func (d *boltDatabase) GetAllGitLabMergeRequestsWithLabels(debugMode bool) (map[string]MergeRequestModel, map[string]string, error) {
 	items := make(map[string]MergeRequestModel)
 	labels := make(map[string]string)
 
@@ -201,6 +520,10 @@ func (d *Database) GetAllGitLabMergeRequestsWithLabels(debugMode bool) (map[stri
 		b := tx.Bucket(gitlabMergeRequestsBkt)
 		return b.ForEach(func(k, v []byte) error {
 			key := string(k)
+			v, err := d.decode(v)
+			if err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
 			var item GitLabMRWithLabel
 			if err := json.Unmarshal(v, &item); err != nil {
 				if debugMode {
@@ -227,7 +550,7 @@ func (d *Database) GetAllGitLabMergeRequestsWithLabels(debugMode bool) (map[stri
 	return items, labels, nil
 }
 
-func (d *Database) GetAllGitLabIssuesWithLabels(debugMode bool) (map[string]IssueModel, map[string]string, error) {
+func (d *boltDatabase) GetAllGitLabIssuesWithLabels(debugMode bool) (map[string]IssueModel, map[string]string, error) {
 	items := make(map[string]IssueModel)
 	labels := make(map[string]string)
 
@@ -239,6 +562,10 @@ func (d *Database) GetAllGitLabIssuesWithLabels(debugMode bool) (map[string]Issu
 		b := tx.Bucket(gitlabIssuesBkt)
 		return b.ForEach(func(k, v []byte) error {
 			key := string(k)
+			v, err := d.decode(v)
+			if err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
 			var item GitLabIssueWithLabel
 			if err := json.Unmarshal(v, &item); err != nil {
 				if debugMode {
@@ -265,7 +592,7 @@ func (d *Database) GetAllGitLabIssuesWithLabels(debugMode bool) (map[string]Issu
 	return items, labels, nil
 }
 
-func (d *Database) GetAllGitHubPullRequestsWithLabels(debugMode bool) (map[string]MergeRequestModel, map[string]string, error) {
+func (d *boltDatabase) GetAllGitHubPullRequestsWithLabels(debugMode bool) (map[string]MergeRequestModel, map[string]string, error) {
 	items := make(map[string]MergeRequestModel)
 	labels := make(map[string]string)
 
@@ -281,6 +608,10 @@ func (d *Database) GetAllGitHubPullRequestsWithLabels(debugMode bool) (map[strin
 
 		return b.ForEach(func(k, v []byte) error {
 			key := string(k)
+			v, err := d.decode(v)
+			if err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
 
 			var item GitHubPRWithLabel
 			if err := json.Unmarshal(v, &item); err == nil {
@@ -318,7 +649,7 @@ func (d *Database) GetAllGitHubPullRequestsWithLabels(debugMode bool) (map[strin
 	return items, labels, nil
 }
 
-func (d *Database) GetAllGitHubIssuesWithLabels(debugMode bool) (map[string]IssueModel, map[string]string, error) {
+func (d *boltDatabase) GetAllGitHubIssuesWithLabels(debugMode bool) (map[string]IssueModel, map[string]string, error) {
 	items := make(map[string]IssueModel)
 	labels := make(map[string]string)
 
@@ -334,6 +665,10 @@ func (d *Database) GetAllGitHubIssuesWithLabels(debugMode bool) (map[string]Issu
 
 		return b.ForEach(func(k, v []byte) error {
 			key := string(k)
+			v, err := d.decode(v)
+			if err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
 
 			var item GitHubIssueWithLabel
 			if err := json.Unmarshal(v, &item); err == nil {
@@ -371,7 +706,7 @@ func (d *Database) GetAllGitHubIssuesWithLabels(debugMode bool) (map[string]Issu
 	return items, labels, nil
 }
 
-func (d *Database) HasGitLabData() (bool, error) {
+func (d *boltDatabase) HasGitLabData() (bool, error) {
 	hasData := false
 	err := d.db.View(func(tx *bolt.Tx) error {
 		b := tx.Bucket(gitlabMergeRequestsBkt)
@@ -392,7 +727,7 @@ func (d *Database) HasGitLabData() (bool, error) {
 	return hasData, nil
 }
 
-func (d *Database) GetGitLabNotes(pathWithNamespace, itemType string, iid int) ([]GitLabNoteRecord, error) {
+func (d *boltDatabase) GetGitLabNotes(pathWithNamespace, itemType string, iid int) ([]GitLabNoteRecord, error) {
 	notes := make([]GitLabNoteRecord, 0)
 	prefix := fmt.Sprintf(
 		"%s|%s|%d|",
@@ -409,6 +744,10 @@ func (d *Database) GetGitLabNotes(pathWithNamespace, itemType string, iid int) (
 
 		c := b.Cursor()
 		for k, v := c.Seek([]byte(prefix)); k != nil && strings.HasPrefix(string(k), prefix); k, v = c.Next() {
+			v, err := d.decode(v)
+			if err != nil {
+				return err
+			}
 			var record GitLabNoteRecord
 			if err := json.Unmarshal(v, &record); err != nil {
 				return err
@@ -423,7 +762,7 @@ func (d *Database) GetGitLabNotes(pathWithNamespace, itemType string, iid int) (
 	return notes, nil
 }
 
-func (d *Database) GetGitHubPRReviewComments(owner, repo string, prNumber int) ([]GitHubPRReviewCommentRecord, error) {
+func (d *boltDatabase) GetGitHubPRReviewComments(owner, repo string, prNumber int) ([]GitHubPRReviewCommentRecord, error) {
 	comments := make([]GitHubPRReviewCommentRecord, 0)
 	prefix := fmt.Sprintf("%s/%s#%d/pr_review_comment/", strings.TrimSpace(owner), strings.TrimSpace(repo), prNumber)
 
@@ -435,6 +774,10 @@ func (d *Database) GetGitHubPRReviewComments(owner, repo string, prNumber int) (
 
 		c := b.Cursor()
 		for k, v := c.Seek([]byte(prefix)); k != nil && strings.HasPrefix(string(k), prefix); k, v = c.Next() {
+			v, err := d.decode(v)
+			if err != nil {
+				return err
+			}
 			var record GitHubPRReviewCommentRecord
 			if err := json.Unmarshal(v, &record); err != nil {
 				return err
@@ -449,3 +792,86 @@ func (d *Database) GetGitHubPRReviewComments(owner, repo string, prNumber int) (
 
 	return comments, nil
 }
+
+// CachedProjectPaths lists the distinct project paths (GitLab group/.../repo or
+// GitHub owner/repo) that have cached merge requests, pull requests or issues.
+func (d *boltDatabase) CachedProjectPaths() ([]string, error) {
+	seen := make(map[string]bool)
+	err := d.db.View(func(tx *bolt.Tx) error {
+		for _, bucket := range cachedItemBuckets {
+			b := tx.Bucket(bucket)
+			if b == nil {
+				continue
+			}
+			if err := b.ForEach(func(k, _ []byte) error {
+				if path, _, ok := strings.Cut(string(k), "#"); ok && path != "" {
+					seen[path] = true
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(seen))
+	for path := range seen {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func (d *boltDatabase) SaveGitLabProject(pathWithNamespace string, project GitLabProjectRecord) error {
+	return d.save(gitlabProjectsBkt, normalizeProjectPathWithNamespace(pathWithNamespace), project, false, "gitlab project")
+}
+
+func (d *boltDatabase) GetGitLabProject(pathWithNamespace string) (GitLabProjectRecord, bool, error) {
+	var project GitLabProjectRecord
+	found := false
+	err := d.db.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket(gitlabProjectsBkt).Get([]byte(normalizeProjectPathWithNamespace(pathWithNamespace)))
+		if value == nil {
+			return nil
+		}
+		value, err := d.decode(value)
+		if err != nil {
+			return err
+		}
+		found = true
+		return json.Unmarshal(value, &project)
+	})
+	if err != nil {
+		return GitLabProjectRecord{}, false, err
+	}
+	return project, found, nil
+}
+
+func (d *boltDatabase) SaveGitLabApprovalState(pathWithNamespace string, iid int64, approval GitLabApprovalRecord) error {
+	return d.save(gitlabApprovalsBkt, buildGitLabMergeRequestKey(pathWithNamespace, int(iid)), approval, false, "gitlab approval state")
+}
+
+func (d *boltDatabase) GetGitLabApprovalState(pathWithNamespace string, iid int64) (GitLabApprovalRecord, bool, error) {
+	var approval GitLabApprovalRecord
+	found := false
+	err := d.db.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket(gitlabApprovalsBkt).Get([]byte(buildGitLabMergeRequestKey(pathWithNamespace, int(iid))))
+		if value == nil {
+			return nil
+		}
+		value, err := d.decode(value)
+		if err != nil {
+			return err
+		}
+		found = true
+		return json.Unmarshal(value, &approval)
+	})
+	if err != nil {
+		return GitLabApprovalRecord{}, false, err
+	}
+	return approval, found, nil
+}
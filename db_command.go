@@ -0,0 +1,287 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// compactTxMaxSize bounds how much bolt.Compact copies per write transaction.
+const compactTxMaxSize = 64 << 20
+
+// runDBCommand handles `db compact`, `db stats` and `db metrics`. It runs
+// before main opens the cache, since bbolt locks the file for the process that
+// has it open. Compaction copies values as they are, so only stats and metrics
+// need the cache key.
+func runDBCommand(dbPath string, key *[32]byte, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: db compact|stats|metrics")
+	}
+
+	switch args[0] {
+	case "compact":
+		if len(args) > 1 {
+			return fmt.Errorf("db compact does not take arguments (got %q)", args[1:])
+		}
+		before, after, err := compactDatabase(dbPath)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Compacted %s: %s -> %s\n", dbPath, formatByteSize(before), formatByteSize(after))
+		return nil
+	case "stats":
+		if len(args) > 1 {
+			return fmt.Errorf("db stats does not take arguments (got %q)", args[1:])
+		}
+		info, err := os.Stat(dbPath)
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("no cache database at %s", dbPath)
+		}
+		if err != nil {
+			return err
+		}
+		db, err := OpenDatabase(dbPath, key)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		stats, err := db.Stats()
+		if err != nil {
+			return fmt.Errorf("failed to read cache stats: %w", err)
+		}
+		writeCacheStats(out, dbPath, info.Size(), stats, time.Now())
+		return nil
+	case "metrics":
+		if len(args) > 1 {
+			return fmt.Errorf("db metrics does not take arguments (got %q)", args[1:])
+		}
+		if _, err := os.Stat(dbPath); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("no cache database at %s", dbPath)
+			}
+			return err
+		}
+		db, err := OpenDatabase(dbPath, key)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		metrics, err := db.SyncMetrics()
+		if err != nil {
+			return fmt.Errorf("failed to read sync metrics: %w", err)
+		}
+		writePrometheusMetrics(out, metrics)
+		return nil
+	default:
+		return fmt.Errorf("unknown db command %q (allowed: compact, stats, metrics)", args[0])
+	}
+}
+
+// cacheStats summarizes a cache file for `db stats`. Counts is keyed by bucket
+// name (the kind column in SQLite); Projects counts merge requests, pull
+// requests and issues per project path. UpdatedAt bounds cover the same items.
+type cacheStats struct {
+	Counts       map[string]int
+	Projects     map[string]int
+	OldestUpdate time.Time
+	NewestUpdate time.Time
+	LastSync     time.Time
+	LastPrune    time.Time
+}
+
+func (s *cacheStats) observeUpdate(updatedAt time.Time) {
+	if updatedAt.IsZero() {
+		return
+	}
+	if s.OldestUpdate.IsZero() || updatedAt.Before(s.OldestUpdate) {
+		s.OldestUpdate = updatedAt
+	}
+	if updatedAt.After(s.NewestUpdate) {
+		s.NewestUpdate = updatedAt
+	}
+}
+
+func (d *boltDatabase) Stats() (cacheStats, error) {
+	var stats cacheStats
+	err := d.db.View(func(tx *bolt.Tx) error {
+		var err error
+		stats, err = d.statsTx(tx)
+		return err
+	})
+	return stats, err
+}
+
+// statsTx reads the stats within tx, which may belong to a read-only open.
+func (d *boltDatabase) statsTx(tx *bolt.Tx) (cacheStats, error) {
+	stats := cacheStats{Counts: make(map[string]int), Projects: make(map[string]int)}
+	for _, bucket := range cachedItemBuckets {
+		b := tx.Bucket(bucket)
+		if b == nil {
+			continue
+		}
+		err := b.ForEach(func(k, v []byte) error {
+			stats.Counts[string(bucket)]++
+			if path, _, ok := strings.Cut(string(k), "#"); ok && path != "" {
+				stats.Projects[path]++
+			}
+			v, err := d.decode(v)
+			if err != nil {
+				return fmt.Errorf("%s: %w", k, err)
+			}
+			updatedAt, err := cachedItemUpdatedAt(v)
+			if err != nil {
+				return fmt.Errorf("%s: %w", k, err)
+			}
+			stats.observeUpdate(updatedAt)
+			return nil
+		})
+		if err != nil {
+			return cacheStats{}, fmt.Errorf("%s: %w", bucket, err)
+		}
+	}
+	for _, bucket := range [][]byte{gitlabNotesBkt, githubCommentsBkt} {
+		if b := tx.Bucket(bucket); b != nil {
+			stats.Counts[string(bucket)] = b.Stats().KeyN
+		}
+	}
+
+	var err error
+	if stats.LastSync, err = metaTimeTx(tx, lastSyncKey); err != nil {
+		return cacheStats{}, err
+	}
+	if stats.LastPrune, err = metaTimeTx(tx, lastPruneKey); err != nil {
+		return cacheStats{}, err
+	}
+	return stats, nil
+}
+
+// writeCacheStats prints the stats with every bucket, empty ones included, and
+// projects from the most to the least cached items.
+func writeCacheStats(out io.Writer, path string, size int64, stats cacheStats, now time.Time) {
+	fmt.Fprintf(out, "Cache:      %s (%s)\n", path, formatByteSize(size))
+	fmt.Fprintf(out, "Last sync:  %s\n", formatCacheTime(stats.LastSync, now))
+	fmt.Fprintf(out, "Last prune: %s\n", formatCacheTime(stats.LastPrune, now))
+	if !stats.OldestUpdate.IsZero() {
+		fmt.Fprintf(out, "Updated:    %s to %s\n", displayTime(stats.OldestUpdate).Format("2006-01-02"), displayTime(stats.NewestUpdate).Format("2006-01-02"))
+	}
+
+	fmt.Fprintln(out, "\nBuckets:")
+	for _, bucket := range [][]byte{gitlabMergeRequestsBkt, gitlabIssuesBkt, gitlabNotesBkt, githubPullRequestsBkt, githubIssuesBkt, githubCommentsBkt} {
+		fmt.Fprintf(out, "  %-22s %6d\n", bucket, stats.Counts[string(bucket)])
+	}
+
+	if len(stats.Projects) == 0 {
+		return
+	}
+	projects := make([]string, 0, len(stats.Projects))
+	for project := range stats.Projects {
+		projects = append(projects, project)
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		if stats.Projects[projects[i]] != stats.Projects[projects[j]] {
+			return stats.Projects[projects[i]] > stats.Projects[projects[j]]
+		}
+		return projects[i] < projects[j]
+	})
+	fmt.Fprintln(out, "\nProjects:")
+	for _, project := range projects {
+		fmt.Fprintf(out, "  %-40s %6d\n", project, stats.Projects[project])
+	}
+}
+
+func formatCacheTime(t, now time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return fmt.Sprintf("%s (%s ago)", displayTime(t).Format("2006-01-02 15:04"), formatSLADuration(now.Sub(t)))
+}
+
+// compactDatabase copies the live data of the cache at path into a fresh
+// file and swaps it in, which gives space freed by deletes (such as pruning)
+// back to the file system. SQLite caches are vacuumed instead. It returns the
+// file size before and after.
+func compactDatabase(path string) (int64, int64, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, 0, fmt.Errorf("no cache database at %s", path)
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	before := info.Size()
+
+	if isSQLiteCachePath(path) {
+		if err := vacuumSQLiteDatabase(path); err != nil {
+			return 0, 0, err
+		}
+		info, err = os.Stat(path)
+		if err != nil {
+			return 0, 0, err
+		}
+		return before, info.Size(), nil
+	}
+
+	src, err := bolt.Open(path, 0666, &bolt.Options{Timeout: 1 * time.Second, ReadOnly: true})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer src.Close()
+
+	tmpPath := path + ".compact"
+	_ = os.Remove(tmpPath)
+	dst, err := bolt.Open(tmpPath, 0666, &bolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to create compacted database: %w", err)
+	}
+
+	if err := bolt.Compact(dst, src, compactTxMaxSize); err != nil {
+		_ = dst.Close()
+		_ = os.Remove(tmpPath)
+		return 0, 0, fmt.Errorf("failed to compact database: %w", err)
+	}
+	if err := dst.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return 0, 0, fmt.Errorf("failed to write compacted database: %w", err)
+	}
+	if err := src.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return 0, 0, err
+	}
+
+	if err := os.Chmod(tmpPath, 0666); err != nil {
+		_ = os.Remove(tmpPath)
+		return 0, 0, fmt.Errorf("failed to set database permissions: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return 0, 0, fmt.Errorf("failed to replace database: %w", err)
+	}
+
+	info, err = os.Stat(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	return before, info.Size(), nil
+}
+
+// formatByteSize renders a file size with a binary unit, e.g. 3.1 MiB.
+func formatByteSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	value := float64(size) / unit
+	for _, suffix := range []string{"KiB", "MiB", "GiB"} {
+		if value < unit || suffix == "GiB" {
+			return fmt.Sprintf("%.1f %s", value, suffix)
+		}
+		value /= unit
+	}
+	return fmt.Sprintf("%d B", size)
+}
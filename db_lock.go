@@ -0,0 +1,270 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// queuedWritesSuffix names the files next to the cache that hold the writes
+// of runs which found the cache locked, e.g. gitlab.db.queued-123456.
+const queuedWritesSuffix = ".queued-"
+
+// openLockedBoltDatabase opens the cache at path while another run (such as
+// the web dashboard or a scheduled sync) holds its lock. Reads come from a
+// private snapshot of the file, so the feed shows what was cached; writes go
+// to the snapshot and to a queue file next to the cache, which the next run
+// that opens the cache itself applies.
+func openLockedBoltDatabase(path string, key *[32]byte) (*boltDatabase, error) {
+	snapshot, err := snapshotBoltFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("database is in use by another run, and copying it failed: %w", err)
+	}
+	db, err := bolt.Open(snapshot, 0600, &bolt.Options{Timeout: 1 * time.Second})
+	if err == nil {
+		if err = prepareBoltBuckets(db, key); err != nil {
+			_ = db.Close()
+		}
+	}
+	if err != nil {
+		_ = os.Remove(snapshot)
+		return nil, fmt.Errorf("database is in use by another run, and its snapshot cannot be read: %w", err)
+	}
+
+	queueFile, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+queuedWritesSuffix+"*")
+	if err == nil {
+		_ = queueFile.Close()
+	}
+	var queue *bolt.DB
+	if err == nil {
+		queue, err = bolt.Open(queueFile.Name(), 0600, &bolt.Options{Timeout: 1 * time.Second})
+		if err == nil {
+			if err = prepareBoltBuckets(queue, key); err != nil {
+				_ = queue.Close()
+			}
+		}
+		if err != nil {
+			_ = os.Remove(queueFile.Name())
+		}
+	}
+	if err != nil {
+		_ = db.Close()
+		_ = os.Remove(snapshot)
+		return nil, fmt.Errorf("database is in use by another run, and queueing writes failed: %w", err)
+	}
+
+	return &boltDatabase{db: db, key: key, snapshot: snapshot, queue: queue}, nil
+}
+
+// snapshotAttempts bounds how often snapshotBoltFile copies a cache that
+// the lock holder keeps committing to.
+const snapshotAttempts = 5
+
+var errSnapshotChanged = errors.New("the cache was written to while it was copied")
+
+// snapshotBoltFile copies the cache to a temporary file. The copy is taken
+// without the lock, so it is only used when the meta pages, which every
+// commit rewrites last, are the same in the copy and in the cache after the
+// copy was made; otherwise the copy is retried.
+func snapshotBoltFile(path string) (string, error) {
+	var err error
+	for attempt := 0; attempt < snapshotAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(attempt) * 50 * time.Millisecond)
+		}
+		var snapshot string
+		if snapshot, err = copyBoltFile(path); err != nil {
+			return "", err
+		}
+		if err = checkBoltSnapshot(path, snapshot); err == nil {
+			return snapshot, nil
+		}
+		_ = os.Remove(snapshot)
+	}
+	return "", err
+}
+
+func copyBoltFile(path string) (string, error) {
+	source, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer source.Close()
+
+	target, err := os.CreateTemp("", filepath.Base(path)+".snapshot-*")
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(target, source); err != nil {
+		_ = target.Close()
+		_ = os.Remove(target.Name())
+		return "", err
+	}
+	if err := target.Close(); err != nil {
+		_ = os.Remove(target.Name())
+		return "", err
+	}
+	return target.Name(), nil
+}
+
+// checkBoltSnapshot reports errSnapshotChanged when the meta pages of the
+// cache at source no longer match those copied to snapshot.
+func checkBoltSnapshot(source, snapshot string) error {
+	db, err := bolt.Open(snapshot, 0600, &bolt.Options{Timeout: 1 * time.Second, ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	size := 2 * db.Info().PageSize
+	_ = db.Close()
+
+	copied, err := readFilePrefix(snapshot, size)
+	if err != nil {
+		return err
+	}
+	current, err := readFilePrefix(source, size)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(copied, current) {
+		return errSnapshotChanged
+	}
+	return nil
+}
+
+func readFilePrefix(path string, size int) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	data := make([]byte, size)
+	if _, err := io.ReadFull(f, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// applyQueuedCacheWrites copies the writes queued next to the cache at path
+// into db, oldest queue first, and removes the applied queue files. A queued
+// item only replaces a cached one with an older UpdatedAt, other records are
+// only added, and the meta bucket (last sync, last feed, checkpoints) belongs
+// to the run that holds the cache. Queues still open in a running process are
+// left for a later run, and a queue written with a different CACHE_ENCRYPTION
+// setting is dropped.
+func applyQueuedCacheWrites(db *bolt.DB, path string, key *[32]byte) {
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		recordDBWarning("Failed to look for queued cache writes: %v", err)
+		return
+	}
+	type queuedFile struct {
+		path    string
+		modTime time.Time
+	}
+	var queues []queuedFile
+	prefix := filepath.Base(path) + queuedWritesSuffix
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), prefix) {
+			continue
+		}
+		info, err := entry.Info()
+		// A queue that is still empty is being created by its run.
+		if err != nil || info.Size() == 0 {
+			continue
+		}
+		queues = append(queues, queuedFile{path: filepath.Join(filepath.Dir(path), entry.Name()), modTime: info.ModTime()})
+	}
+	sort.Slice(queues, func(i, j int) bool { return queues[i].modTime.Before(queues[j].modTime) })
+
+	for _, queued := range queues {
+		queue, err := bolt.Open(queued.path, 0600, &bolt.Options{Timeout: 50 * time.Millisecond, ReadOnly: true})
+		if errors.Is(err, bolt.ErrTimeout) {
+			continue
+		}
+		if err != nil {
+			recordDBWarning("Failed to open queued cache writes %s: %v", queued.path, err)
+			continue
+		}
+		var applied int
+		err = db.Update(func(tx *bolt.Tx) error {
+			return queue.View(func(queueTx *bolt.Tx) error {
+				if !sameCacheEncryption(tx, queueTx) {
+					return errQueuedWritesEncryption
+				}
+				return queueTx.ForEach(func(name []byte, bucket *bolt.Bucket) error {
+					target := tx.Bucket(name)
+					if target == nil || bytes.Equal(name, metaBkt) {
+						return nil
+					}
+					return bucket.ForEach(func(k, v []byte) error {
+						if !queuedWriteIsNewer(key, name, target.Get(k), v) {
+							return nil
+						}
+						applied++
+						return target.Put(k, v)
+					})
+				})
+			})
+		})
+		_ = queue.Close()
+		if err != nil && !errors.Is(err, errQueuedWritesEncryption) {
+			recordDBWarning("Failed to apply queued cache writes %s: %v", queued.path, err)
+			continue
+		}
+		if err != nil {
+			recordDBWarning("Dropped queued cache writes %s: %v", queued.path, err)
+		} else if config.debugMode {
+			fmt.Printf("  [DB] Applied %d cache writes queued by another run\n", applied)
+		}
+		if err := os.Remove(queued.path); err != nil {
+			recordDBWarning("Failed to remove queued cache writes %s: %v", queued.path, err)
+		}
+	}
+}
+
+// queuedWriteIsNewer reports whether the queued value should replace the
+// cached one in bucket: always when nothing is cached, by UpdatedAt for
+// items, and never for other records.
+func queuedWriteIsNewer(key *[32]byte, bucket, cached, queued []byte) bool {
+	if cached == nil {
+		return true
+	}
+	isItem := false
+	for _, itemBucket := range cachedItemBuckets {
+		if bytes.Equal(bucket, itemBucket) {
+			isItem = true
+			break
+		}
+	}
+	if !isItem {
+		return false
+	}
+	cachedAt, err := queuedItemUpdatedAt(key, cached)
+	if err != nil {
+		return true
+	}
+	queuedAt, err := queuedItemUpdatedAt(key, queued)
+	return err == nil && queuedAt.After(cachedAt)
+}
+
+func queuedItemUpdatedAt(key *[32]byte, value []byte) (time.Time, error) {
+	data, err := openCacheValue(key, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return cachedItemUpdatedAt(data)
+}
+
+var errQueuedWritesEncryption = errors.New("written with a different CACHE_ENCRYPTION setting")
+
+func sameCacheEncryption(a, b *bolt.Tx) bool {
+	return (a.Bucket(metaBkt).Get(encryptionMetaKey) != nil) == (b.Bucket(metaBkt).Get(encryptionMetaKey) != nil)
+}
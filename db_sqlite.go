@@ -0,0 +1,659 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+const (
+	cacheBackendBolt   = "bolt"
+	cacheBackendSQLite = "sqlite"
+)
+
+// sqliteTimeLayout is fixed width so that timestamps compare correctly as
+// text, both in Prune and in ad-hoc queries.
+const sqliteTimeLayout = "2006-01-02 15:04:05.000000000"
+
+// The SQLite cache keeps one row per cached item or note, keyed like the bbolt
+// buckets (kind is the bucket name). The model is stored as JSON in data; the
+// other columns duplicate the fields worth filtering on so the file can be
+// queried directly, e.g.
+//
+//	SELECT project, number, title FROM items
+//	WHERE kind = 'gitlab_merge_requests' AND state = 'opened'
+//	ORDER BY updated_at DESC;
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS items (
+	kind       TEXT NOT NULL,
+	key        TEXT NOT NULL,
+	project    TEXT NOT NULL,
+	number     INTEGER NOT NULL,
+	title      TEXT NOT NULL,
+	state      TEXT NOT NULL,
+	author     TEXT NOT NULL,
+	label      TEXT NOT NULL,
+	web_url    TEXT NOT NULL,
+	created_at TEXT,
+	updated_at TEXT,
+	data       TEXT NOT NULL,
+	PRIMARY KEY (kind, key)
+);
+CREATE INDEX IF NOT EXISTS items_updated_at ON items (updated_at);
+CREATE TABLE IF NOT EXISTS notes (
+	kind     TEXT NOT NULL,
+	key      TEXT NOT NULL,
+	item_key TEXT NOT NULL,
+	note_id  INTEGER NOT NULL,
+	author   TEXT NOT NULL,
+	body     TEXT NOT NULL,
+	data     TEXT NOT NULL,
+	PRIMARY KEY (kind, key)
+);
+CREATE INDEX IF NOT EXISTS notes_item_key ON notes (kind, item_key);
+CREATE TABLE IF NOT EXISTS gitlab_projects (
+	path           TEXT PRIMARY KEY,
+	id             INTEGER NOT NULL,
+	current_path   TEXT NOT NULL,
+	data           TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS gitlab_approvals (
+	key        TEXT PRIMARY KEY,
+	updated_at TEXT,
+	data       TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS meta (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+`
+
+type sqliteDatabase struct {
+	db *sql.DB
+}
+
+// resolveCacheBackend reads CACHE_BACKEND (bolt or sqlite, default bolt).
+func resolveCacheBackend() (string, error) {
+	switch backend := strings.ToLower(strings.TrimSpace(os.Getenv("CACHE_BACKEND"))); backend {
+	case "", cacheBackendBolt:
+		return cacheBackendBolt, nil
+	case cacheBackendSQLite:
+		return cacheBackendSQLite, nil
+	default:
+		return "", fmt.Errorf("invalid CACHE_BACKEND %q (allowed: bolt, sqlite)", backend)
+	}
+}
+
+// cacheFileName gives the SQLite cache its own extension, so switching
+// backends never opens a file written by the other one.
+func cacheFileName(dbFileName, backend string) string {
+	if backend == cacheBackendSQLite {
+		return strings.TrimSuffix(dbFileName, ".db") + ".sqlite"
+	}
+	return dbFileName
+}
+
+func isSQLiteCachePath(path string) bool {
+	return filepath.Ext(path) == ".sqlite"
+}
+
+func formatSQLiteTime(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC().Format(sqliteTimeLayout)
+}
+
+func openSQLiteDatabase(path string) (*sqliteDatabase, error) {
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(1000)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	// The auto-prune runs next to the fetch; a single connection serializes
+	// them instead of failing with SQLITE_BUSY.
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to create tables: %w", err)
+	}
+	if err := os.Chmod(path, 0666); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to set database permissions: %w", err)
+	}
+
+	return &sqliteDatabase{db: db}, nil
+}
+
+func (d *sqliteDatabase) Close() error {
+	return d.db.Close()
+}
+
+// sqliteItem is the queryable part of an items row.
+type sqliteItem struct {
+	kind      string
+	key       string
+	number    int
+	title     string
+	state     string
+	author    string
+	webURL    string
+	createdAt time.Time
+	updatedAt time.Time
+}
+
+func mergeRequestSQLiteItem(kind []byte, key string, mr MergeRequestModel) sqliteItem {
+	return sqliteItem{kind: string(kind), key: key, number: mr.Number, title: mr.Title, state: mr.State, author: mr.UserLogin, webURL: mr.WebURL, createdAt: mr.CreatedAt, updatedAt: mr.UpdatedAt}
+}
+
+func issueSQLiteItem(kind []byte, key string, issue IssueModel) sqliteItem {
+	return sqliteItem{kind: string(kind), key: key, number: issue.Number, title: issue.Title, state: issue.State, author: issue.UserLogin, webURL: issue.WebURL, createdAt: issue.CreatedAt, updatedAt: issue.UpdatedAt}
+}
+
+func (d *sqliteDatabase) saveItem(item sqliteItem, model any, label string, debugMode bool, itemType string) error {
+	jsonData, err := json.Marshal(model)
+	if err != nil {
+		if debugMode {
+			fmt.Printf("  [DB] Error marshaling %s %s: %v\n", itemType, item.key, err)
+		}
+		return fmt.Errorf("failed to marshal %s: %w", itemType, err)
+	}
+
+	project, _, _ := strings.Cut(item.key, "#")
+	_, err = d.db.Exec(`INSERT INTO items (kind, key, project, number, title, state, author, label, web_url, created_at, updated_at, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (kind, key) DO UPDATE SET
+			project = excluded.project, number = excluded.number, title = excluded.title,
+			state = excluded.state, author = excluded.author, label = excluded.label,
+			web_url = excluded.web_url, created_at = excluded.created_at,
+			updated_at = excluded.updated_at, data = excluded.data`,
+		item.kind, item.key, project, item.number, item.title, item.state, item.author, label, item.webURL,
+		formatSQLiteTime(item.createdAt), formatSQLiteTime(item.updatedAt), string(jsonData))
+	if err != nil {
+		if debugMode {
+			fmt.Printf("  [DB] Error saving %s %s: %v\n", itemType, item.key, err)
+		}
+		return err
+	}
+
+	if debugMode {
+		fmt.Printf("  [DB] Saved %s %s\n", itemType, item.key)
+	}
+	return nil
+}
+
+func (d *sqliteDatabase) saveNote(kind []byte, key, itemKey string, noteID int64, author, body string, record any, debugMode bool, itemType string) error {
+	jsonData, err := json.Marshal(record)
+	if err != nil {
+		if debugMode {
+			fmt.Printf("  [DB] Error marshaling %s %s: %v\n", itemType, key, err)
+		}
+		return fmt.Errorf("failed to marshal %s: %w", itemType, err)
+	}
+
+	_, err = d.db.Exec(`INSERT INTO notes (kind, key, item_key, note_id, author, body, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (kind, key) DO UPDATE SET
+			item_key = excluded.item_key, note_id = excluded.note_id,
+			author = excluded.author, body = excluded.body, data = excluded.data`,
+		string(kind), key, itemKey, noteID, author, body, string(jsonData))
+	if err != nil {
+		if debugMode {
+			fmt.Printf("  [DB] Error saving %s %s: %v\n", itemType, key, err)
+		}
+		return err
+	}
+
+	if debugMode {
+		fmt.Printf("  [DB] Saved %s %s\n", itemType, key)
+	}
+	return nil
+}
+
+func (d *sqliteDatabase) SaveGitLabMergeRequestWithLabel(pathWithNamespace string, mr MergeRequestModel, label string, debugMode bool) error {
+	key := buildGitLabMergeRequestKey(pathWithNamespace, mr.Number)
+	return d.saveItem(mergeRequestSQLiteItem(gitlabMergeRequestsBkt, key, mr), mr, label, debugMode, fmt.Sprintf("gitlab merge request with label %s", label))
+}
+
+func (d *sqliteDatabase) SaveGitLabIssueWithLabel(pathWithNamespace string, issue IssueModel, label string, debugMode bool) error {
+	key := buildGitLabIssueKey(pathWithNamespace, issue.Number)
+	return d.saveItem(issueSQLiteItem(gitlabIssuesBkt, key, issue), issue, label, debugMode, fmt.Sprintf("gitlab issue with label %s", label))
+}
+
+func (d *sqliteDatabase) SaveGitLabNote(note GitLabNoteRecord, debugMode bool) error {
+	key := buildGitLabNoteKey(note.ProjectPath, note.ItemType, note.ItemIID, note.NoteID)
+	return d.saveNote(gitlabNotesBkt, key, gitLabNoteItemKey(note), note.NoteID, note.AuthorUsername, note.Body, note, debugMode, "gitlab note")
+}
+
+func (d *sqliteDatabase) SaveGitHubPullRequestWithLabel(owner, repo string, pr MergeRequestModel, label string, debugMode bool) error {
+	key := buildGitHubItemKey(owner, repo, pr.Number)
+	return d.saveItem(mergeRequestSQLiteItem(githubPullRequestsBkt, key, pr), pr, label, debugMode, fmt.Sprintf("github pull request with label %s", label))
+}
+
+func (d *sqliteDatabase) SaveGitHubIssueWithLabel(owner, repo string, issue IssueModel, label string, debugMode bool) error {
+	key := buildGitHubItemKey(owner, repo, issue.Number)
+	return d.saveItem(issueSQLiteItem(githubIssuesBkt, key, issue), issue, label, debugMode, fmt.Sprintf("github issue with label %s", label))
+}
+
+func (d *sqliteDatabase) SaveGitHubPRReviewComment(comment GitHubPRReviewCommentRecord, debugMode bool) error {
+	key := buildGitHubPRReviewCommentKey(comment.Owner, comment.Repo, comment.PRNumber, comment.CommentID)
+	itemKey := buildGitHubItemKey(comment.Owner, comment.Repo, comment.PRNumber)
+	return d.saveNote(githubCommentsBkt, key, itemKey, comment.CommentID, comment.AuthorUsername, comment.Body, comment, debugMode, "github pr review comment")
+}
+
+// loadSQLiteItems decodes the data column of every item of one kind into a new T.
+func loadSQLiteItems[T any](d *sqliteDatabase, kind []byte, debugMode bool, itemType string) (map[string]T, map[string]string, error) {
+	items := make(map[string]T)
+	labels := make(map[string]string)
+
+	if debugMode {
+		fmt.Printf("  [DB] Reading all %ss with labels from database...\n", itemType)
+	}
+
+	rows, err := d.db.Query(`SELECT key, label, data FROM items WHERE kind = ?`, string(kind))
+	if err != nil {
+		if debugMode {
+			fmt.Printf("  [DB] Error reading %ss: %v\n", itemType, err)
+		}
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key, label, data string
+		if err := rows.Scan(&key, &label, &data); err != nil {
+			return nil, nil, err
+		}
+		var item T
+		if err := json.Unmarshal([]byte(data), &item); err != nil {
+			if debugMode {
+				fmt.Printf("  [DB] Error unmarshaling %s %s: %v\n", itemType, key, err)
+			}
+			return nil, nil, err
+		}
+		items[key] = item
+		labels[key] = label
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	if debugMode {
+		fmt.Printf("  [DB] Loaded %d %ss from database\n", len(items), itemType)
+	}
+
+	return items, labels, nil
+}
+
+func (d *sqliteDatabase) GetAllGitLabMergeRequestsWithLabels(debugMode bool) (map[string]MergeRequestModel, map[string]string, error) {
+	return loadSQLiteItems[MergeRequestModel](d, gitlabMergeRequestsBkt, debugMode, "GitLab merge request")
+}
+
+func (d *sqliteDatabase) GetAllGitLabIssuesWithLabels(debugMode bool) (map[string]IssueModel, map[string]string, error) {
+	return loadSQLiteItems[IssueModel](d, gitlabIssuesBkt, debugMode, "GitLab issue")
+}
+
+func (d *sqliteDatabase) GetAllGitHubPullRequestsWithLabels(debugMode bool) (map[string]MergeRequestModel, map[string]string, error) {
+	return loadSQLiteItems[MergeRequestModel](d, githubPullRequestsBkt, debugMode, "GitHub pull request")
+}
+
+func (d *sqliteDatabase) GetAllGitHubIssuesWithLabels(debugMode bool) (map[string]IssueModel, map[string]string, error) {
+	return loadSQLiteItems[IssueModel](d, githubIssuesBkt, debugMode, "GitHub issue")
+}
+
+func (d *sqliteDatabase) HasGitLabData() (bool, error) {
+	var hasData bool
+	err := d.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM items WHERE kind IN (?, ?))`,
+		string(gitlabMergeRequestsBkt), string(gitlabIssuesBkt)).Scan(&hasData)
+	if err != nil {
+		return false, err
+	}
+	return hasData, nil
+}
+
+// loadSQLiteNotes decodes the notes of one item in key order, like the bbolt
+// cursor scan.
+func loadSQLiteNotes[T any](d *sqliteDatabase, kind []byte, itemKey string) ([]T, error) {
+	notes := make([]T, 0)
+	rows, err := d.db.Query(`SELECT data FROM notes WHERE kind = ? AND item_key = ? ORDER BY key`, string(kind), itemKey)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var record T
+		if err := json.Unmarshal([]byte(data), &record); err != nil {
+			return nil, err
+		}
+		notes = append(notes, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
+
+func (d *sqliteDatabase) GetGitLabNotes(pathWithNamespace, itemType string, iid int) ([]GitLabNoteRecord, error) {
+	itemKey := gitLabNoteItemKey(GitLabNoteRecord{
+		ProjectPath: pathWithNamespace,
+		ItemType:    strings.ToLower(strings.TrimSpace(itemType)),
+		ItemIID:     iid,
+	})
+	return loadSQLiteNotes[GitLabNoteRecord](d, gitlabNotesBkt, itemKey)
+}
+
+func (d *sqliteDatabase) GetGitHubPRReviewComments(owner, repo string, prNumber int) ([]GitHubPRReviewCommentRecord, error) {
+	return loadSQLiteNotes[GitHubPRReviewCommentRecord](d, githubCommentsBkt, buildGitHubItemKey(owner, repo, prNumber))
+}
+
+func (d *sqliteDatabase) CachedProjectPaths() ([]string, error) {
+	rows, err := d.db.Query(`SELECT DISTINCT project FROM items WHERE project != '' ORDER BY project`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	paths := make([]string, 0)
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, rows.Err()
+}
+
+func (d *sqliteDatabase) SaveGitLabProject(pathWithNamespace string, project GitLabProjectRecord) error {
+	jsonData, err := json.Marshal(project)
+	if err != nil {
+		return fmt.Errorf("failed to marshal gitlab project: %w", err)
+	}
+	_, err = d.db.Exec(`INSERT INTO gitlab_projects (path, id, current_path, data) VALUES (?, ?, ?, ?)
+		ON CONFLICT (path) DO UPDATE SET id = excluded.id, current_path = excluded.current_path, data = excluded.data`,
+		normalizeProjectPathWithNamespace(pathWithNamespace), project.ID, project.PathWithNamespace, string(jsonData))
+	return err
+}
+
+func (d *sqliteDatabase) GetGitLabProject(pathWithNamespace string) (GitLabProjectRecord, bool, error) {
+	var data string
+	err := d.db.QueryRow(`SELECT data FROM gitlab_projects WHERE path = ?`, normalizeProjectPathWithNamespace(pathWithNamespace)).Scan(&data)
+	if err == sql.ErrNoRows {
+		return GitLabProjectRecord{}, false, nil
+	}
+	if err != nil {
+		return GitLabProjectRecord{}, false, err
+	}
+	var project GitLabProjectRecord
+	if err := json.Unmarshal([]byte(data), &project); err != nil {
+		return GitLabProjectRecord{}, false, err
+	}
+	return project, true, nil
+}
+
+func (d *sqliteDatabase) SaveGitLabApprovalState(pathWithNamespace string, iid int64, approval GitLabApprovalRecord) error {
+	jsonData, err := json.Marshal(approval)
+	if err != nil {
+		return fmt.Errorf("failed to marshal gitlab approval state: %w", err)
+	}
+	_, err = d.db.Exec(`INSERT INTO gitlab_approvals (key, updated_at, data) VALUES (?, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET updated_at = excluded.updated_at, data = excluded.data`,
+		buildGitLabMergeRequestKey(pathWithNamespace, int(iid)), formatSQLiteTime(approval.UpdatedAt), string(jsonData))
+	return err
+}
+
+func (d *sqliteDatabase) GetGitLabApprovalState(pathWithNamespace string, iid int64) (GitLabApprovalRecord, bool, error) {
+	var data string
+	err := d.db.QueryRow(`SELECT data FROM gitlab_approvals WHERE key = ?`, buildGitLabMergeRequestKey(pathWithNamespace, int(iid))).Scan(&data)
+	if err == sql.ErrNoRows {
+		return GitLabApprovalRecord{}, false, nil
+	}
+	if err != nil {
+		return GitLabApprovalRecord{}, false, err
+	}
+	var approval GitLabApprovalRecord
+	if err := json.Unmarshal([]byte(data), &approval); err != nil {
+		return GitLabApprovalRecord{}, false, err
+	}
+	return approval, true, nil
+}
+
+// Prune deletes the items last updated before cutoff and the notes and review
+// comments that belong to them, in one transaction. Rows without updated_at
+// compare as NULL and are kept, like zero timestamps in the bbolt cache.
+func (d *sqliteDatabase) Prune(cutoff, now time.Time) (pruneStats, error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return pruneStats{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	before := cutoff.UTC().Format(sqliteTimeLayout)
+	var stats pruneStats
+	for _, query := range []string{
+		`DELETE FROM notes WHERE kind = 'gitlab_notes' AND item_key IN (
+			SELECT key FROM items WHERE kind IN ('gitlab_merge_requests', 'gitlab_issues') AND updated_at < ?)`,
+		`DELETE FROM notes WHERE kind = 'comments' AND item_key IN (
+			SELECT key FROM items WHERE kind = 'pull_requests' AND updated_at < ?)`,
+	} {
+		result, err := tx.Exec(query, before)
+		if err != nil {
+			return pruneStats{}, fmt.Errorf("prune notes: %w", err)
+		}
+		count, err := result.RowsAffected()
+		if err != nil {
+			return pruneStats{}, err
+		}
+		stats.Notes += int(count)
+	}
+
+	_, err = tx.Exec(`DELETE FROM gitlab_approvals WHERE key IN (
+		SELECT key FROM items WHERE kind = 'gitlab_merge_requests' AND updated_at < ?)`, before)
+	if err != nil {
+		return pruneStats{}, fmt.Errorf("prune approval states: %w", err)
+	}
+
+	items, err := tx.Exec(`DELETE FROM items WHERE updated_at < ?`, before)
+	if err != nil {
+		return pruneStats{}, fmt.Errorf("prune items: %w", err)
+	}
+	itemCount, err := items.RowsAffected()
+	if err != nil {
+		return pruneStats{}, err
+	}
+	stats.Items = int(itemCount)
+
+	_, err = tx.Exec(`INSERT INTO meta (key, value) VALUES (?, ?) ON CONFLICT (key) DO UPDATE SET value = excluded.value`,
+		string(lastPruneKey), now.UTC().Format(time.RFC3339))
+	if err != nil {
+		return pruneStats{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return pruneStats{}, err
+	}
+	return stats, nil
+}
+
+func (d *sqliteDatabase) LastPrune() (time.Time, error) {
+	return d.metaTime(lastPruneKey)
+}
+
+func (d *sqliteDatabase) SetLastSync(at time.Time) error {
+	_, err := d.db.Exec(`INSERT INTO meta (key, value) VALUES (?, ?) ON CONFLICT (key) DO UPDATE SET value = excluded.value`,
+		string(lastSyncKey), at.UTC().Format(time.RFC3339))
+	return err
+}
+
+func (d *sqliteDatabase) LastSync() (time.Time, error) {
+	return d.metaTime(lastSyncKey)
+}
+
+func (d *sqliteDatabase) SetLastItems(items []numberedItem) error {
+	data, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	_, err = d.db.Exec(`INSERT INTO meta (key, value) VALUES (?, ?) ON CONFLICT (key) DO UPDATE SET value = excluded.value`,
+		string(lastItemsKey), string(data))
+	return err
+}
+
+func (d *sqliteDatabase) LastItems() ([]numberedItem, error) {
+	var value string
+	err := d.db.QueryRow(`SELECT value FROM meta WHERE key = ?`, string(lastItemsKey)).Scan(&value)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var items []numberedItem
+	if err := json.Unmarshal([]byte(value), &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (d *sqliteDatabase) SetSyncCheckpoints(checkpoints map[string]syncCheckpoint) error {
+	data, err := json.Marshal(checkpoints)
+	if err != nil {
+		return err
+	}
+	_, err = d.db.Exec(`INSERT INTO meta (key, value) VALUES (?, ?) ON CONFLICT (key) DO UPDATE SET value = excluded.value`,
+		string(syncCheckpointsKey), string(data))
+	return err
+}
+
+func (d *sqliteDatabase) SyncCheckpoints() (map[string]syncCheckpoint, error) {
+	var value string
+	err := d.db.QueryRow(`SELECT value FROM meta WHERE key = ?`, string(syncCheckpointsKey)).Scan(&value)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var checkpoints map[string]syncCheckpoint
+	if err := json.Unmarshal([]byte(value), &checkpoints); err != nil {
+		return nil, err
+	}
+	return checkpoints, nil
+}
+
+func (d *sqliteDatabase) SetSyncMetrics(metrics syncMetrics) error {
+	data, err := json.Marshal(metrics)
+	if err != nil {
+		return err
+	}
+	_, err = d.db.Exec(`INSERT INTO meta (key, value) VALUES (?, ?) ON CONFLICT (key) DO UPDATE SET value = excluded.value`,
+		string(syncMetricsKey), string(data))
+	return err
+}
+
+func (d *sqliteDatabase) SyncMetrics() (syncMetrics, error) {
+	var metrics syncMetrics
+	var value string
+	err := d.db.QueryRow(`SELECT value FROM meta WHERE key = ?`, string(syncMetricsKey)).Scan(&value)
+	if err == sql.ErrNoRows {
+		return metrics, nil
+	}
+	if err != nil {
+		return metrics, err
+	}
+	err = json.Unmarshal([]byte(value), &metrics)
+	return metrics, err
+}
+
+func (d *sqliteDatabase) metaTime(key []byte) (time.Time, error) {
+	var value string
+	err := d.db.QueryRow(`SELECT value FROM meta WHERE key = ?`, string(key)).Scan(&value)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func (d *sqliteDatabase) Stats() (cacheStats, error) {
+	stats := cacheStats{Counts: make(map[string]int), Projects: make(map[string]int)}
+	if err := d.scanCounts(`SELECT kind, COUNT(*) FROM items GROUP BY kind`, stats.Counts); err != nil {
+		return cacheStats{}, err
+	}
+	if err := d.scanCounts(`SELECT kind, COUNT(*) FROM notes GROUP BY kind`, stats.Counts); err != nil {
+		return cacheStats{}, err
+	}
+	if err := d.scanCounts(`SELECT project, COUNT(*) FROM items WHERE project != '' GROUP BY project`, stats.Projects); err != nil {
+		return cacheStats{}, err
+	}
+
+	var oldest, newest sql.NullString
+	if err := d.db.QueryRow(`SELECT MIN(updated_at), MAX(updated_at) FROM items`).Scan(&oldest, &newest); err != nil {
+		return cacheStats{}, err
+	}
+	for _, bound := range []struct {
+		raw    sql.NullString
+		target *time.Time
+	}{{oldest, &stats.OldestUpdate}, {newest, &stats.NewestUpdate}} {
+		if !bound.raw.Valid {
+			continue
+		}
+		parsed, err := time.Parse(sqliteTimeLayout, bound.raw.String)
+		if err != nil {
+			return cacheStats{}, err
+		}
+		*bound.target = parsed
+	}
+
+	var err error
+	if stats.LastSync, err = d.LastSync(); err != nil {
+		return cacheStats{}, err
+	}
+	if stats.LastPrune, err = d.LastPrune(); err != nil {
+		return cacheStats{}, err
+	}
+	return stats, nil
+}
+
+func (d *sqliteDatabase) scanCounts(query string, counts map[string]int) error {
+	rows, err := d.db.Query(query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		var count int
+		if err := rows.Scan(&name, &count); err != nil {
+			return err
+		}
+		counts[name] = count
+	}
+	return rows.Err()
+}
+
+// vacuumSQLiteDatabase is `db compact` for the SQLite cache.
+func vacuumSQLiteDatabase(path string) error {
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(1000)")
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`VACUUM`); err != nil {
+		return fmt.Errorf("failed to compact database: %w", err)
+	}
+	return db.Close()
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+const demoBaseURL = "https://gitlab.example.com"
+
+// demoActivities builds a fixed, realistic-looking feed for --demo so every
+// display mode can be explored without a token, network or cache database.
+func demoActivities(now time.Time, cutoff time.Time) ([]PRActivity, []IssueActivity) {
+	mr := func(project string, iid int, label, title, author, state string, merged bool, age time.Duration) PRActivity {
+		owner, repo, _ := splitGitLabPathWithNamespace(project)
+		updatedAt := now.Add(-age)
+		return PRActivity{
+			Label:     label,
+			Owner:     owner,
+			Repo:      repo,
+			UpdatedAt: updatedAt,
+			MR: MergeRequestModel{
+				Number:    iid,
+				Title:     title,
+				State:     state,
+				Merged:    merged,
+				CreatedAt: updatedAt.Add(-demoCreatedBefore(iid)),
+				UpdatedAt: updatedAt,
+				UserLogin: author,
+				WebURL:    fmt.Sprintf("%s/%s/-/merge_requests/%d", demoBaseURL, project, iid),
+			},
+		}
+	}
+	issue := func(project string, iid int, label, title, author, state string, age time.Duration) IssueActivity {
+		owner, repo, _ := splitGitLabPathWithNamespace(project)
+		updatedAt := now.Add(-age)
+		return IssueActivity{
+			Label:     label,
+			Owner:     owner,
+			Repo:      repo,
+			UpdatedAt: updatedAt,
+			Issue: IssueModel{
+				Number:    iid,
+				Title:     title,
+				State:     state,
+				CreatedAt: updatedAt.Add(-demoCreatedBefore(iid)),
+				UpdatedAt: updatedAt,
+				UserLogin: author,
+				WebURL:    fmt.Sprintf("%s/%s/-/issues/%d", demoBaseURL, project, iid),
+			},
+		}
+	}
+
+	checkout := mr("acme/shop/checkout", 482, "Review Requested", "Retry card authorization on gateway timeouts", "priya", "open", false, 35*time.Minute)
+	checkout.HasUpdates = true
+	checkout.MR.Squash = true
+	checkout.MR.Reviewers = []string{"demo", "jonas"}
+	checkout.MR.SourceBranch, checkout.MR.TargetBranch = "card-auth-retries", "main"
+	checkout.MR.ProjectLabels = []ProjectLabel{{Name: "bug", Color: "#dc143c"}, {Name: "payments", Color: "#6699cc"}}
+	checkout.Issues = []IssueActivity{
+		issue("acme/shop/checkout", 311, "Mentioned", "Checkout fails intermittently during peak traffic", "sam", "open", 2*time.Hour),
+	}
+
+	ratelimit := mr("acme/platform/api-gateway", 127, "Approval Requested", "Per-tenant rate limits for public API", "jonas", "open", false, 3*time.Hour)
+	ratelimit.MR.MergeMethod = "ff"
+	ratelimit.MR.SourceBranch, ratelimit.MR.TargetBranch = "tenant-rate-limits", "release/2.4"
+
+	search := mr("acme/shop/catalog", 93, "Assigned", "Index product variants for faceted search", "demo", "open", false, 6*time.Hour)
+	search.MR.MergeMethod = "rebase_merge"
+	search.MR.Assignees = []string{"demo", "lena"}
+
+	forked := mr("acme/docs", 58, "Mentioned", "Fix broken links in the onboarding guide", "contributor42", "open", false, 20*time.Hour)
+	forked.MR.SourceProject = "contributor42/docs"
+
+	comments := mr("acme/platform/api-gateway", 121, "Commented", "Switch health checks to gRPC", "lena", "open", false, 26*time.Hour)
+
+	mine := mr("acme/platform/infra", 640, "Authored", "Bump Postgres to 16 on staging", "demo", "open", false, 2*24*time.Hour)
+	mine.Issues = []IssueActivity{
+		issue("acme/platform/infra", 702, "Assigned", "Plan Postgres 16 production rollout", "ops-bot", "open", 2*24*time.Hour),
+	}
+	mine.Issues[0].Issue.Weight = 5
+	mine.Issues[0].Issue.Assignees = []string{"demo"}
+
+	reviewed := mr("acme/shop/catalog", 88, "Reviewed", "Cache category tree in Redis", "priya", "closed", true, 3*24*time.Hour)
+	abandoned := mr("acme/platform/infra", 612, "Authored", "Experiment: move CI runners to spot instances", "demo", "closed", false, 9*24*time.Hour)
+	shipped := mr("acme/docs", 51, "Authored", "Document the new release process", "demo", "closed", true, 12*24*time.Hour)
+
+	activities := []PRActivity{checkout, ratelimit, search, forked, comments, mine, reviewed, abandoned, shipped}
+	issueActivities := []IssueActivity{
+		issue("acme/shop/checkout", 318, "Assigned", "Add idempotency keys to refunds endpoint", "priya", "open", 5*time.Hour),
+		issue("acme/platform/api-gateway", 140, "Mentioned", "Document rate limit headers", "jonas", "open", 30*time.Hour),
+		issue("acme/docs", 44, "Commented", "Screenshots are outdated in the quick start", "lena", "open", 4*24*time.Hour),
+		issue("acme/shop/catalog", 77, "Authored", "Search ignores accented characters", "demo", "closed", 6*24*time.Hour),
+	}
+	issueActivities[0].HasUpdates = true
+	issueActivities[0].Issue.DueDate = now.AddDate(0, 0, 2)
+	issueActivities[1].Issue.DueDate = now.AddDate(0, 0, -3)
+	issueActivities[0].Issue.Weight = 3
+	issueActivities[0].Issue.ProjectLabels = []ProjectLabel{{Name: "backend", Color: "#428bca"}}
+
+	var filteredActivities []PRActivity
+	for _, activity := range activities {
+		if !activity.UpdatedAt.Before(cutoff) {
+			filteredActivities = append(filteredActivities, activity)
+		}
+	}
+	var filteredIssues []IssueActivity
+	for _, issue := range issueActivities {
+		if !issue.UpdatedAt.Before(cutoff) {
+			filteredIssues = append(filteredIssues, issue)
+		}
+	}
+
+	return filteredActivities, filteredIssues
+}
+
+func demoProjectBadges() map[string][]string {
+	return map[string][]string{
+		"acme/shop/checkout":        {"go", "payments"},
+		"acme/shop/catalog":         {"typescript", "search"},
+		"acme/platform/api-gateway": {"go", "backend"},
+		"acme/platform/infra":       {"hcl", "terraform"},
+		"acme/docs":                 {"markdown"},
+	}
+}
+
+// demoCreatedBefore spreads creation times so --sla shows both countdowns and
+// overdue items in the demo feed.
+func demoCreatedBefore(iid int) time.Duration {
+	return time.Duration(iid%4) * 8 * time.Hour
+}
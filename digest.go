@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// digestWindow is the previous calendar week (Monday to Monday) in location,
+// so a Monday cron run reports the week that just ended.
+func digestWindow(now time.Time, location *time.Location) (time.Time, time.Time) {
+	if location == nil {
+		location = time.Local
+	}
+	now = now.In(location)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+	weekStart := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	return weekStart.AddDate(0, 0, -7), weekStart
+}
+
+// repoDigest counts one project's activity in the digest window: items
+// created, merged and closed in it. Cache entries without a merge or close
+// time count by their last update instead.
+type repoDigest struct {
+	Project      string
+	MRsOpened    int
+	MRsMerged    int
+	MRsClosed    int
+	IssuesOpened int
+	IssuesClosed int
+}
+
+// summarizeDigest counts merge/pull requests and issues (nested ones
+// included) per project for [since, until), sorted by project path.
+func summarizeDigest(activities []PRActivity, issueActivities []IssueActivity, since, until time.Time) []repoDigest {
+	byProject := make(map[string]*repoDigest)
+	digestFor := func(owner, repo string) *repoDigest {
+		project := projectDisplayPath(owner, repo)
+		digest, ok := byProject[project]
+		if !ok {
+			digest = &repoDigest{Project: project}
+			byProject[project] = digest
+		}
+		return digest
+	}
+	inWindow := func(at, updatedAt time.Time) bool {
+		if at.IsZero() {
+			at = updatedAt
+		}
+		return !at.Before(since) && (until.IsZero() || at.Before(until))
+	}
+	addIssue := func(issue IssueActivity) {
+		digest := digestFor(issue.Owner, issue.Repo)
+		if inWindow(issue.Issue.CreatedAt, time.Time{}) {
+			digest.IssuesOpened++
+		}
+		if issue.Issue.State == "closed" && inWindow(issue.Issue.ClosedAt, issue.Issue.UpdatedAt) {
+			digest.IssuesClosed++
+		}
+	}
+
+	for _, activity := range activities {
+		digest := digestFor(activity.Owner, activity.Repo)
+		if inWindow(activity.MR.CreatedAt, time.Time{}) {
+			digest.MRsOpened++
+		}
+		switch activityState(activity.MR) {
+		case "merged":
+			if inWindow(activity.MR.MergedAt, activity.MR.UpdatedAt) {
+				digest.MRsMerged++
+			}
+		case "closed":
+			if inWindow(activity.MR.ClosedAt, activity.MR.UpdatedAt) {
+				digest.MRsClosed++
+			}
+		}
+		for _, issue := range activity.Issues {
+			addIssue(issue)
+		}
+	}
+	for _, issue := range issueActivities {
+		addIssue(issue)
+	}
+
+	digests := make([]repoDigest, 0, len(byProject))
+	for _, digest := range byProject {
+		digests = append(digests, *digest)
+	}
+	sort.Slice(digests, func(i, j int) bool {
+		return strings.ToLower(digests[i].Project) < strings.ToLower(digests[j].Project)
+	})
+	return digests
+}
+
+func renderDigest(platform string, digests []repoDigest, since, until time.Time) string {
+	mrName := "PRs"
+	if platform == "gitlab" {
+		mrName = "MRs"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Weekly digest: %s to %s\n", displayTime(since).Format("2006-01-02"), displayTime(until.Add(-time.Nanosecond)).Format("2006-01-02"))
+	if len(digests) == 0 {
+		b.WriteString("\nNo activity found.\n")
+		return b.String()
+	}
+
+	writeRow := func(digest repoDigest) {
+		fmt.Fprintf(&b, "  %-36s %10d %7d %7d %13d %7d\n", digest.Project, digest.MRsOpened, digest.MRsMerged, digest.MRsClosed, digest.IssuesOpened, digest.IssuesClosed)
+	}
+	fmt.Fprintf(&b, "\n  %-36s %10s %7s %7s %13s %7s\n", "PROJECT", mrName+" opened", "merged", "closed", "issues opened", "closed")
+	total := repoDigest{Project: "Total"}
+	for _, digest := range digests {
+		writeRow(digest)
+		total.MRsOpened += digest.MRsOpened
+		total.MRsMerged += digest.MRsMerged
+		total.MRsClosed += digest.MRsClosed
+		total.IssuesOpened += digest.IssuesOpened
+		total.IssuesClosed += digest.IssuesClosed
+	}
+	writeRow(total)
+	return b.String()
+}
+
+// fetchAndDisplayDigest prints the --digest report for the window set up in
+// main and reports whether the feed has actionable items.
+func fetchAndDisplayDigest(platform string, out io.Writer) (bool, error) {
+	activities, issueActivities, err := fetchActivities(platform)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch activity: %w", err)
+	}
+
+	fmt.Fprint(out, renderDigest(platform, summarizeDigest(activities, issueActivities, config.since, config.until), config.since, config.until))
+	return hasActionableItems(activities, issueActivities), nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// appDirs are where git-feed keeps its settings (.env, config.yaml) and its
+// data (the cache databases).
+type appDirs struct {
+	Config string
+	Data   string
+}
+
+// resolveAppDirs follows the XDG base directory spec: settings go to
+// $XDG_CONFIG_HOME/git-feed (~/.config/git-feed) and caches to
+// $XDG_DATA_HOME/git-feed (~/.local/share/git-feed). GIT_FEED_HOME puts both
+// in one directory, and an existing ~/.git-feed from before XDG support keeps
+// being used as such a directory, so upgrading never loses settings or caches.
+func resolveAppDirs(homeDir string) appDirs {
+	if home := strings.TrimSpace(os.Getenv("GIT_FEED_HOME")); home != "" {
+		return appDirs{Config: home, Data: home}
+	}
+	legacy := filepath.Join(homeDir, ".git-feed")
+	if info, err := os.Stat(legacy); err == nil && info.IsDir() {
+		return appDirs{Config: legacy, Data: legacy}
+	}
+	return appDirs{
+		Config: filepath.Join(xdgBaseDir("XDG_CONFIG_HOME", filepath.Join(homeDir, ".config")), "git-feed"),
+		Data:   filepath.Join(xdgBaseDir("XDG_DATA_HOME", filepath.Join(homeDir, ".local", "share")), "git-feed"),
+	}
+}
+
+// xdgBaseDir reads an XDG base directory variable. The spec says relative
+// paths are invalid and must be ignored.
+func xdgBaseDir(name, fallback string) string {
+	if dir := strings.TrimSpace(os.Getenv(name)); filepath.IsAbs(dir) {
+		return dir
+	}
+	return fallback
+}
@@ -0,0 +1,402 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+	bolt "go.etcd.io/bbolt"
+)
+
+// doctorOptions holds what `doctor` needs from the command line. It runs
+// before main loads any configuration, so it can report a broken config.yaml
+// or token command instead of stopping at the first error.
+type doctorOptions struct {
+	EnvPath      string
+	ConfigPath   string
+	DataDir      string
+	Profile      string
+	AllowedRepos string
+	Transport    transportOptions
+}
+
+// doctorCheck is one line of the doctor report. Hint says how to fix a
+// failed check.
+type doctorCheck struct {
+	Name   string
+	Detail string
+	Hint   string
+	Failed bool
+}
+
+// doctorReport prints each check as it completes, since the GitLab calls can
+// take a while on a slow instance.
+type doctorReport struct {
+	w      io.Writer
+	failed int
+}
+
+func (r *doctorReport) add(check doctorCheck) {
+	mark := symbols.Passed
+	if check.Failed {
+		mark = symbols.Blocked
+		r.failed++
+	}
+	fmt.Fprintf(r.w, "%s %s: %s\n", mark, check.Name, check.Detail)
+	if check.Failed && check.Hint != "" {
+		fmt.Fprintf(r.w, "    %s %s\n", symbols.Arrow, check.Hint)
+	}
+}
+
+// runDoctor checks the GitLab setup one step at a time: the .env and
+// config.yaml syntax, the base URL, the token, the cache and each allowed
+// project. It returns an error when any check failed.
+func runDoctor(ctx context.Context, opts doctorOptions, w io.Writer) error {
+	report := &doctorReport{w: w}
+
+	report.add(checkEnvFile(opts.EnvPath))
+	fileConfig, check := checkConfigFile(opts.ConfigPath, opts.EnvPath, opts.Profile)
+	report.add(check)
+	// Same precedence as a regular run: environment, config.yaml, the .env
+	// profile section, then the rest of .env.
+	applyConfigFileEnv(fileConfig)
+	if opts.Profile != "" {
+		_, _ = applyEnvFileProfile(opts.EnvPath, opts.Profile)
+	}
+	_ = loadEnvFile(opts.EnvPath)
+
+	if strings.TrimSpace(opts.Transport.caCert) == "" {
+		opts.Transport.caCert = strings.TrimSpace(os.Getenv("GITLAB_CA_CERT"))
+	}
+	transport, err := newHTTPTransport(opts.Transport)
+	if err != nil {
+		report.add(doctorCheck{Name: "Network settings", Detail: err.Error(), Hint: "Fix --proxy, --ca-cert or GITLAB_CA_CERT", Failed: true})
+	} else {
+		config.transport = transport
+	}
+
+	baseURL, check := checkGitLabBaseURL()
+	report.add(check)
+
+	var client *gitlab.Client
+	if check.Failed {
+		report.add(doctorCheck{Name: "Token", Detail: "not checked without a valid GitLab URL", Failed: true})
+	} else {
+		var token string
+		token, baseURL, check = resolveDoctorToken(baseURL)
+		if !check.Failed {
+			client, check = checkGitLabToken(ctx, token, baseURL, check.Detail)
+		}
+		report.add(check)
+	}
+
+	report.add(checkCache(opts.DataDir, opts.Profile, baseURL))
+
+	for _, check := range checkAllowedProjects(ctx, client, resolveAllowedRepos("gitlab", opts.AllowedRepos)) {
+		report.add(check)
+	}
+
+	if report.failed > 0 {
+		return fmt.Errorf("%d check(s) failed", report.failed)
+	}
+	fmt.Fprintln(w, "All checks passed")
+	return nil
+}
+
+// checkEnvFile reports .env lines that are neither comments, section headers
+// nor KEY=value entries; loadEnvFile skips those silently.
+func checkEnvFile(path string) doctorCheck {
+	check := doctorCheck{Name: ".env"}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		check.Detail = path + " (not present)"
+		return check
+	}
+	if err != nil {
+		check.Detail, check.Failed = err.Error(), true
+		return check
+	}
+	defer file.Close()
+
+	var malformed []string
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if _, ok := envFileSectionHeader(line); ok {
+			continue
+		}
+		if key, _, ok := strings.Cut(line, "="); !ok || strings.TrimSpace(key) == "" {
+			malformed = append(malformed, fmt.Sprint(lineNumber))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		check.Detail, check.Failed = fmt.Sprintf("%s: %v", path, err), true
+		return check
+	}
+	if len(malformed) > 0 {
+		check.Detail = fmt.Sprintf("%s: line %s is not KEY=value", path, strings.Join(malformed, ", "))
+		check.Hint = "Comment the line out with # or write it as KEY=value"
+		check.Failed = true
+		return check
+	}
+	check.Detail = path
+	return check
+}
+
+// checkConfigFile parses config.yaml the way a regular run does and checks
+// that the profile, if one is selected, exists somewhere.
+func checkConfigFile(path, envPath, profile string) (configFile, doctorCheck) {
+	check := doctorCheck{Name: "config.yaml"}
+	fileConfig, err := loadConfigFile(path)
+	if err != nil {
+		check.Detail, check.Failed = err.Error(), true
+		check.Hint = "Fix the YAML, or list the valid keys with `git-feed config list`"
+		return configFile{}, check
+	}
+	fileConfig, fileProfileFound := fileConfig.withProfile(profile)
+	if _, err := parseRepoOptions(fileConfig.Repos); err != nil {
+		check.Detail, check.Failed = fmt.Sprintf("%s: %v", path, err), true
+		check.Hint = "Fix the repos section"
+		return configFile{}, check
+	}
+	if profile != "" && !fileProfileFound {
+		if entries, err := loadEnvFileSection(envPath, "profile."+profile); err != nil || len(entries) == 0 {
+			check.Detail, check.Failed = fmt.Sprintf("profile %q not found", profile), true
+			check.Hint = fmt.Sprintf("Add a profiles.%s entry to %s or a [profile.%s] section to %s", profile, path, profile, envPath)
+			return fileConfig, check
+		}
+	}
+	check.Detail = path
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		check.Detail += " (not present)"
+	}
+	return fileConfig, check
+}
+
+// checkGitLabBaseURL normalizes GITLAB_HOST (or GITLAB_BASE_URL) the way the
+// GitLab client is configured.
+func checkGitLabBaseURL() (string, doctorCheck) {
+	check := doctorCheck{Name: "GitLab URL"}
+	source := "GITLAB_BASE_URL"
+	raw := os.Getenv("GITLAB_BASE_URL")
+	if strings.TrimSpace(os.Getenv("GITLAB_HOST")) != "" {
+		source, raw = "GITLAB_HOST", os.Getenv("GITLAB_HOST")
+	}
+	if strings.TrimSpace(raw) == "" {
+		source = "default"
+	}
+
+	baseURL, err := normalizeGitLabBaseURL(raw)
+	if err != nil {
+		check.Detail, check.Failed = err.Error(), true
+		check.Hint = "Set GITLAB_HOST to the instance's root URL, e.g. https://gitlab.example.com"
+		return "", check
+	}
+	check.Detail = fmt.Sprintf("%s (from %s)", baseURL, source)
+	return baseURL, check
+}
+
+// resolveDoctorToken finds the token in the order a regular run does and
+// returns where it came from in the check's Detail. A glab CLI token may be for
+// another host, whose base URL is returned instead.
+func resolveDoctorToken(baseURL string) (string, string, doctorCheck) {
+	check := doctorCheck{Name: "Token"}
+	for _, name := range []string{"GITLAB_ACTIVITY_TOKEN", "GITLAB_TOKEN"} {
+		if token := os.Getenv(name); token != "" {
+			check.Detail = name
+			return token, baseURL, check
+		}
+	}
+	if command := strings.TrimSpace(os.Getenv(tokenCommandEnvVar("gitlab"))); command != "" {
+		token, err := runTokenCommand(command)
+		if err != nil {
+			check.Detail, check.Failed = fmt.Sprintf("%s: %v", tokenCommandEnvVar("gitlab"), err), true
+			check.Hint = "Run the command by hand; it must print the token and exit 0"
+			return "", baseURL, check
+		}
+		check.Detail = tokenCommandEnvVar("gitlab")
+		return token, baseURL, check
+	}
+	if token, err := keyringToken("gitlab", baseURL); err == nil && token != "" {
+		check.Detail = "the system keyring"
+		return token, baseURL, check
+	}
+	hostConfigured := strings.TrimSpace(os.Getenv("GITLAB_HOST")) != "" || strings.TrimSpace(os.Getenv("GITLAB_BASE_URL")) != ""
+	if creds, ok := loadGlabCredentials(glabConfigPath(), baseURL, hostConfigured); ok {
+		check.Detail = "the glab CLI (" + creds.Host + ")"
+		return creds.Token, creds.BaseURL, check
+	}
+
+	check.Detail, check.Failed = "no GitLab token is configured", true
+	check.Hint = "Run `git-feed --platform gitlab auth login`, or set GITLAB_TOKEN"
+	return "", baseURL, check
+}
+
+// checkGitLabToken signs in with the token and checks that its scopes can
+// read the feed. source says where the token came from.
+func checkGitLabToken(ctx context.Context, token, baseURL, source string) (*gitlab.Client, doctorCheck) {
+	check := doctorCheck{Name: "Token"}
+	client, _, err := newGitLabClient(token, baseURL)
+	if err != nil {
+		check.Detail, check.Failed = err.Error(), true
+		return nil, check
+	}
+
+	capabilities, err := detectGitLabCapabilities(ctx, client)
+	if err != nil {
+		check.Detail, check.Failed = err.Error(), true
+		check.Hint = "Store the new token with `git-feed --platform gitlab auth login`"
+		return nil, check
+	}
+	if !capabilities.ReadAPI {
+		check.Detail = fmt.Sprintf("token scopes [%s] cannot read merge requests and issues", strings.Join(capabilities.Scopes, ", "))
+		check.Hint = "Create a token with the read_api scope at " + personalAccessTokensURL(client)
+		check.Failed = true
+		return nil, check
+	}
+	user, err := verifyGitLabToken(ctx, client)
+	if err != nil {
+		check.Detail, check.Failed = err.Error(), true
+		check.Hint = "Check GITLAB_HOST, and --proxy or --ca-cert when the instance is behind a proxy or a private CA"
+		return nil, check
+	}
+
+	check.Detail = fmt.Sprintf("signed in as %s (token from %s)", user.Username, source)
+	if capabilities.ExpiresAt != nil {
+		check.Detail += ", expires on " + capabilities.ExpiresAt.Format("2006-01-02")
+	}
+	return client, check
+}
+
+// checkCache opens the cache of the GitLab host and reads every item in it,
+// which is what a --local run does.
+func checkCache(dataDir, profile, baseURL string) doctorCheck {
+	check := doctorCheck{Name: "Cache"}
+	backend, err := resolveCacheBackend()
+	if err != nil {
+		check.Detail, check.Failed = err.Error(), true
+		return check
+	}
+	encrypt, err := resolveCacheEncryption()
+	if err != nil {
+		check.Detail, check.Failed = err.Error(), true
+		return check
+	}
+	var key *[32]byte
+	if encrypt {
+		if backend != cacheBackendBolt {
+			check.Detail, check.Failed = "CACHE_ENCRYPTION is only supported by the bolt cache backend", true
+			return check
+		}
+		if key, err = loadCacheKey(); err != nil {
+			check.Detail, check.Failed = err.Error(), true
+			return check
+		}
+	}
+
+	dbPath := filepath.Join(dataDir, cacheFileName(hostDBFileName(profileDBFileName("gitlab", profile), baseURL), backend))
+	info, err := os.Stat(dbPath)
+	if errors.Is(err, os.ErrNotExist) {
+		check.Detail = dbPath + " (not created yet; the first run creates it)"
+		return check
+	}
+	if err != nil {
+		check.Detail, check.Failed = err.Error(), true
+		return check
+	}
+
+	stats, snapshot, err := readOnlyCacheStats(dbPath, key)
+	if err != nil {
+		check.Detail, check.Failed = fmt.Sprintf("%s: %v", dbPath, err), true
+		check.Hint = "Run with --clean to start over with an empty cache"
+		return check
+	}
+
+	check.Detail = fmt.Sprintf("%s (%s, %d project(s))", dbPath, formatByteSize(info.Size()), len(stats.Projects))
+	if snapshot {
+		check.Detail += "; another run is using it, so a snapshot was checked"
+	}
+	return check
+}
+
+// readOnlyCacheStats reads the stats of the cache at path without creating
+// buckets or tables, applying queued writes or changing permissions, so doctor
+// leaves the cache as it found it. A bolt cache another run holds is read from
+// a snapshot, which the second result reports.
+func readOnlyCacheStats(path string, key *[32]byte) (cacheStats, bool, error) {
+	if isSQLiteCachePath(path) {
+		db, err := sql.Open("sqlite", "file:"+path+"?mode=ro&_pragma=busy_timeout(1000)")
+		if err != nil {
+			return cacheStats{}, false, err
+		}
+		defer db.Close()
+		stats, err := (&sqliteDatabase{db: db}).Stats()
+		return stats, false, err
+	}
+
+	snapshot := ""
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 1 * time.Second, ReadOnly: true})
+	if errors.Is(err, bolt.ErrTimeout) {
+		if snapshot, err = snapshotBoltFile(path); err != nil {
+			return cacheStats{}, false, fmt.Errorf("database is in use by another run, and copying it failed: %w", err)
+		}
+		defer os.Remove(snapshot)
+		db, err = bolt.Open(snapshot, 0600, &bolt.Options{Timeout: 1 * time.Second, ReadOnly: true})
+	}
+	if err != nil {
+		return cacheStats{}, false, err
+	}
+	defer db.Close()
+
+	var stats cacheStats
+	err = db.View(func(tx *bolt.Tx) error {
+		var err error
+		stats, err = (&boltDatabase{key: key}).statsTx(tx)
+		return err
+	})
+	return stats, snapshot != "", err
+}
+
+// checkAllowedProjects looks up each allowed project with the verified token.
+func checkAllowedProjects(ctx context.Context, client *gitlab.Client, allowedRepos string) []doctorCheck {
+	repos := make([]string, 0)
+	for repo := range parseRepoList(allowedRepos) {
+		repos = append(repos, normalizeProjectPathWithNamespace(repo))
+	}
+	sort.Strings(repos)
+
+	if len(repos) == 0 {
+		return []doctorCheck{{
+			Name:   "Projects",
+			Detail: "no projects are configured",
+			Hint:   "Set GITLAB_ALLOWED_REPOS, e.g. `git-feed config set gitlab.allowed_repos group/project`",
+			Failed: true,
+		}}
+	}
+	if client == nil {
+		return []doctorCheck{{Name: "Projects", Detail: "not checked without a working token", Failed: true}}
+	}
+
+	checks := make([]doctorCheck, 0, len(repos))
+	for _, repo := range repos {
+		check := doctorCheck{Name: repo, Detail: "readable"}
+		if failures := unreadableGitLabProjects(ctx, client, []string{repo}); len(failures) > 0 {
+			check.Detail, check.Failed = failures[0].Err.Error(), true
+			check.Hint = "Check the path (group[/subgroup]/repo) and that the token's user is a member"
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/fatih/color"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// gitLabCountCap is the item count above which GitLab stops sending X-Total
+// (and X-Total-Pages) for performance reasons.
+const gitLabCountCap = 10000
+
+// dryRunProject is what a dry run found out about one project: how many merge
+// requests and issues the fetch would list. Capped counts are lower bounds.
+type dryRunProject struct {
+	Path                string
+	MergeRequests       int
+	Issues              int
+	MergeRequestsCapped bool
+	IssuesCapped        bool
+	Resumed             bool
+	// Chunks is the number of monthly chunks a long window is listed in.
+	Chunks int
+}
+
+// apiCallEstimate is the expected number of calls to one endpoint. UpTo marks
+// upper bounds: calls that depend on the labels derived while fetching (an
+// authored merge request needs neither approvals nor notes) or on the cache.
+type apiCallEstimate struct {
+	Endpoint string
+	Calls    int
+	UpTo     bool
+}
+
+// estimateGitLabAPICalls works out the calls a fetch of projects makes per
+// endpoint, mirroring fetchProjectItems and the cross-reference linking,
+// with each project's counts capped at --max-items and its REST pages counted
+// per listing chunk.
+func estimateGitLabAPICalls(projects []dryRunProject, apiBackend string) []apiCallEstimate {
+	pages := func(items, pageSize int) int {
+		return max(1, (items+pageSize-1)/pageSize)
+	}
+	var mergeRequests, issues, mrPages, issuePages, projectsWithMRs int
+	for _, project := range projects {
+		projectMRs, projectIssues := project.MergeRequests, project.Issues
+		if config.maxItems > 0 {
+			projectMRs, projectIssues = min(projectMRs, config.maxItems), min(projectIssues, config.maxItems)
+		}
+		mergeRequests += projectMRs
+		issues += projectIssues
+		if apiBackend == "graphql" {
+			mrPages += pages(projectMRs, gitLabGraphQLPageSize)
+			issuePages += pages(projectIssues, gitLabGraphQLPageSize)
+		} else {
+			// Each chunk past the first adds at most one partly filled page.
+			extraPages := max(0, project.Chunks-1)
+			mrPages += pages(projectMRs, pageSize()) + extraPages
+			issuePages += pages(projectIssues, pageSize()) + extraPages
+		}
+		if projectMRs > 0 {
+			projectsWithMRs++
+		}
+	}
+
+	if apiBackend == "graphql" {
+		// Approvals and notes come with the listing; long note threads are
+		// fetched over REST and not counted here.
+		return []apiCallEstimate{
+			{Endpoint: "POST /graphql (merge request pages)", Calls: mrPages},
+			{Endpoint: "POST /graphql (issue pages)", Calls: issuePages},
+			{Endpoint: "GET /projects/:id/merge_requests/:iid/closes_issues", Calls: mergeRequests},
+		}
+	}
+	return []apiCallEstimate{
+		{Endpoint: "GET /projects/:id/merge_requests", Calls: mrPages},
+		{Endpoint: "GET /projects/:id/issues", Calls: issuePages},
+		{Endpoint: "GET /projects/:id/labels", Calls: projectsWithMRs, UpTo: true},
+		{Endpoint: "GET /projects/:id/merge_requests/:iid/approval_state", Calls: mergeRequests, UpTo: true},
+		{Endpoint: "GET /projects/:id/merge_requests/:iid/notes", Calls: mergeRequests, UpTo: true},
+		{Endpoint: "GET /projects/:id/issues/:iid/notes", Calls: issues, UpTo: true},
+		{Endpoint: "GET /projects/:id/merge_requests/:iid/closes_issues", Calls: mergeRequests},
+	}
+}
+
+// countGitLabProjectItems asks for one item per listing and reads the totals
+// GitLab reports alongside, so counting costs two calls per project. Counts
+// past gitLabCountCap are capped there.
+func countGitLabProjectItems(ctx context.Context, client *gitlab.Client, projectID int64, mergeRequestCutoff, issueCutoff time.Time) (dryRunProject, error) {
+	count := func(response *gitlab.Response, listed int) (int, bool) {
+		switch {
+		case response == nil:
+			return listed, false
+		case response.TotalItems > 0 || response.NextPage == 0:
+			return max(int(response.TotalItems), listed), false
+		default:
+			return gitLabCountCap, true
+		}
+	}
+
+	var mrResponse *gitlab.Response
+	var mergeRequests []*gitlab.BasicMergeRequest
+	err := retryWithBackoff(func() error {
+		var apiErr error
+		mergeRequests, mrResponse, apiErr = client.MergeRequests.ListProjectMergeRequests(projectID, &gitlab.ListProjectMergeRequestsOptions{
+			ListOptions:  gitlab.ListOptions{PerPage: 1, Page: 1},
+			State:        gitlab.Ptr("all"),
+			UpdatedAfter: &mergeRequestCutoff,
+		}, gitlab.WithContext(ctx))
+		return apiErr
+	}, fmt.Sprintf("GitLabCountProjectMergeRequests %d", projectID))
+	if err != nil {
+		return dryRunProject{}, fmt.Errorf("count merge requests: %w", err)
+	}
+
+	var issueResponse *gitlab.Response
+	var issues []*gitlab.Issue
+	err = retryWithBackoff(func() error {
+		var apiErr error
+		issues, issueResponse, apiErr = client.Issues.ListProjectIssues(projectID, &gitlab.ListProjectIssuesOptions{
+			ListOptions:  gitlab.ListOptions{PerPage: 1, Page: 1},
+			State:        gitlab.Ptr("all"),
+			UpdatedAfter: &issueCutoff,
+		}, gitlab.WithContext(ctx))
+		return apiErr
+	}, fmt.Sprintf("GitLabCountProjectIssues %d", projectID))
+	if err != nil {
+		return dryRunProject{}, fmt.Errorf("count issues: %w", err)
+	}
+
+	var counted dryRunProject
+	counted.MergeRequests, counted.MergeRequestsCapped = count(mrResponse, len(mergeRequests))
+	counted.Issues, counted.IssuesCapped = count(issueResponse, len(issues))
+	return counted, nil
+}
+
+// runGitLabDryRun implements --dry-run: it resolves the allowed projects,
+// counts their items in the window and prints the API calls a fetch would
+// make, without labeling or caching anything.
+func runGitLabDryRun(ctx context.Context, p *gitLabPlatform, cutoff time.Time, out io.Writer) error {
+	if config.localMode {
+		return fmt.Errorf("--dry-run needs API access, which this token does not have")
+	}
+	if err := p.ResolveProjects(ctx); err != nil {
+		return err
+	}
+	breaker := p.breaker
+	requested := len(p.projects) + len(breaker.open)
+	defer func() { config.failedProjects = breaker.failures() }()
+
+	checkpoints := loadSyncCheckpoints(p.db, time.Now())
+	var counted []dryRunProject
+	for _, project := range p.projects {
+		projectCutoff := repoCutoff(project.PathWithNamespace, cutoff)
+		_, resumed := checkpoints.resumeFrom(project.PathWithNamespace, projectCutoff)
+		mergeRequestCutoff, issueCutoff := checkpoints.listingCutoffs(project.PathWithNamespace, projectCutoff)
+		projectCount, err := countGitLabProjectItems(ctx, p.client, project.ID, mergeRequestCutoff, issueCutoff)
+		if err != nil {
+			err = fmt.Errorf("%s: %w", project.PathWithNamespace, err)
+			if breaker.skip(project.PathWithNamespace, err) {
+				continue
+			}
+			return err
+		}
+		projectCount.Path, projectCount.Resumed = project.PathWithNamespace, resumed
+		projectCount.Chunks = len(gitLabListingChunks(earlierTime(mergeRequestCutoff, issueCutoff), time.Now()))
+		counted = append(counted, projectCount)
+	}
+	if err := breaker.allFailed(requested); err != nil {
+		return err
+	}
+
+	writeDryRun(out, counted, estimateGitLabAPICalls(counted, config.apiBackend), cutoff)
+	return nil
+}
+
+func writeDryRun(out io.Writer, projects []dryRunProject, estimates []apiCallEstimate, cutoff time.Time) {
+	heading := color.New(color.Bold)
+	fmt.Fprintln(out, heading.Sprintf("Dry run: %d GitLab projects, items updated since %s", len(projects), displayTime(cutoff).Format("2006-01-02 15:04")))
+	capped := false
+	for _, project := range projects {
+		note := ""
+		if project.Resumed {
+			note = " (resumed from the last run's checkpoint)"
+		}
+		mrPlus, issuePlus := "", ""
+		if project.MergeRequestsCapped {
+			mrPlus, capped = "+", true
+		}
+		if project.IssuesCapped {
+			issuePlus, capped = "+", true
+		}
+		fmt.Fprintf(out, "  %-50s %5d%s merge requests, %5d%s issues%s\n", project.Path, project.MergeRequests, mrPlus, project.Issues, issuePlus, note)
+	}
+
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, heading.Sprint("Estimated API calls"))
+	total, upTo := 0, false
+	for _, estimate := range estimates {
+		prefix := ""
+		if estimate.UpTo {
+			prefix, upTo = "up to ", true
+		}
+		fmt.Fprintf(out, "  %-58s %12s\n", estimate.Endpoint, fmt.Sprintf("%s%d", prefix, estimate.Calls))
+		total += estimate.Calls
+	}
+	prefix := ""
+	if upTo {
+		prefix = "up to "
+	}
+	fmt.Fprintf(out, "  %-58s %12s\n", "Total", fmt.Sprintf("%s%d", prefix, total))
+
+	var notes []string
+	if upTo {
+		notes = append(notes, "\"Up to\" counts shrink for items you authored or are assigned to, and cached approvals are reused.")
+	}
+	if capped {
+		notes = append(notes, fmt.Sprintf("GitLab does not count past %d items, so + counts are lower bounds.", gitLabCountCap))
+	}
+	if config.maxAPICalls > 0 && total > config.maxAPICalls {
+		notes = append(notes, fmt.Sprintf("The estimate exceeds --max-api-calls %d, so the fetch may stop before every project is done.", config.maxAPICalls))
+	}
+	notes = append(notes, "Narrow ALLOWED_REPOS or --time to lower the estimate.")
+	fmt.Fprintln(out)
+	for _, note := range notes {
+		fmt.Fprintln(out, color.New(color.Faint).Sprint(note))
+	}
+}
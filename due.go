@@ -0,0 +1,61 @@
+package main
+
+import (
+	"math"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// dueInDays counts calendar days from today to the due date in the display
+// timezone: 0 is due today, negative is overdue. Due dates carry no time of
+// day, so only their year, month and day are used.
+func dueInDays(due, now time.Time) int {
+	today := displayTime(now)
+	location := today.Location()
+	todayStart := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, location)
+	dueStart := time.Date(due.Year(), due.Month(), due.Day(), 0, 0, 0, 0, location)
+	return int(math.Round(dueStart.Sub(todayStart).Hours() / 24))
+}
+
+// isOverdue reports whether an open issue is past its due date.
+func isOverdue(state string, due, now time.Time) bool {
+	return state != "closed" && !due.IsZero() && dueInDays(due, now) < 0
+}
+
+// dueBadge is the "(due 3d ago)" suffix of issues with a due date. Closed
+// issues have none.
+func dueBadge(state string, due, now time.Time) string {
+	if due.IsZero() || state == "closed" {
+		return ""
+	}
+	switch days := dueInDays(due, now); {
+	case days < 0:
+		return color.New(color.FgRed, color.Bold).Sprintf("(due %dd ago)", -days)
+	case days == 0:
+		return color.New(color.FgYellow, color.Bold).Sprint("(due today)")
+	default:
+		return color.New(color.Faint).Sprintf("(due in %dd)", days)
+	}
+}
+
+// filterActivitiesByDueSoon implements --due-soon: only issues due within
+// window (overdue ones included) are kept, and merge/pull requests only when
+// such an issue is nested under them.
+func filterActivitiesByDueSoon(activities []PRActivity, issueActivities []IssueActivity, window time.Duration, now time.Time) ([]PRActivity, []IssueActivity) {
+	if window <= 0 {
+		return activities, issueActivities
+	}
+
+	return filterActivitiesByIssue(activities, issueActivities, func(issue IssueActivity) bool {
+		return !issue.Issue.DueDate.IsZero() && dueInDays(issue.Issue.DueDate, now) <= int(window/(24*time.Hour))
+	})
+}
+
+// formatDueDate is the due date in exports, e.g. "2026-03-12".
+func formatDueDate(due time.Time) string {
+	if due.IsZero() {
+		return ""
+	}
+	return due.Format("2006-01-02")
+}
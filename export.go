@@ -0,0 +1,290 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// anonymizeKeyFile holds the per-install secret that keys the --anonymize
+// pseudonyms. It lives in the data directory and is never exported.
+const anonymizeKeyFile = "anonymize.key"
+
+type exportFeed struct {
+	Platform      string               `json:"platform"`
+	GeneratedAt   time.Time            `json:"generated_at"`
+	TimeRange     string               `json:"time_range"`
+	Since         time.Time            `json:"since"`
+	Until         *time.Time           `json:"until,omitempty"`
+	Anonymized    bool                 `json:"anonymized"`
+	MergeRequests []exportMergeRequest `json:"merge_requests"`
+	Issues        []exportIssue        `json:"issues"`
+}
+
+type exportMergeRequest struct {
+	Project       string        `json:"project"`
+	Number        int           `json:"number"`
+	Title         string        `json:"title"`
+	State         string        `json:"state"`
+	Label         string        `json:"label"`
+	Author        string        `json:"author"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+	URL           string        `json:"url,omitempty"`
+	HasUpdates    bool          `json:"has_updates"`
+	SourceProject string        `json:"source_project,omitempty"`
+	Squash        bool          `json:"squash,omitempty"`
+	MergeMethod   string        `json:"merge_method,omitempty"`
+	Checks        string        `json:"checks,omitempty"`
+	Assignees     []string      `json:"assignees,omitempty"`
+	Reviewers     []string      `json:"reviewers,omitempty"`
+	SourceBranch  string        `json:"source_branch,omitempty"`
+	TargetBranch  string        `json:"target_branch,omitempty"`
+	Labels        []string      `json:"labels,omitempty"`
+	Issues        []exportIssue `json:"issues,omitempty"`
+}
+
+type exportIssue struct {
+	Project    string    `json:"project"`
+	Number     int       `json:"number"`
+	Title      string    `json:"title"`
+	State      string    `json:"state"`
+	Label      string    `json:"label"`
+	Author     string    `json:"author"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	URL        string    `json:"url,omitempty"`
+	HasUpdates bool      `json:"has_updates"`
+	DueDate    string    `json:"due_date,omitempty"`
+	Weight     int       `json:"weight,omitempty"`
+	Assignees  []string  `json:"assignees,omitempty"`
+	Labels     []string  `json:"labels,omitempty"`
+}
+
+// loadAnonymizeKey reads the --anonymize key from dataDir, creating a random
+// one on first use.
+func loadAnonymizeKey(dataDir string) ([]byte, error) {
+	path := filepath.Join(dataDir, anonymizeKeyFile)
+	key, err := os.ReadFile(path)
+	if err == nil && len(key) >= 32 {
+		return key, nil
+	}
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read anonymize key: %w", err)
+	}
+
+	key = make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate anonymize key: %w", err)
+	}
+	if err := os.WriteFile(path, key, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to store anonymize key: %w", err)
+	}
+	return key, nil
+}
+
+// pseudonymize replaces a value with an HMAC of it under the per-install key,
+// so the same project or user maps to the same pseudonym in every export from
+// this machine, while nobody without the key can confirm a guess by hashing
+// known usernames or project paths.
+func pseudonymize(key []byte, kind, value string) string {
+	if value == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(kind + ":" + strings.ToLower(value)))
+	return kind + "-" + hex.EncodeToString(mac.Sum(nil)[:12])
+}
+
+// pseudonymizePath keeps the group/subgroup depth of a project path, which is
+// often what a path-related bug depends on, while hiding every segment.
+func pseudonymizePath(key []byte, path string) string {
+	if path == "" {
+		return ""
+	}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		kind := "group"
+		if i == len(segments)-1 {
+			kind = "project"
+		}
+		segments[i] = pseudonymize(key, kind, strings.Join(segments[:i], "/")+"/"+segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// buildExportFeed pseudonymizes the feed when anonymizeKey is set.
+func buildExportFeed(platform string, activities []PRActivity, issueActivities []IssueActivity, generatedAt time.Time, anonymizeKey []byte) exportFeed {
+	anonymize := len(anonymizeKey) > 0
+	activities, issueActivities = filterActivitiesByState(activities, issueActivities, config.states)
+	sort.Slice(activities, func(i, j int) bool {
+		return activities[i].UpdatedAt.After(activities[j].UpdatedAt)
+	})
+	sort.Slice(issueActivities, func(i, j int) bool {
+		return issueActivities[i].UpdatedAt.After(issueActivities[j].UpdatedAt)
+	})
+
+	project := func(owner, repo string) string {
+		path := projectDisplayPath(owner, repo)
+		if anonymize {
+			return pseudonymizePath(anonymizeKey, path)
+		}
+		return path
+	}
+	user := func(login string) string {
+		if anonymize {
+			return pseudonymize(anonymizeKey, "user", login)
+		}
+		return login
+	}
+	users := func(logins []string) []string {
+		var out []string
+		for _, login := range logins {
+			out = append(out, user(login))
+		}
+		return out
+	}
+	// Branch names often carry ticket numbers or feature names.
+	branch := func(name string) string {
+		if anonymize {
+			return ""
+		}
+		return name
+	}
+	// Labels can name customers or internal projects, like branches.
+	labels := func(projectLabels []ProjectLabel) []string {
+		if anonymize {
+			return nil
+		}
+		var out []string
+		for _, label := range projectLabels {
+			out = append(out, label.Name)
+		}
+		return out
+	}
+	title := func(kind, value string, number int) string {
+		if anonymize {
+			return fmt.Sprintf("%s %d", kind, number)
+		}
+		return value
+	}
+	url := func(value string) string {
+		if anonymize {
+			return ""
+		}
+		return value
+	}
+	issue := func(issue IssueActivity) exportIssue {
+		return exportIssue{
+			Project:    project(issue.Owner, issue.Repo),
+			Number:     issue.Issue.Number,
+			Title:      title("Issue", issue.Issue.Title, issue.Issue.Number),
+			State:      issue.Issue.State,
+			Label:      issue.Label,
+			Author:     user(issue.Issue.UserLogin),
+			UpdatedAt:  issue.UpdatedAt,
+			URL:        url(issue.Issue.WebURL),
+			HasUpdates: issue.HasUpdates,
+			DueDate:    formatDueDate(issue.Issue.DueDate),
+			Weight:     issue.Issue.Weight,
+			Assignees:  users(issue.Issue.Assignees),
+			Labels:     labels(issue.Issue.ProjectLabels),
+		}
+	}
+
+	feed := exportFeed{
+		Platform:      platform,
+		GeneratedAt:   generatedAt,
+		TimeRange:     config.timeRange.String(),
+		Since:         activityCutoff().UTC(),
+		Anonymized:    anonymize,
+		MergeRequests: make([]exportMergeRequest, 0, len(activities)),
+		Issues:        make([]exportIssue, 0, len(issueActivities)),
+	}
+	if !config.until.IsZero() {
+		until := config.until.UTC()
+		feed.Until = &until
+	}
+	for _, activity := range activities {
+		sourceProject := activity.MR.SourceProject
+		if anonymize {
+			sourceProject = pseudonymizePath(anonymizeKey, sourceProject)
+		}
+		mr := exportMergeRequest{
+			Project:       project(activity.Owner, activity.Repo),
+			Number:        activity.MR.Number,
+			Title:         title("Merge request", activity.MR.Title, activity.MR.Number),
+			State:         activityState(activity.MR),
+			Label:         activity.Label,
+			Author:        user(activity.MR.UserLogin),
+			UpdatedAt:     activity.UpdatedAt,
+			URL:           url(activity.MR.WebURL),
+			HasUpdates:    activity.HasUpdates,
+			SourceProject: sourceProject,
+			Squash:        activity.MR.Squash,
+			MergeMethod:   activity.MR.MergeMethod,
+			Checks:        activity.MR.CheckStatus,
+			Assignees:     users(activity.MR.Assignees),
+			Reviewers:     users(activity.MR.Reviewers),
+			SourceBranch:  branch(activity.MR.SourceBranch),
+			TargetBranch:  branch(activity.MR.TargetBranch),
+			Labels:        labels(activity.MR.ProjectLabels),
+		}
+		for _, nested := range activity.Issues {
+			mr.Issues = append(mr.Issues, issue(nested))
+		}
+		feed.MergeRequests = append(feed.MergeRequests, mr)
+	}
+	for _, standalone := range issueActivities {
+		feed.Issues = append(feed.Issues, issue(standalone))
+	}
+
+	return feed
+}
+
+func writeExportFeed(w io.Writer, feed exportFeed) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(feed)
+}
+
+func runExportCommand(platform, dataDir string, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	anonymize := flags.Bool("anonymize", false, "Replace project paths, usernames and titles with stable pseudonyms and drop URLs")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [options] export [--anonymize] > feed.json\n\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return fmt.Errorf("export does not take positional arguments (got %q)", flags.Args())
+	}
+
+	var anonymizeKey []byte
+	if *anonymize {
+		key, err := loadAnonymizeKey(dataDir)
+		if err != nil {
+			return err
+		}
+		anonymizeKey = key
+	}
+
+	activities, issueActivities, err := fetchActivities(platform)
+	if err != nil {
+		return fmt.Errorf("failed to fetch activity: %w", err)
+	}
+
+	return writeExportFeed(out, buildExportFeed(platform, activities, issueActivities, time.Now().UTC(), anonymizeKey))
+}
@@ -0,0 +1,391 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// gitHubPrefetched holds the pull request and issue details, review comments
+// and check statuses the GraphQL backend fetched together with the search
+// results, keyed by buildGitHubItemKey. A nil value (REST) or a missing entry
+// means the data has to be fetched per item.
+type gitHubPrefetched struct {
+	pullRequests   map[string]*github.PullRequest
+	issues         map[string]*github.Issue
+	reviewComments map[string][]*github.PullRequestComment
+	checkStatuses  map[string]string
+}
+
+func (p *gitHubPrefetched) pullRequest(key string) (*github.PullRequest, bool) {
+	if p == nil {
+		return nil, false
+	}
+	pr, ok := p.pullRequests[key]
+	return pr, ok
+}
+
+func (p *gitHubPrefetched) issue(key string) (*github.Issue, bool) {
+	if p == nil {
+		return nil, false
+	}
+	issue, ok := p.issues[key]
+	return issue, ok
+}
+
+func (p *gitHubPrefetched) checkStatus(key string) (string, bool) {
+	if p == nil {
+		return "", false
+	}
+	status, ok := p.checkStatuses[key]
+	return status, ok
+}
+
+func (p *gitHubPrefetched) pullRequestReviewComments(key string) ([]*github.PullRequestComment, bool) {
+	if p == nil {
+		return nil, false
+	}
+	comments, ok := p.reviewComments[key]
+	return comments, ok
+}
+
+const gitHubGraphQLPageSize = 50
+
+// Pull requests with more review threads or thread comments than these limits
+// get their review comments from REST. Together with the page size they keep a
+// page well below GitHub's node limit.
+const (
+	gitHubGraphQLThreadsLimit  = 50
+	gitHubGraphQLCommentsLimit = 20
+)
+
+// gitHubGraphQLAssigneesLimit matches GitHub's own cap of 10 assignees; it
+// also bounds the review requests read per pull request.
+const gitHubGraphQLAssigneesLimit = 10
+
+// gitHubGraphQLLabelsLimit bounds the labels read per pull request and issue.
+const gitHubGraphQLLabelsLimit = 20
+
+var gitHubSearchQuery = `query($query: String!, $after: String) {
+  search(query: $query, type: ISSUE, first: ` + strconv.Itoa(gitHubGraphQLPageSize) + `, after: $after) {
+    pageInfo { hasNextPage endCursor }
+    nodes {
+      __typename
+      ... on PullRequest {
+        number title body state merged createdAt updatedAt mergedAt closedAt url headRefName baseRefName
+        author { login }
+        assignees(first: ` + strconv.Itoa(gitHubGraphQLAssigneesLimit) + `) { nodes { login } }
+        reviewRequests(first: ` + strconv.Itoa(gitHubGraphQLAssigneesLimit) + `) { nodes { requestedReviewer { ... on User { login } } } }
+        labels(first: ` + strconv.Itoa(gitHubGraphQLLabelsLimit) + `) { nodes { name color } }
+        repository { name owner { login } }
+        commits(last: 1) { nodes { commit { statusCheckRollup { state } } } }
+        reviewThreads(first: ` + strconv.Itoa(gitHubGraphQLThreadsLimit) + `) {
+          pageInfo { hasNextPage }
+          nodes {
+            comments(first: ` + strconv.Itoa(gitHubGraphQLCommentsLimit) + `) {
+              pageInfo { hasNextPage }
+              nodes {
+                databaseId body
+                author { login ... on User { databaseId } ... on Bot { databaseId } }
+              }
+            }
+          }
+        }
+      }
+      ... on Issue {
+        number title body state createdAt updatedAt closedAt url
+        author { login }
+        assignees(first: ` + strconv.Itoa(gitHubGraphQLAssigneesLimit) + `) { nodes { login } }
+        labels(first: ` + strconv.Itoa(gitHubGraphQLLabelsLimit) + `) { nodes { name color } }
+        repository { name owner { login } }
+      }
+    }
+  }
+}`
+
+type gitHubGraphQLActor struct {
+	Login      string `json:"login"`
+	DatabaseID int64  `json:"databaseId"`
+}
+
+type gitHubGraphQLSearchNode struct {
+	TypeName   string              `json:"__typename"`
+	Number     int                 `json:"number"`
+	Title      string              `json:"title"`
+	Body       string              `json:"body"`
+	State      string              `json:"state"`
+	Merged     bool                `json:"merged"`
+	CreatedAt  *time.Time          `json:"createdAt"`
+	UpdatedAt  *time.Time          `json:"updatedAt"`
+	MergedAt   *time.Time          `json:"mergedAt"`
+	ClosedAt   *time.Time          `json:"closedAt"`
+	URL        string              `json:"url"`
+	Author     *gitHubGraphQLActor `json:"author"`
+	Repository struct {
+		Name  string `json:"name"`
+		Owner struct {
+			Login string `json:"login"`
+		} `json:"owner"`
+	} `json:"repository"`
+	Commits struct {
+		Nodes []struct {
+			Commit struct {
+				StatusCheckRollup *struct {
+					State string `json:"state"`
+				} `json:"statusCheckRollup"`
+			} `json:"commit"`
+		} `json:"nodes"`
+	} `json:"commits"`
+	ReviewThreads struct {
+		PageInfo graphQLPageInfo `json:"pageInfo"`
+		Nodes    []struct {
+			Comments struct {
+				PageInfo graphQLPageInfo `json:"pageInfo"`
+				Nodes    []struct {
+					DatabaseID int64               `json:"databaseId"`
+					Body       string              `json:"body"`
+					Author     *gitHubGraphQLActor `json:"author"`
+				} `json:"nodes"`
+			} `json:"comments"`
+		} `json:"nodes"`
+	} `json:"reviewThreads"`
+	Assignees struct {
+		Nodes []*gitHubGraphQLActor `json:"nodes"`
+	} `json:"assignees"`
+	HeadRefName string `json:"headRefName"`
+	BaseRefName string `json:"baseRefName"`
+	// ReviewRequests are the pending review requests; team requests have no
+	// login and are skipped.
+	ReviewRequests struct {
+		Nodes []struct {
+			RequestedReviewer *gitHubGraphQLActor `json:"requestedReviewer"`
+		} `json:"nodes"`
+	} `json:"reviewRequests"`
+	Labels struct {
+		Nodes []struct {
+			Name  string `json:"name"`
+			Color string `json:"color"`
+		} `json:"nodes"`
+	} `json:"labels"`
+}
+
+type gitHubSearchResponse struct {
+	graphQLErrors
+	Data struct {
+		Search struct {
+			PageInfo graphQLPageInfo           `json:"pageInfo"`
+			Nodes    []gitHubGraphQLSearchNode `json:"nodes"`
+		} `json:"search"`
+	} `json:"data"`
+}
+
+// searchGitHubGraphQL runs a search query page by page and returns the results
+// as search items, with each pull request's and issue's details and review
+// comments as prefetched data.
+func searchGitHubGraphQL(ctx context.Context, client *github.Client, query string) ([]*github.Issue, *gitHubPrefetched, error) {
+	prefetched := &gitHubPrefetched{
+		pullRequests:   make(map[string]*github.PullRequest),
+		issues:         make(map[string]*github.Issue),
+		reviewComments: make(map[string][]*github.PullRequestComment),
+		checkStatuses:  make(map[string]string),
+	}
+
+	items := make([]*github.Issue, 0)
+	for cursor := ""; ; {
+		variables := map[string]any{"query": query}
+		if cursor != "" {
+			variables["after"] = cursor
+		}
+		var response gitHubSearchResponse
+		if err := doGitHubGraphQL(ctx, client, gitHubSearchQuery, variables, &response); err != nil {
+			return nil, nil, err
+		}
+
+		connection := response.Data.Search
+		for _, node := range connection.Nodes {
+			owner, repo := node.Repository.Owner.Login, node.Repository.Name
+			if owner == "" || repo == "" {
+				continue
+			}
+			key := buildGitHubItemKey(owner, repo, node.Number)
+			item := &github.Issue{Number: github.Int(node.Number), HTMLURL: github.String(node.URL)}
+
+			switch node.TypeName {
+			case "PullRequest":
+				item.PullRequestLinks = &github.PullRequestLinks{HTMLURL: github.String(node.URL)}
+				prefetched.pullRequests[key] = node.toPullRequest()
+				prefetched.checkStatuses[key] = node.checkStatus()
+				if comments, ok := node.toReviewComments(); ok {
+					prefetched.reviewComments[key] = comments
+				}
+			case "Issue":
+				prefetched.issues[key] = node.toIssue()
+			default:
+				continue
+			}
+			items = append(items, item)
+		}
+		if !connection.PageInfo.HasNextPage {
+			break
+		}
+		cursor = connection.PageInfo.EndCursor
+	}
+
+	return items, prefetched, nil
+}
+
+// doGitHubGraphQL posts one query to the GraphQL endpoint next to the REST
+// base URL (/graphql on api.github.com, /api/graphql on GitHub Enterprise)
+// through retryWithBackoff, which waits out rate limits as GitHub asks.
+// Errors in the response body are returned as they are, without retrying.
+func doGitHubGraphQL(ctx context.Context, client *github.Client, query string, variables map[string]any, response interface{ err() error }) error {
+	endpoint := "graphql"
+	if strings.HasSuffix(client.BaseURL.Path, "/api/v3/") {
+		endpoint = strings.TrimSuffix(client.BaseURL.Path, "v3/") + "graphql"
+	}
+
+	err := retryWithBackoff(func() error {
+		// A request's body is consumed by sending it, so each attempt
+		// builds its own.
+		req, err := client.NewRequest(http.MethodPost, endpoint, map[string]any{"query": query, "variables": variables})
+		if err != nil {
+			return err
+		}
+		_, err = client.Do(ctx, req, response)
+		return err
+	}, "GitHubGraphQLSearch")
+	if err != nil {
+		return fmt.Errorf("GitHub GraphQL search: %w", err)
+	}
+	return response.err()
+}
+
+func (a *gitHubGraphQLActor) toUser() *github.User {
+	if a == nil {
+		return nil
+	}
+	return &github.User{Login: github.String(a.Login), ID: github.Int64(a.DatabaseID)}
+}
+
+func (n gitHubGraphQLSearchNode) toPullRequest() *github.PullRequest {
+	// GraphQL reports merged pull requests as MERGED; REST calls them closed
+	// and sets merged.
+	state := strings.ToLower(n.State)
+	if state == "merged" {
+		state = "closed"
+	}
+	pr := &github.PullRequest{
+		Number:  github.Int(n.Number),
+		Title:   github.String(n.Title),
+		Body:    github.String(n.Body),
+		State:   github.String(state),
+		Merged:  github.Bool(n.Merged),
+		HTMLURL: github.String(n.URL),
+		User:    n.Author.toUser(),
+	}
+	for _, assignee := range n.Assignees.Nodes {
+		pr.Assignees = append(pr.Assignees, assignee.toUser())
+	}
+	if n.HeadRefName != "" {
+		pr.Head = &github.PullRequestBranch{Ref: github.String(n.HeadRefName)}
+	}
+	if n.BaseRefName != "" {
+		pr.Base = &github.PullRequestBranch{Ref: github.String(n.BaseRefName)}
+	}
+	pr.Labels = n.toLabels()
+	for _, request := range n.ReviewRequests.Nodes {
+		if request.RequestedReviewer != nil && request.RequestedReviewer.Login != "" {
+			pr.RequestedReviewers = append(pr.RequestedReviewers, request.RequestedReviewer.toUser())
+		}
+	}
+	if n.CreatedAt != nil {
+		pr.CreatedAt = &github.Timestamp{Time: *n.CreatedAt}
+	}
+	if n.UpdatedAt != nil {
+		pr.UpdatedAt = &github.Timestamp{Time: *n.UpdatedAt}
+	}
+	if n.MergedAt != nil {
+		pr.MergedAt = &github.Timestamp{Time: *n.MergedAt}
+	}
+	if n.ClosedAt != nil {
+		pr.ClosedAt = &github.Timestamp{Time: *n.ClosedAt}
+	}
+	return pr
+}
+
+// checkStatus maps the head commit's status check rollup, which covers both
+// check runs and commit statuses, to the REST combination's values.
+func (n gitHubGraphQLSearchNode) checkStatus() string {
+	if len(n.Commits.Nodes) == 0 || n.Commits.Nodes[0].Commit.StatusCheckRollup == nil {
+		return ""
+	}
+	switch n.Commits.Nodes[0].Commit.StatusCheckRollup.State {
+	case "SUCCESS":
+		return checkStatusSuccess
+	case "FAILURE", "ERROR":
+		return checkStatusFailure
+	case "PENDING", "EXPECTED":
+		return checkStatusPending
+	}
+	return ""
+}
+
+// toReviewComments flattens the review threads into review comments. It
+// reports false when a thread or comment page was cut off by the limits.
+func (n gitHubGraphQLSearchNode) toReviewComments() ([]*github.PullRequestComment, bool) {
+	if n.ReviewThreads.PageInfo.HasNextPage {
+		return nil, false
+	}
+	comments := make([]*github.PullRequestComment, 0)
+	for _, thread := range n.ReviewThreads.Nodes {
+		if thread.Comments.PageInfo.HasNextPage {
+			return nil, false
+		}
+		for _, node := range thread.Comments.Nodes {
+			comments = append(comments, &github.PullRequestComment{
+				ID:   github.Int64(node.DatabaseID),
+				Body: github.String(node.Body),
+				User: node.Author.toUser(),
+			})
+		}
+	}
+	return comments, true
+}
+
+func (n gitHubGraphQLSearchNode) toLabels() []*github.Label {
+	var labels []*github.Label
+	for _, label := range n.Labels.Nodes {
+		labels = append(labels, &github.Label{Name: github.String(label.Name), Color: github.String(label.Color)})
+	}
+	return labels
+}
+
+func (n gitHubGraphQLSearchNode) toIssue() *github.Issue {
+	issue := &github.Issue{
+		Number:  github.Int(n.Number),
+		Title:   github.String(n.Title),
+		Body:    github.String(n.Body),
+		State:   github.String(strings.ToLower(n.State)),
+		HTMLURL: github.String(n.URL),
+		User:    n.Author.toUser(),
+	}
+	for _, assignee := range n.Assignees.Nodes {
+		issue.Assignees = append(issue.Assignees, assignee.toUser())
+	}
+	issue.Labels = n.toLabels()
+	if n.CreatedAt != nil {
+		issue.CreatedAt = &github.Timestamp{Time: *n.CreatedAt}
+	}
+	if n.UpdatedAt != nil {
+		issue.UpdatedAt = &github.Timestamp{Time: *n.UpdatedAt}
+	}
+	if n.ClosedAt != nil {
+		issue.ClosedAt = &github.Timestamp{Time: *n.ClosedAt}
+	}
+	return issue
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// gitHubNotificationLabels maps the notification reasons that seed the feed
+// with --github-source notifications to labels. Notifications for any other
+// reason (subscribed, ci_activity, ...) are skipped.
+var gitHubNotificationLabels = map[string]string{
+	"review_requested": "Review Requested",
+	"assign":           "Assigned",
+	"mention":          "Mentioned",
+	"team_mention":     "Mentioned",
+}
+
+// collectGitHubNotificationResults seeds the feed from the notifications
+// updated since cutoff instead of the search queries. The subjects are then
+// hydrated and labeled like search results.
+func collectGitHubNotificationResults(
+	ctx context.Context,
+	client *github.Client,
+	cutoff time.Time,
+) ([]PRActivity, map[string][]GitHubPRReviewCommentRecord, []IssueActivity, error) {
+	notifications, err := listGitHubNotifications(ctx, client, cutoff)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	prBatches, issueBatches := groupGitHubNotifications(notifications)
+
+	prActivities, prReviewComments, err := collectGitHubPullRequests(ctx, client, prBatches, cutoff)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	issueActivities, err := collectGitHubIssues(ctx, client, issueBatches, cutoff)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return prActivities, prReviewComments, issueActivities, nil
+}
+
+// listGitHubNotifications lists read and unread notifications, since the feed
+// covers a time range rather than an inbox.
+func listGitHubNotifications(ctx context.Context, client *github.Client, cutoff time.Time) ([]*github.Notification, error) {
+	allNotifications := make([]*github.Notification, 0)
+	options := &github.NotificationListOptions{All: true, Since: cutoff, ListOptions: github.ListOptions{PerPage: 50, Page: 1}}
+
+	for {
+		notifications, resp, err := client.Activity.ListNotifications(ctx, options)
+		if err != nil {
+			return nil, fmt.Errorf("list notifications: %w", err)
+		}
+		allNotifications = append(allNotifications, notifications...)
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		options.Page = resp.NextPage
+	}
+
+	return allNotifications, nil
+}
+
+// groupGitHubNotifications turns notifications into per-label batches of
+// search-style items, split into pull requests and issues.
+func groupGitHubNotifications(notifications []*github.Notification) ([]gitHubLabeledItems, []gitHubLabeledItems) {
+	prItems := make(map[string][]*github.Issue)
+	issueItems := make(map[string][]*github.Issue)
+	for _, notification := range notifications {
+		label, ok := gitHubNotificationLabels[notification.GetReason()]
+		if !ok {
+			continue
+		}
+		item, ok := gitHubNotificationItem(notification)
+		if !ok {
+			continue
+		}
+		if item.IsPullRequest() {
+			prItems[label] = append(prItems[label], item)
+		} else {
+			issueItems[label] = append(issueItems[label], item)
+		}
+	}
+
+	var prBatches, issueBatches []gitHubLabeledItems
+	for _, label := range []string{"Review Requested", "Assigned", "Mentioned"} {
+		if items := prItems[label]; len(items) > 0 {
+			prBatches = append(prBatches, gitHubLabeledItems{Label: label, Items: items})
+		}
+		if items := issueItems[label]; len(items) > 0 {
+			issueBatches = append(issueBatches, gitHubLabeledItems{Label: label, Items: items})
+		}
+	}
+	return prBatches, issueBatches
+}
+
+// gitHubNotificationItem builds a search item for a pull request or issue
+// notification. The subject's API URL ends in the item number.
+func gitHubNotificationItem(notification *github.Notification) (*github.Issue, bool) {
+	subject := notification.GetSubject()
+	if subject == nil {
+		return nil, false
+	}
+	number, err := strconv.Atoi(path.Base(subject.GetURL()))
+	if err != nil {
+		return nil, false
+	}
+
+	// parseGitHubRepoFromSearchItem falls back to the owner/repo prefix of the
+	// HTML URL, which the repository's own URL provides.
+	item := &github.Issue{
+		Number:  github.Int(number),
+		HTMLURL: github.String(notification.GetRepository().GetHTMLURL()),
+	}
+	switch subject.GetType() {
+	case "PullRequest":
+		item.PullRequestLinks = &github.PullRequestLinks{URL: github.String(subject.GetURL())}
+	case "Issue":
+	default:
+		return nil, false
+	}
+	return item, true
+}
@@ -0,0 +1,476 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// gitLabPrefetched holds the approvals and notes the GraphQL backend fetched
+// together with the items. A nil value (REST) or a missing entry means the
+// data has to be fetched per item.
+type gitLabPrefetched struct {
+	approvals       map[int64]*gitlab.MergeRequestApprovalState
+	mrNotesByIID    map[int64][]*gitlab.Note
+	issueNotesByIID map[int64][]*gitlab.Note
+}
+
+func (p *gitLabPrefetched) approvalState(iid int64) (*gitlab.MergeRequestApprovalState, bool) {
+	if p == nil {
+		return nil, false
+	}
+	state, ok := p.approvals[iid]
+	return state, ok
+}
+
+func (p *gitLabPrefetched) mergeRequestNotes(iid int64) ([]*gitlab.Note, bool) {
+	if p == nil {
+		return nil, false
+	}
+	notes, ok := p.mrNotesByIID[iid]
+	return notes, ok
+}
+
+func (p *gitLabPrefetched) issueNotes(iid int64) ([]*gitlab.Note, bool) {
+	if p == nil {
+		return nil, false
+	}
+	notes, ok := p.issueNotesByIID[iid]
+	return notes, ok
+}
+
+const gitLabGraphQLPageSize = 50
+
+// Items with more notes than gitLabGraphQLNotesLimit get theirs from REST.
+const gitLabGraphQLNotesLimit = 100
+
+const gitLabUserFields = `id username`
+
+// gitLabPremiumMergeRequestFields and gitLabPremiumIssueFields only exist on
+// GitLab Premium and Ultimate; Free instances answer "Field ... doesn't exist"
+// to queries that ask for them.
+const gitLabPremiumMergeRequestFields = `
+        approvalState {
+          rules {
+            approved
+            approvedBy { nodes { ` + gitLabUserFields + ` } }
+            eligibleApprovers { ` + gitLabUserFields + ` }
+          }
+        }`
+
+const gitLabPremiumIssueFields = ` weight`
+
+// gitLabMergeRequestsQuery asks for approvalState only with premium set.
+func gitLabMergeRequestsQuery(premium bool) string {
+	premiumFields := ""
+	if premium {
+		premiumFields = gitLabPremiumMergeRequestFields
+	}
+	return `query($fullPath: ID!, $updatedAfter: Time, $after: String) {
+  project(fullPath: $fullPath) {
+    mergeRequests(updatedAfter: $updatedAfter, sort: UPDATED_DESC, first: ` + strconv.Itoa(gitLabGraphQLPageSize) + `, after: $after) {
+      pageInfo { hasNextPage endCursor }
+      nodes {
+        iid title description state createdAt updatedAt mergedAt closedAt webUrl
+        squash squashOnMerge sourceProjectId targetProjectId sourceBranch targetBranch
+        author { ` + gitLabUserFields + ` }
+        assignees { nodes { ` + gitLabUserFields + ` } }
+        reviewers { nodes { ` + gitLabUserFields + ` } }
+        labels { nodes { title color } }` + premiumFields + `
+        notes(first: ` + strconv.Itoa(gitLabGraphQLNotesLimit) + `) {
+          pageInfo { hasNextPage }
+          nodes { id body system createdAt updatedAt author { ` + gitLabUserFields + ` name } }
+        }
+      }
+    }
+  }
+}`
+}
+
+// gitLabIssuesQuery asks for weight only with premium set.
+func gitLabIssuesQuery(premium bool) string {
+	premiumFields := ""
+	if premium {
+		premiumFields = gitLabPremiumIssueFields
+	}
+	return `query($fullPath: ID!, $updatedAfter: Time, $after: String) {
+  project(fullPath: $fullPath) {
+    issues(updatedAfter: $updatedAfter, sort: UPDATED_DESC, first: ` + strconv.Itoa(gitLabGraphQLPageSize) + `, after: $after) {
+      pageInfo { hasNextPage endCursor }
+      nodes {
+        iid title description state createdAt updatedAt closedAt webUrl dueDate` + premiumFields + `
+        author { ` + gitLabUserFields + ` }
+        assignees { nodes { ` + gitLabUserFields + ` } }
+        labels { nodes { title color } }
+        notes(first: ` + strconv.Itoa(gitLabGraphQLNotesLimit) + `) {
+          pageInfo { hasNextPage }
+          nodes { id body system createdAt updatedAt author { ` + gitLabUserFields + ` name } }
+        }
+      }
+    }
+  }
+}`
+}
+
+// isGitLabGraphQLFieldMissing reports whether a query failed because the
+// instance does not know one of its fields, which is how a Free instance
+// rejects the Premium fields.
+func isGitLabGraphQLFieldMissing(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "doesn't exist on type")
+}
+
+// useGitLabFreeGraphQL makes this and later queries leave out the Premium
+// fields once the instance rejected them.
+func useGitLabFreeGraphQL(err error) {
+	if !config.gitlabFreeGraphQL.Swap(true) && config.debugMode {
+		fmt.Printf("  [GitLab] GraphQL: %v; querying without approvalState and weight (approvals come from REST)\n", err)
+	}
+}
+
+type graphQLUser struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Name     string `json:"name"`
+}
+
+type graphQLUserConnection struct {
+	Nodes []graphQLUser `json:"nodes"`
+}
+
+type graphQLPageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+type graphQLNotes struct {
+	PageInfo graphQLPageInfo `json:"pageInfo"`
+	Nodes    []struct {
+		ID        string       `json:"id"`
+		Body      string       `json:"body"`
+		System    bool         `json:"system"`
+		CreatedAt *time.Time   `json:"createdAt"`
+		UpdatedAt *time.Time   `json:"updatedAt"`
+		Author    *graphQLUser `json:"author"`
+	} `json:"nodes"`
+}
+
+type graphQLLabels struct {
+	Nodes []struct {
+		Title string `json:"title"`
+		Color string `json:"color"`
+	} `json:"nodes"`
+}
+
+// toLabels splits the labels into the names and details REST returns.
+func (c graphQLLabels) toLabels() (gitlab.Labels, []*gitlab.LabelDetails) {
+	var names gitlab.Labels
+	var details []*gitlab.LabelDetails
+	for _, label := range c.Nodes {
+		names = append(names, label.Title)
+		details = append(details, &gitlab.LabelDetails{Name: label.Title, Color: label.Color})
+	}
+	return names, details
+}
+
+type graphQLMergeRequest struct {
+	IID             string                `json:"iid"`
+	Title           string                `json:"title"`
+	Description     string                `json:"description"`
+	State           string                `json:"state"`
+	CreatedAt       *time.Time            `json:"createdAt"`
+	UpdatedAt       *time.Time            `json:"updatedAt"`
+	MergedAt        *time.Time            `json:"mergedAt"`
+	ClosedAt        *time.Time            `json:"closedAt"`
+	WebURL          string                `json:"webUrl"`
+	Squash          bool                  `json:"squash"`
+	SquashOnMerge   bool                  `json:"squashOnMerge"`
+	SourceProjectID int64                 `json:"sourceProjectId"`
+	TargetProjectID int64                 `json:"targetProjectId"`
+	SourceBranch    string                `json:"sourceBranch"`
+	TargetBranch    string                `json:"targetBranch"`
+	Author          *graphQLUser          `json:"author"`
+	Assignees       graphQLUserConnection `json:"assignees"`
+	Reviewers       graphQLUserConnection `json:"reviewers"`
+	Labels          graphQLLabels         `json:"labels"`
+	ApprovalState   *struct {
+		Rules []struct {
+			Approved          bool                  `json:"approved"`
+			ApprovedBy        graphQLUserConnection `json:"approvedBy"`
+			EligibleApprovers []graphQLUser         `json:"eligibleApprovers"`
+		} `json:"rules"`
+	} `json:"approvalState"`
+	Notes graphQLNotes `json:"notes"`
+}
+
+type graphQLIssue struct {
+	IID         string                `json:"iid"`
+	Title       string                `json:"title"`
+	Description string                `json:"description"`
+	State       string                `json:"state"`
+	CreatedAt   *time.Time            `json:"createdAt"`
+	UpdatedAt   *time.Time            `json:"updatedAt"`
+	ClosedAt    *time.Time            `json:"closedAt"`
+	WebURL      string                `json:"webUrl"`
+	DueDate     *time.Time            `json:"dueDate"`
+	Weight      *int64                `json:"weight"`
+	Author      *graphQLUser          `json:"author"`
+	Assignees   graphQLUserConnection `json:"assignees"`
+	Labels      graphQLLabels         `json:"labels"`
+	Notes       graphQLNotes          `json:"notes"`
+}
+
+// graphQLErrors is embedded in every response; GraphQL reports query errors in
+// the body of a 200 response.
+type graphQLErrors struct {
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func (e *graphQLErrors) err() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	messages := make([]string, 0, len(e.Errors))
+	for _, graphQLErr := range e.Errors {
+		messages = append(messages, graphQLErr.Message)
+	}
+	return fmt.Errorf("GraphQL error: %s", strings.Join(messages, "; "))
+}
+
+type gitLabMergeRequestsResponse struct {
+	graphQLErrors
+	Data struct {
+		Project *struct {
+			MergeRequests struct {
+				PageInfo graphQLPageInfo       `json:"pageInfo"`
+				Nodes    []graphQLMergeRequest `json:"nodes"`
+			} `json:"mergeRequests"`
+		} `json:"project"`
+	} `json:"data"`
+}
+
+type gitLabIssuesResponse struct {
+	graphQLErrors
+	Data struct {
+		Project *struct {
+			Issues struct {
+				PageInfo graphQLPageInfo `json:"pageInfo"`
+				Nodes    []graphQLIssue  `json:"nodes"`
+			} `json:"issues"`
+		} `json:"project"`
+	} `json:"data"`
+}
+
+// fetchGitLabProjectGraphQL retrieves a project's merge requests and issues
+// with their reviewers, approvals and first notes in one paginated query each,
+// converted to the REST types the rest of the fetch path works with.
+func fetchGitLabProjectGraphQL(ctx context.Context, client *gitlab.Client, projectPath string, cutoff time.Time) ([]*gitlab.BasicMergeRequest, []*gitlab.Issue, *gitLabPrefetched, error) {
+	prefetched := &gitLabPrefetched{
+		approvals:       make(map[int64]*gitlab.MergeRequestApprovalState),
+		mrNotesByIID:    make(map[int64][]*gitlab.Note),
+		issueNotesByIID: make(map[int64][]*gitlab.Note),
+	}
+	variables := map[string]any{"fullPath": projectPath, "updatedAfter": cutoff.UTC().Format(time.RFC3339)}
+
+	// Without approvalState (GitLab Free) nothing lands in prefetched.approvals
+	// and deriveGitLabMergeRequestLabel asks REST for each merge request's.
+	mergeRequests := make([]*gitlab.BasicMergeRequest, 0)
+	for cursor := ""; ; {
+		premium := !config.gitlabFreeGraphQL.Load()
+		var response gitLabMergeRequestsResponse
+		err := doGitLabGraphQL(ctx, client, gitLabMergeRequestsQuery(premium), variables, cursor, &response,
+			fmt.Sprintf("GitLabGraphQLMergeRequests %s", projectPath))
+		if premium && isGitLabGraphQLFieldMissing(err) {
+			useGitLabFreeGraphQL(err)
+			continue
+		}
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if response.Data.Project == nil {
+			return nil, nil, nil, fmt.Errorf("project %s not found", projectPath)
+		}
+		connection := response.Data.Project.MergeRequests
+		for _, node := range connection.Nodes {
+			item := node.toBasicMergeRequest()
+			mergeRequests = append(mergeRequests, item)
+			if premium {
+				prefetched.approvals[item.IID] = node.toApprovalState()
+			}
+			if !node.Notes.PageInfo.HasNextPage {
+				prefetched.mrNotesByIID[item.IID] = node.Notes.toNotes()
+			}
+		}
+		if !connection.PageInfo.HasNextPage || reachedMaxItems(len(mergeRequests)) {
+			break
+		}
+		cursor = connection.PageInfo.EndCursor
+	}
+
+	issues := make([]*gitlab.Issue, 0)
+	for cursor := ""; ; {
+		premium := !config.gitlabFreeGraphQL.Load()
+		var response gitLabIssuesResponse
+		err := doGitLabGraphQL(ctx, client, gitLabIssuesQuery(premium), variables, cursor, &response,
+			fmt.Sprintf("GitLabGraphQLIssues %s", projectPath))
+		if premium && isGitLabGraphQLFieldMissing(err) {
+			useGitLabFreeGraphQL(err)
+			continue
+		}
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if response.Data.Project == nil {
+			return nil, nil, nil, fmt.Errorf("project %s not found", projectPath)
+		}
+		connection := response.Data.Project.Issues
+		for _, node := range connection.Nodes {
+			item := node.toIssue()
+			issues = append(issues, item)
+			if !node.Notes.PageInfo.HasNextPage {
+				prefetched.issueNotesByIID[item.IID] = node.Notes.toNotes()
+			}
+		}
+		if !connection.PageInfo.HasNextPage || reachedMaxItems(len(issues)) {
+			break
+		}
+		cursor = connection.PageInfo.EndCursor
+	}
+
+	return capGitLabItems(mergeRequests), capGitLabItems(issues), prefetched, nil
+}
+
+// doGitLabGraphQL runs one query page through retryWithBackoff. Errors in the
+// response body are returned as they are, without retrying.
+func doGitLabGraphQL(ctx context.Context, client *gitlab.Client, query string, variables map[string]any, cursor string, response interface{ err() error }, operationName string) error {
+	pageVariables := make(map[string]any, len(variables)+1)
+	for key, value := range variables {
+		pageVariables[key] = value
+	}
+	if cursor != "" {
+		pageVariables["after"] = cursor
+	}
+
+	err := retryWithBackoff(func() error {
+		_, apiErr := client.GraphQL.Do(gitlab.GraphQLQuery{Query: query, Variables: pageVariables}, response, gitlab.WithContext(ctx))
+		return apiErr
+	}, operationName)
+	if err != nil {
+		return err
+	}
+	return response.err()
+}
+
+// graphQLNumericID extracts 42 from a global ID such as gid://gitlab/User/42.
+func graphQLNumericID(globalID string) int64 {
+	id, _ := strconv.ParseInt(globalID[strings.LastIndex(globalID, "/")+1:], 10, 64)
+	return id
+}
+
+func (u *graphQLUser) toBasicUser() *gitlab.BasicUser {
+	if u == nil {
+		return nil
+	}
+	return &gitlab.BasicUser{ID: graphQLNumericID(u.ID), Username: u.Username}
+}
+
+func (c graphQLUserConnection) toBasicUsers() []*gitlab.BasicUser {
+	users := make([]*gitlab.BasicUser, 0, len(c.Nodes))
+	for i := range c.Nodes {
+		users = append(users, c.Nodes[i].toBasicUser())
+	}
+	return users
+}
+
+func (n graphQLNotes) toNotes() []*gitlab.Note {
+	notes := make([]*gitlab.Note, 0, len(n.Nodes))
+	for _, node := range n.Nodes {
+		note := &gitlab.Note{ID: graphQLNumericID(node.ID), Body: node.Body, System: node.System, CreatedAt: node.CreatedAt, UpdatedAt: node.UpdatedAt}
+		if node.Author != nil {
+			note.Author = gitlab.NoteAuthor{ID: graphQLNumericID(node.Author.ID), Username: node.Author.Username, Name: node.Author.Name}
+		}
+		notes = append(notes, note)
+	}
+	return notes
+}
+
+func (m graphQLMergeRequest) toBasicMergeRequest() *gitlab.BasicMergeRequest {
+	iid, _ := strconv.ParseInt(m.IID, 10, 64)
+	labels, labelDetails := m.Labels.toLabels()
+	return &gitlab.BasicMergeRequest{
+		IID:             iid,
+		ProjectID:       m.TargetProjectID,
+		Title:           m.Title,
+		Description:     m.Description,
+		State:           m.State,
+		CreatedAt:       m.CreatedAt,
+		UpdatedAt:       m.UpdatedAt,
+		MergedAt:        m.MergedAt,
+		ClosedAt:        m.ClosedAt,
+		WebURL:          m.WebURL,
+		Squash:          m.Squash,
+		SquashOnMerge:   m.SquashOnMerge,
+		SourceProjectID: m.SourceProjectID,
+		TargetProjectID: m.TargetProjectID,
+		SourceBranch:    m.SourceBranch,
+		TargetBranch:    m.TargetBranch,
+		Author:          m.Author.toBasicUser(),
+		Assignees:       m.Assignees.toBasicUsers(),
+		Reviewers:       m.Reviewers.toBasicUsers(),
+		Labels:          labels,
+		LabelDetails:    labelDetails,
+	}
+}
+
+func (m graphQLMergeRequest) toApprovalState() *gitlab.MergeRequestApprovalState {
+	state := &gitlab.MergeRequestApprovalState{}
+	if m.ApprovalState == nil {
+		return state
+	}
+	for _, rule := range m.ApprovalState.Rules {
+		eligible := make([]*gitlab.BasicUser, 0, len(rule.EligibleApprovers))
+		for i := range rule.EligibleApprovers {
+			eligible = append(eligible, rule.EligibleApprovers[i].toBasicUser())
+		}
+		state.Rules = append(state.Rules, &gitlab.MergeRequestApprovalRule{
+			Approved:          rule.Approved,
+			ApprovedBy:        rule.ApprovedBy.toBasicUsers(),
+			EligibleApprovers: eligible,
+		})
+	}
+	return state
+}
+
+func (i graphQLIssue) toIssue() *gitlab.Issue {
+	iid, _ := strconv.ParseInt(i.IID, 10, 64)
+	issue := &gitlab.Issue{
+		IID:         iid,
+		Title:       i.Title,
+		Description: i.Description,
+		State:       i.State,
+		CreatedAt:   i.CreatedAt,
+		UpdatedAt:   i.UpdatedAt,
+		ClosedAt:    i.ClosedAt,
+		WebURL:      i.WebURL,
+	}
+	if i.DueDate != nil {
+		dueDate := gitlab.ISOTime(*i.DueDate)
+		issue.DueDate = &dueDate
+	}
+	if i.Weight != nil {
+		issue.Weight = *i.Weight
+	}
+	issue.Labels, issue.LabelDetails = i.Labels.toLabels()
+	if i.Author != nil {
+		issue.Author = &gitlab.IssueAuthor{ID: graphQLNumericID(i.Author.ID), Username: i.Author.Username}
+	}
+	for _, assignee := range i.Assignees.Nodes {
+		issue.Assignees = append(issue.Assignees, &gitlab.IssueAssignee{ID: graphQLNumericID(assignee.ID), Username: assignee.Username})
+	}
+	return issue
+}
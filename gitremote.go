@@ -0,0 +1,95 @@
+package main
+
+import (
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// gitRemote is a parsed remote URL.
+type gitRemote struct {
+	// Host is the hostname, without user info or SSH port.
+	Host string
+	// Path is owner/repo or group[/subgroup]/repo, without ".git".
+	Path string
+}
+
+// originRemote returns the origin remote of the git working tree containing
+// dir. Asking git rather than reading .git/config keeps worktrees, submodules
+// and url.insteadOf rewrites working.
+func originRemote(dir string) (gitRemote, bool) {
+	out, err := exec.Command("git", "-C", dir, "remote", "get-url", "origin").Output()
+	if err != nil {
+		return gitRemote{}, false
+	}
+	return parseGitRemoteURL(strings.TrimSpace(string(out)))
+}
+
+// parseGitRemoteURL understands the URL forms git accepts for remotes:
+// https://host/path, ssh://[user@]host[:port]/path and scp-like
+// [user@]host:path.
+func parseGitRemoteURL(raw string) (gitRemote, bool) {
+	var host, path string
+	if strings.Contains(raw, "://") {
+		parsed, err := url.Parse(raw)
+		if err != nil {
+			return gitRemote{}, false
+		}
+		switch parsed.Scheme {
+		case "https", "http", "ssh", "git":
+		default:
+			return gitRemote{}, false
+		}
+		host, path = parsed.Hostname(), parsed.Path
+	} else {
+		hostPart, pathPart, ok := strings.Cut(raw, ":")
+		if !ok || strings.Contains(hostPart, "/") {
+			return gitRemote{}, false
+		}
+		if _, after, found := strings.Cut(hostPart, "@"); found {
+			hostPart = after
+		}
+		host, path = hostPart, pathPart
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if host == "" || !strings.Contains(path, "/") {
+		return gitRemote{}, false
+	}
+	return gitRemote{Host: strings.ToLower(host), Path: path}, true
+}
+
+// detectRepoScope turns the origin remote into an allowed-repos value for
+// platform. GitHub remotes must point at github.com with an owner/repo path.
+// GitLab remotes must point at the host of GITLAB_HOST/GITLAB_BASE_URL, or at
+// gitlab.com when neither is set. The GitLab host is never taken from the
+// remote: the token belongs to the configured instance, and a checkout's origin
+// can point anywhere.
+func detectRepoScope(platform string, remote gitRemote) (string, bool) {
+	switch platform {
+	case "github":
+		if remote.Host != "github.com" || strings.Count(remote.Path, "/") != 1 {
+			return "", false
+		}
+		return remote.Path, true
+	case "gitlab":
+		if remote.Host == "github.com" {
+			return "", false
+		}
+		configured := strings.TrimSpace(os.Getenv("GITLAB_HOST"))
+		if configured == "" {
+			configured = strings.TrimSpace(os.Getenv("GITLAB_BASE_URL"))
+		}
+		normalized, err := normalizeGitLabBaseURL(configured)
+		if err != nil {
+			return "", false
+		}
+		parsed, err := url.Parse(normalized)
+		if err != nil || !strings.EqualFold(parsed.Hostname(), remote.Host) {
+			return "", false
+		}
+		return remote.Path, true
+	}
+	return "", false
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// glabConfig is the part of the glab CLI config (config.yml) needed to reuse
+// its credentials. glab writes many more keys; they are ignored.
+type glabConfig struct {
+	Host  string              `yaml:"host"`
+	Hosts map[string]glabHost `yaml:"hosts"`
+}
+
+type glabHost struct {
+	Token       string `yaml:"token"`
+	APIHost     string `yaml:"api_host"`
+	APIProtocol string `yaml:"api_protocol"`
+	User        string `yaml:"user"`
+}
+
+type glabCredentials struct {
+	Host    string
+	BaseURL string
+	Token   string
+}
+
+// glabConfigPath follows glab's own lookup: GLAB_CONFIG_DIR, then
+// $XDG_CONFIG_HOME/glab-cli, then ~/.config/glab-cli.
+func glabConfigPath() string {
+	if dir := strings.TrimSpace(os.Getenv("GLAB_CONFIG_DIR")); dir != "" {
+		return filepath.Join(dir, "config.yml")
+	}
+	if dir := strings.TrimSpace(os.Getenv("XDG_CONFIG_HOME")); dir != "" {
+		return filepath.Join(dir, "glab-cli", "config.yml")
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".config", "glab-cli", "config.yml")
+}
+
+// loadGlabCredentials finds the glab token for the GitLab instance at
+// gitlabBaseURL, or for glab's default host when no host was configured here.
+// Tokens glab keeps in its own keyring are not readable and are skipped.
+func loadGlabCredentials(path, gitlabBaseURL string, hostConfigured bool) (glabCredentials, bool) {
+	if path == "" {
+		return glabCredentials{}, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return glabCredentials{}, false
+	}
+	var cfg glabConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return glabCredentials{}, false
+	}
+
+	wantHost := ""
+	if parsed, err := url.Parse(gitlabBaseURL); err == nil {
+		wantHost = parsed.Host
+	}
+	if !hostConfigured && strings.TrimSpace(cfg.Host) != "" {
+		wantHost = strings.TrimSpace(cfg.Host)
+	}
+
+	for name, host := range cfg.Hosts {
+		if !strings.EqualFold(name, wantHost) || strings.TrimSpace(host.Token) == "" {
+			continue
+		}
+		apiHost := strings.TrimSpace(host.APIHost)
+		if apiHost == "" {
+			apiHost = name
+		}
+		protocol := strings.TrimSpace(host.APIProtocol)
+		if protocol == "" {
+			protocol = "https"
+		}
+		baseURL, err := normalizeGitLabBaseURL(protocol + "://" + apiHost)
+		if err != nil {
+			return glabCredentials{}, false
+		}
+		return glabCredentials{Host: name, BaseURL: baseURL, Token: strings.TrimSpace(host.Token)}, true
+	}
+	return glabCredentials{}, false
+}
+
+// offerGlabCredentials asks whether to reuse glab's token. GITLAB_USE_GLAB=true
+// reuses it without asking (for scripts); without a terminal it is never used
+// implicitly.
+func offerGlabCredentials(creds glabCredentials, interactive bool, in *os.File) bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("GITLAB_USE_GLAB"))) {
+	case "1", "true", "yes":
+		return true
+	case "0", "false", "no":
+		return false
+	}
+	if !interactive {
+		return false
+	}
+	return confirmPrompt(in, fmt.Sprintf("No GitLab token is configured. Use the glab CLI token for %s?", creds.Host))
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// httpCacheTTL is how long a cached GET response is served without asking the
+// server, unless its Cache-Control allows less. It only covers rapid repeated
+// runs (a shell prompt, a few runs in a row while tweaking flags); older
+// responses are revalidated with their ETag.
+const httpCacheTTL = 2 * time.Minute
+
+// httpCacheMaxAge is how long an entry is kept for ETag revalidation before
+// pruneHTTPCache removes it.
+const httpCacheMaxAge = 24 * time.Hour
+
+// httpCacheHeader marks responses served from the HTTP cache, so the run log
+// and --debug-http can tell them apart.
+const httpCacheHeader = "X-Git-Feed-Cache"
+
+// httpCacheEntry is a stored response, one JSON file per request, sealed
+// with the cache key when CACHE_ENCRYPTION is on.
+type httpCacheEntry struct {
+	URL      string      `json:"url"`
+	Status   int         `json:"status"`
+	Header   http.Header `json:"header"`
+	Body     []byte      `json:"body"`
+	StoredAt time.Time   `json:"stored_at"`
+}
+
+// httpCacheTransport serves repeated GET requests from responses stored in
+// dir (--http-cache). A response younger than its freshness (see
+// httpCacheFreshness) is returned as is; an older one with an ETag is
+// revalidated, and a 304 reply serves the stored body. Only 200 responses
+// are stored, and none marked no-store. private ones are, since the cache
+// belongs to one user and is keyed by their credentials; GitLab and GitHub
+// mark every API response private. Cache hits never reach the transports
+// below, so they are not counted as API calls.
+type httpCacheTransport struct {
+	next http.RoundTripper
+	dir  string
+	// key encrypts the entries like the rest of the cache (CACHE_ENCRYPTION);
+	// nil stores them in plaintext.
+	key *[32]byte
+}
+
+func (t httpCacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" {
+		return t.next.RoundTrip(req)
+	}
+	path := filepath.Join(t.dir, httpCacheKey(req)+".json")
+	entry, ok := readHTTPCacheEntry(path, t.key)
+	if ok && time.Since(entry.StoredAt) < httpCacheFreshness(entry.Header) {
+		return entry.response(req, "hit"), nil
+	}
+
+	etag := ""
+	if ok {
+		etag = entry.Header.Get("ETag")
+	}
+	if etag != "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", etag)
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	if resp.StatusCode == http.StatusNotModified && etag != "" {
+		resp.Body.Close()
+		entry.StoredAt = time.Now()
+		writeHTTPCacheEntry(path, entry, t.key)
+		return entry.response(req, "revalidated"), nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+	if parseCacheControl(resp.Header.Get("Cache-Control")).noStore {
+		// An entry stored before the server changed its mind must not be
+		// revalidated later either.
+		if ok {
+			os.Remove(path)
+		}
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	writeHTTPCacheEntry(path, httpCacheEntry{
+		URL:      redactURL(req.URL),
+		Status:   resp.StatusCode,
+		Header:   resp.Header.Clone(),
+		Body:     body,
+		StoredAt: time.Now(),
+	}, t.key)
+	return resp, nil
+}
+
+// cacheControl holds the Cache-Control response directives the HTTP cache
+// obeys.
+type cacheControl struct {
+	noStore bool
+	noCache bool
+	// maxAge is -1 without a max-age directive.
+	maxAge time.Duration
+}
+
+func parseCacheControl(value string) cacheControl {
+	directives := cacheControl{maxAge: -1}
+	for _, part := range strings.Split(value, ",") {
+		name, argument, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch strings.ToLower(name) {
+		case "no-store":
+			directives.noStore = true
+		case "no-cache":
+			directives.noCache = true
+		case "max-age":
+			if seconds, err := strconv.Atoi(strings.Trim(argument, `"`)); err == nil && seconds >= 0 {
+				directives.maxAge = time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	return directives
+}
+
+// httpCacheFreshness is how long a stored response is served without asking
+// the server: httpCacheTTL, shortened by its max-age, and nothing with
+// no-cache (such entries are always revalidated).
+func httpCacheFreshness(header http.Header) time.Duration {
+	directives := parseCacheControl(header.Get("Cache-Control"))
+	if directives.noCache {
+		return 0
+	}
+	if directives.maxAge >= 0 {
+		return min(directives.maxAge, httpCacheTTL)
+	}
+	return httpCacheTTL
+}
+
+func (e httpCacheEntry) response(req *http.Request, source string) *http.Response {
+	header := e.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set(httpCacheHeader, source)
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status)),
+		StatusCode:    e.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// httpCacheKey identifies a request by its URL, the representation asked for
+// and the credentials sent, so two tokens (or profiles) never share entries.
+// The credentials are only hashed, never stored.
+func httpCacheKey(req *http.Request) string {
+	hash := sha256.New()
+	for _, part := range []string{
+		req.URL.String(),
+		req.Header.Get("Accept"),
+		req.Header.Get("Authorization"),
+		req.Header.Get("Private-Token"),
+		req.Header.Get("Job-Token"),
+	} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// readHTTPCacheEntry loads the entry at path. One sealed under another key,
+// or without one, is a miss and gets overwritten.
+func readHTTPCacheEntry(path string, key *[32]byte) (httpCacheEntry, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return httpCacheEntry{}, false
+	}
+	if data, err = openCacheValue(key, data); err != nil {
+		return httpCacheEntry{}, false
+	}
+	var entry httpCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return httpCacheEntry{}, false
+	}
+	return entry, true
+}
+
+// writeHTTPCacheEntry stores entry through a temporary file, so concurrent
+// runs never read a half-written one. A failed write only costs the next run
+// a request.
+func writeHTTPCacheEntry(path string, entry httpCacheEntry, key *[32]byte) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if key != nil {
+		if data, err = sealCacheValue(key, data); err != nil {
+			return
+		}
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".entry-*")
+	if err != nil {
+		return
+	}
+	_, writeErr := tmp.Write(data)
+	closeErr := tmp.Close()
+	if writeErr != nil || closeErr != nil || os.Rename(tmp.Name(), path) != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// pruneHTTPCache creates dir and removes the entries not refreshed within
+// maxAge (and temporary files left by interrupted writes).
+func pruneHTTPCache(dir string, maxAge time.Duration) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || entry.IsDir() {
+			continue
+		}
+		if time.Since(info.ModTime()) > maxAge {
+			os.Remove(filepath.Join(dir, entry.Name()))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+const (
+	// twoColumnMinWidth is the narrowest terminal that gets side-by-side open
+	// sections with --two-column; below it most titles would be cut off.
+	twoColumnMinWidth = 160
+	// minTitleWidth is how much of a title survives before project paths
+	// are shortened to make an item fit.
+	minTitleWidth = 24
+)
+
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// terminalWidth prefers COLUMNS (set by most shells and easy to override in
+// monitoring setups) and falls back to asking the terminal; 0 when neither
+// knows.
+func terminalWidth() int {
+	if columns, err := strconv.Atoi(strings.TrimSpace(os.Getenv("COLUMNS"))); err == nil && columns > 0 {
+		return columns
+	}
+	width, _, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+// clearProgressLine blanks the line of the fetch progress bar, across the
+// whole terminal (80 columns when its width is unknown).
+func clearProgressLine() {
+	width := terminalWidth()
+	if width <= 0 {
+		width = 80
+	}
+	fmt.Print("\r" + strings.Repeat(" ", width) + "\r")
+}
+
+// stdoutIsTerminal reports whether stdout is an interactive terminal. When it
+// is redirected, the feed drops emoji and the carriage-return fetch progress;
+// fatih/color already turns colors off on its own in that case.
+func stdoutIsTerminal() bool {
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// itemLineWidth is the width items are fitted to: terminalWidth, or 0 (no
+// limit) with --wide or when terminalWidth knows no width.
+func itemLineWidth(wide bool) int {
+	if wide {
+		return 0
+	}
+	return terminalWidth()
+}
+
+func runeWidth(r rune) int {
+	switch {
+	case r == 0x200d || (r >= 0xfe00 && r <= 0xfe0f):
+		return 0
+	case r >= 0x1100 && r <= 0x115f,
+		r >= 0x2e80 && r <= 0xa4cf,
+		r >= 0xac00 && r <= 0xd7a3,
+		r >= 0xf900 && r <= 0xfaff,
+		r >= 0xff00 && r <= 0xff60,
+		r >= 0x1f300 && r <= 0x1faff:
+		return 2
+	}
+	return 1
+}
+
+// visibleWidth is the number of terminal cells s occupies, ignoring color codes.
+func visibleWidth(s string) int {
+	width := 0
+	for _, r := range ansiEscape.ReplaceAllString(s, "") {
+		width += runeWidth(r)
+	}
+	return width
+}
+
+// truncateVisible cuts s to at most width cells without splitting escape
+// sequences, marking the cut with an ellipsis and resetting any open color.
+func truncateVisible(s string, width int) string {
+	if visibleWidth(s) <= width {
+		return s
+	}
+	if width < visibleWidth(symbols.Ellipsis) {
+		return ""
+	}
+
+	var b strings.Builder
+	used := 0
+	for i := 0; i < len(s); {
+		if loc := ansiEscape.FindStringIndex(s[i:]); loc != nil && loc[0] == 0 {
+			b.WriteString(s[i : i+loc[1]])
+			i += loc[1]
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if used+runeWidth(r) > width-visibleWidth(symbols.Ellipsis) {
+			break
+		}
+		b.WriteRune(r)
+		used += runeWidth(r)
+		i += size
+	}
+	b.WriteString(symbols.Ellipsis)
+	if strings.Contains(s, "\x1b[") {
+		b.WriteString("\x1b[0m")
+	}
+	return b.String()
+}
+
+func padVisible(s string, width int) string {
+	if pad := width - visibleWidth(s); pad > 0 {
+		return s + strings.Repeat(" ", pad)
+	}
+	return s
+}
+
+// sideBySide lays two blocks of lines out as columns within totalWidth cells.
+// Lines that do not fit their column are truncated rather than wrapped so the
+// rows stay aligned.
+func sideBySide(left, right []string, totalWidth int) []string {
+	columnWidth := sideBySideColumnWidth(totalWidth)
+	rows := max(len(left), len(right))
+
+	lines := make([]string, 0, rows)
+	for i := 0; i < rows; i++ {
+		var l, r string
+		if i < len(left) {
+			l = truncateVisible(left[i], columnWidth)
+		}
+		if i < len(right) {
+			r = truncateVisible(right[i], columnWidth)
+		}
+		lines = append(lines, strings.TrimRight(padVisible(l, columnWidth)+columnGap()+r, " "))
+	}
+	return lines
+}
+
+func sideBySideColumnWidth(totalWidth int) int {
+	return (totalWidth - visibleWidth(columnGap())) / 2
+}
+
+func columnGap() string {
+	return "  " + symbols.Rule + "  "
+}
+
+// fitItemLine shortens an item's title, then its project path, so that both
+// plus fixed cells of surrounding text fit in width. Titles keep at least
+// minTitleWidth cells before the path is touched; anything still too long is
+// left for the caller to cut.
+func fitItemLine(fixed int, path, title string, width int) (string, string) {
+	overflow := fixed + visibleWidth(path) + visibleWidth(title) - width
+	if overflow <= 0 {
+		return path, title
+	}
+
+	if titleWidth := visibleWidth(title); titleWidth > minTitleWidth {
+		cut := min(overflow, titleWidth-minTitleWidth)
+		title = truncateVisible(title, titleWidth-cut)
+		overflow -= cut
+	}
+	if overflow > 0 {
+		path = shortenPath(path, visibleWidth(path)-overflow)
+	}
+	return path, title
+}
+
+// shortenPath collapses middle group segments ("acme/platform/infra#12" ->
+// "acme/…/infra#12") until the path fits, keeping the top-level group and the
+// project, which identify it best.
+func shortenPath(path string, width int) string {
+	segments := strings.Split(path, "/")
+	if len(segments) <= 2 || visibleWidth(path) <= width {
+		return path
+	}
+
+	shortened := path
+	for drop := 1; drop <= len(segments)-2; drop++ {
+		shortened = segments[0] + "/" + symbols.Ellipsis + "/" + strings.Join(segments[1+drop:], "/")
+		if visibleWidth(shortened) <= width {
+			break
+		}
+	}
+	return shortened
+}
@@ -3,18 +3,28 @@ package main
 import (
 	"bufio"
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"hash/fnv"
+	"io"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
+	_ "time/tzdata"
 
 	"github.com/fatih/color"
 	gitlab "gitlab.com/gitlab-org/api/client-go"
+	"golang.org/x/term"
 )
 
 type PRActivity struct {
@@ -41,10 +51,27 @@ type MergeRequestModel struct {
 	Title     string
 	Body      string
 	State     string
+	CreatedAt time.Time
 	UpdatedAt time.Time
 	WebURL    string
 	UserLogin string
 	Merged    bool
+	// MergedAt and ClosedAt are zero for open items and cache entries
+	// written before they were recorded.
+	MergedAt      time.Time
+	ClosedAt      time.Time
+	SourceProject string
+	Squash        bool
+	MergeMethod   string
+	CheckStatus   string
+	Assignees     []string
+	Reviewers     []string
+	SourceBranch  string
+	TargetBranch  string
+	ProjectLabels []ProjectLabel
+	// InvolvedAt is when the current user was last asked to review or
+	// assigned, read from GitLab's system notes; zero when unknown.
+	InvolvedAt time.Time
 }
 
 type IssueModel struct {
@@ -52,9 +79,29 @@ type IssueModel struct {
 	Title     string
 	Body      string
 	State     string
+	CreatedAt time.Time
 	UpdatedAt time.Time
+	// ClosedAt is zero for open issues and cache entries written before it
+	// was recorded.
+	ClosedAt  time.Time
 	WebURL    string
 	UserLogin string
+	DueDate   time.Time
+	Weight    int
+	Assignees []string
+
+	ProjectLabels []ProjectLabel
+	// InvolvedAt is when the current user was last assigned, read from
+	// GitLab's system notes; zero when unknown.
+	InvolvedAt time.Time
+}
+
+// ProjectLabel is a label attached to an item in the forge (GitLab labels),
+// as opposed to the involvement label the feed derives.
+type ProjectLabel struct {
+	Name string
+	// Color is the label's "#rrggbb" color; empty when unknown.
+	Color string
 }
 
 type CommentModel struct {
@@ -73,23 +120,88 @@ type Config struct {
 	githubToken    string
 	githubUsername string
 	showLinks      bool
+	showBranches   bool
 	timeRange      time.Duration
 	gitlabUsername string
+	userAliases    map[string]bool
 	allowedRepos   map[string]bool
+	excludedRepos  map[string]bool
 	gitlabClient   *gitlab.Client
-	db             *Database
+	db             Database
 	progress       *Progress
 	ctx            context.Context
 	dbErrorCount   atomic.Int32
+	apiErrorCount  atomic.Int32
+	fetchCounters  fetchCounters
+	groupBy        string
+	projectBadges  map[string][]string
+	states         map[string]bool
+	countOnly      bool
+	quiet          bool
+	interactive    bool
+	platform       string
+	slaTargets     map[string]time.Duration
+	dueSoon        time.Duration
+	minWeight      int
+	location       *time.Location
+	since          time.Time
+	until          time.Time
+	twoColumn      bool
+	noRecency      bool
+	theme          colorTheme
+	lineWidth      int
+	repoOptions    map[string]repoOptions
+	apiBackend     string
+	githubSource   string
+	transport      *http.Transport
+	numberedItems  []numberedItem
+	stream         bool
+	streamedItems  int
+	gitlabNotes    *gitLabNoteCache
+	failedProjects []projectFailure
+	rateLimit      rateLimitBudget
+	apiCalls       apiCallCounter
+	debugHTTP      bool
+	httpCacheDir   string
+	cacheKey       *[32]byte
+	perPage        int
+	maxItems       int
+	maxAPICalls    int
+	// chunkedListings is set for windows longer than gitLabChunkedWindow
+	// (e.g. --time 1y), whose GitLab listings go month by month.
+	chunkedListings bool
+	// gitlabFreeGraphQL is set once the instance rejected the Premium-only
+	// GraphQL fields (see gitLabMergeRequestsQuery).
+	gitlabFreeGraphQL atomic.Bool
 }
 
 var config Config
 
+// maxPerPage is the largest page size GitLab and GitHub accept; it is also
+// the default for --per-page.
+const maxPerPage = 100
+
+// pageSize is the number of items REST listings ask for per page (--per-page).
+func pageSize() int {
+	if config.perPage > 0 {
+		return config.perPage
+	}
+	return maxPerPage
+}
+
+// The Progress methods are no-ops on a nil *Progress, so fetch code can update
+// config.progress without checking whether a bar is shown.
 func (p *Progress) increment() {
+	if p == nil {
+		return
+	}
 	p.current.Add(1)
 }
 
 func (p *Progress) addToTotal(n int) {
+	if p == nil {
+		return
+	}
 	p.total.Add(int32(n))
 }
 
@@ -118,8 +230,18 @@ func (p *Progress) buildBar(current, total int32) (string, *color.Color, float64
 }
 
 func (p *Progress) display() {
+	if p == nil {
+		return
+	}
 	current := p.current.Load()
 	total := p.total.Load()
+	if total == 0 {
+		return
+	}
+	if warning := config.rateLimit.warning(); warning != "" {
+		p.displayWithWarning(warning)
+		return
+	}
 	barContent, barColor, percentage := p.buildBar(current, total)
 	fmt.Printf("\r[%s] %s/%s (%s) ",
 		barColor.Sprint(barContent),
@@ -141,15 +263,20 @@ func (p *Progress) displayWithWarning(message string) {
 }
 
 func getLabelColor(label string) *color.Color {
+	if attrs, ok := config.theme.labels[normalizeLabelKey(label)]; ok {
+		return color.New(attrs...)
+	}
+
 	labelColors := map[string]*color.Color{
-		"Authored":         color.New(color.FgCyan),
-		"Mentioned":        color.New(color.FgYellow),
-		"Assigned":         color.New(color.FgMagenta),
-		"Commented":        color.New(color.FgBlue),
-		"Reviewed":         color.New(color.FgGreen),
-		"Review Requested": color.New(color.FgRed),
-		"Involved":         color.New(color.FgHiBlack),
-		"Recent Activity":  color.New(color.FgHiCyan),
+		"Authored":           color.New(color.FgCyan),
+		"Mentioned":          color.New(color.FgYellow),
+		"Assigned":           color.New(color.FgMagenta),
+		"Commented":          color.New(color.FgBlue),
+		"Reviewed":           color.New(color.FgGreen),
+		"Review Requested":   color.New(color.FgRed),
+		"Approval Requested": color.New(color.FgHiRed),
+		"Involved":           color.New(color.FgHiBlack),
+		"Recent Activity":    color.New(color.FgHiCyan),
 	}
 
 	if c, ok := labelColors[label]; ok {
@@ -163,6 +290,10 @@ func getUserColor(username string) *color.Color {
 	h.Write([]byte(username))
 	hash := h.Sum32()
 
+	if palette := config.theme.users; len(palette) > 0 {
+		return color.New(palette[hash%uint32(len(palette))]...)
+	}
+
 	colors := []*color.Color{
 		color.New(color.FgHiGreen),
 		color.New(color.FgHiYellow),
@@ -181,6 +312,10 @@ func getUserColor(username string) *color.Color {
 }
 
 func getStateColor(state string) *color.Color {
+	if attrs, ok := config.theme.states[state]; ok {
+		return color.New(attrs...)
+	}
+
 	switch state {
 	case "open":
 		return color.New(color.FgGreen)
@@ -200,12 +335,21 @@ func loadEnvFile(path string) error {
 	}
 	defer file.Close()
 
+	inSection := false
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
 		if line == "" || strings.HasPrefix(line, "#") {
 			continue
 		}
+		// Sections such as [colors] hold settings, not environment variables.
+		if _, ok := envFileSectionHeader(line); ok {
+			inSection = true
+			continue
+		}
+		if inSection {
+			continue
+		}
 
 		parts := strings.SplitN(line, "=", 2)
 		if len(parts) == 2 {
@@ -221,9 +365,72 @@ func loadEnvFile(path string) error {
 	return scanner.Err()
 }
 
+var (
+	tokenCommandMu    sync.Mutex
+	tokenCommandCache = make(map[string]string)
+)
+
+func tokenCommandEnvVar(platform string) string {
+	if platform == "gitlab" {
+		return "GITLAB_TOKEN_COMMAND"
+	}
+	return "GITHUB_TOKEN_COMMAND"
+}
+
+// runTokenCommand executes an external secret command (for example
+// `op read op://vault/gitlab/token`) and returns its trimmed stdout. Results are
+// cached for the lifetime of the process and the output is never printed.
+func runTokenCommand(command string) (string, error) {
+	command = strings.TrimSpace(command)
+	if len(command) >= 2 && (command[0] == '"' || command[0] == '\'') && command[len(command)-1] == command[0] {
+		command = strings.TrimSpace(command[1 : len(command)-1])
+	}
+	if command == "" {
+		return "", fmt.Errorf("token command is empty")
+	}
+
+	tokenCommandMu.Lock()
+	defer tokenCommandMu.Unlock()
+
+	if token, ok := tokenCommandCache[command]; ok {
+		return token, nil
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("token command failed: %w", err)
+	}
+
+	token := strings.TrimSpace(string(output))
+	if token == "" {
+		return "", fmt.Errorf("token command produced no output")
+	}
+
+	tokenCommandCache[command] = token
+	return token, nil
+}
+
 func parseTimeRange(timeStr string) (time.Duration, error) {
 	if len(timeStr) < 2 {
-		return 0, fmt.Errorf("invalid time range format: %s (expected format like 1h, 2d, 3w, 4m, 1y)", timeStr)
+		return 0, fmt.Errorf("invalid time range format: %s (expected format like 1h, 2d, 3bd, 3w, 4m, 1y)", timeStr)
+	}
+
+	if numStr, ok := strings.CutSuffix(timeStr, "bd"); ok {
+		num, err := strconv.Atoi(numStr)
+		if err != nil || num < 1 {
+			return 0, fmt.Errorf("invalid time range number: %s (must be a positive integer)", numStr)
+		}
+		now := time.Now()
+		return now.Sub(subtractBusinessDays(now, num)), nil
 	}
 
 	numStr := timeStr[:len(timeStr)-1]
@@ -247,12 +454,250 @@ func parseTimeRange(timeStr string) (time.Duration, error) {
 	case "y":
 		duration = time.Duration(num) * 365 * 24 * time.Hour
 	default:
-		return 0, fmt.Errorf("invalid time unit: %s (use h=hours, d=days, w=weeks, m=months, y=years)", unit)
+		return 0, fmt.Errorf("invalid time unit: %s (use h=hours, d=days, bd=business days, w=weeks, m=months, y=years)", unit)
 	}
 
 	return duration, nil
 }
 
+// subtractBusinessDays steps back n weekdays from t, so one business day
+// before Monday morning is Friday morning.
+func subtractBusinessDays(t time.Time, n int) time.Time {
+	for n > 0 {
+		t = t.AddDate(0, 0, -1)
+		if t.Weekday() != time.Saturday && t.Weekday() != time.Sunday {
+			n--
+		}
+	}
+	return t
+}
+
+type stateFilterFlag map[string]bool
+
+func (f stateFilterFlag) String() string {
+	states := make([]string, 0, len(f))
+	for state := range f {
+		states = append(states, state)
+	}
+	sort.Strings(states)
+	return strings.Join(states, ",")
+}
+
+func (f stateFilterFlag) Set(value string) error {
+	for _, state := range strings.Split(value, ",") {
+		state = strings.ToLower(strings.TrimSpace(state))
+		switch state {
+		case "":
+			continue
+		case "open", "closed", "merged":
+			f[state] = true
+		default:
+			return fmt.Errorf("invalid state %q (allowed: open|closed|merged)", state)
+		}
+	}
+	return nil
+}
+
+func activityState(mr MergeRequestModel) string {
+	if mr.State != "closed" {
+		return "open"
+	}
+	if mr.Merged {
+		return "merged"
+	}
+	return "closed"
+}
+
+func filterActivitiesByState(activities []PRActivity, issueActivities []IssueActivity, states map[string]bool) ([]PRActivity, []IssueActivity) {
+	if len(states) == 0 {
+		return activities, issueActivities
+	}
+
+	filteredPRs := make([]PRActivity, 0, len(activities))
+	for _, activity := range activities {
+		if states[activityState(activity.MR)] {
+			filteredPRs = append(filteredPRs, activity)
+		}
+	}
+
+	filteredIssues := make([]IssueActivity, 0, len(issueActivities))
+	for _, issue := range issueActivities {
+		state := "open"
+		if issue.Issue.State == "closed" {
+			state = "closed"
+		}
+		if states[state] {
+			filteredIssues = append(filteredIssues, issue)
+		}
+	}
+
+	return filteredPRs, filteredIssues
+}
+
+// Exit codes let scripts and status bars branch on the result of a feed run.
+const (
+	exitNoActionable = 0
+	exitError        = 1
+	exitActionable   = 2
+)
+
+// actionableLabels are the labels that ask something of the user.
+var actionableLabels = map[string]bool{
+	"Review Requested": true,
+	"Assigned":         true,
+}
+
+// hasActionableItems reports whether an open item left by the --state filter
+// (including issues nested under a merge/pull request) carries one of the
+// actionableLabels.
+func hasActionableItems(activities []PRActivity, issueActivities []IssueActivity) bool {
+	activities, issueActivities = filterActivitiesByState(activities, issueActivities, config.states)
+	for _, activity := range activities {
+		if activity.MR.State != "closed" && actionableLabels[activity.Label] {
+			return true
+		}
+		for _, issue := range activity.Issues {
+			if issue.Issue.State != "closed" && actionableLabels[issue.Label] {
+				return true
+			}
+		}
+	}
+	for _, issue := range issueActivities {
+		if issue.Issue.State != "closed" && actionableLabels[issue.Label] {
+			return true
+		}
+	}
+	return false
+}
+
+// filterActivitiesByWindow drops items that were created after --until. The
+// fetch cutoff already limits results to items updated since the start of the
+// window, so what remains is everything that overlaps it.
+func filterActivitiesByWindow(activities []PRActivity, issueActivities []IssueActivity, until time.Time) ([]PRActivity, []IssueActivity) {
+	if until.IsZero() {
+		return activities, issueActivities
+	}
+
+	startedBy := func(createdAt, updatedAt time.Time) bool {
+		start := createdAt
+		if start.IsZero() {
+			start = updatedAt
+		}
+		return start.Before(until)
+	}
+
+	filteredPRs := make([]PRActivity, 0, len(activities))
+	for _, activity := range activities {
+		if !startedBy(activity.MR.CreatedAt, activity.UpdatedAt) {
+			continue
+		}
+		var nested []IssueActivity
+		for _, issue := range activity.Issues {
+			if startedBy(issue.Issue.CreatedAt, issue.UpdatedAt) {
+				nested = append(nested, issue)
+			}
+		}
+		activity.Issues = nested
+		filteredPRs = append(filteredPRs, activity)
+	}
+
+	filteredIssues := make([]IssueActivity, 0, len(issueActivities))
+	for _, issue := range issueActivities {
+		if startedBy(issue.Issue.CreatedAt, issue.UpdatedAt) {
+			filteredIssues = append(filteredIssues, issue)
+		}
+	}
+
+	return filteredPRs, filteredIssues
+}
+
+// filterActivitiesByIssue keeps the issues matching keep, standalone or
+// nested, and the merge/pull requests that still have a nested issue left.
+// Issue-only filters (--due-soon, --min-weight) use it.
+func filterActivitiesByIssue(activities []PRActivity, issueActivities []IssueActivity, keep func(IssueActivity) bool) ([]PRActivity, []IssueActivity) {
+	filteredPRs := make([]PRActivity, 0, len(activities))
+	for _, activity := range activities {
+		var nested []IssueActivity
+		for _, issue := range activity.Issues {
+			if keep(issue) {
+				nested = append(nested, issue)
+			}
+		}
+		if len(nested) == 0 {
+			continue
+		}
+		activity.Issues = nested
+		filteredPRs = append(filteredPRs, activity)
+	}
+
+	filteredIssues := make([]IssueActivity, 0, len(issueActivities))
+	for _, issue := range issueActivities {
+		if keep(issue) {
+			filteredIssues = append(filteredIssues, issue)
+		}
+	}
+
+	return filteredPRs, filteredIssues
+}
+
+// filterActivitiesByMinWeight implements --min-weight: only issues with at
+// least that weight are kept. Zero disables the filter.
+func filterActivitiesByMinWeight(activities []PRActivity, issueActivities []IssueActivity, minWeight int) ([]PRActivity, []IssueActivity) {
+	if minWeight <= 0 {
+		return activities, issueActivities
+	}
+	return filterActivitiesByIssue(activities, issueActivities, func(issue IssueActivity) bool {
+		return issue.Issue.Weight >= minWeight
+	})
+}
+
+// splitRepoArgs separates leading repository arguments (`git-feed team/api
+// team/web`) from the command that may follow them. Repositories always
+// contain a slash and command names never do.
+func splitRepoArgs(args []string) (repos, command []string) {
+	for len(args) > 0 && strings.Contains(args[0], "/") {
+		repos = append(repos, args[0])
+		args = args[1:]
+	}
+	return repos, args
+}
+
+// resolveUserAliases reads the extra usernames that count as the current user
+// (previous account names, bots the user operates): GITHUB_USER_ALIASES or
+// GITLAB_USER_ALIASES, plus USER_ALIASES for both platforms.
+func resolveUserAliases(platform string) map[string]bool {
+	platformVar := "GITHUB_USER_ALIASES"
+	if platform == "gitlab" {
+		platformVar = "GITLAB_USER_ALIASES"
+	}
+
+	aliases := make(map[string]bool)
+	for _, value := range []string{os.Getenv(platformVar), os.Getenv("USER_ALIASES")} {
+		for _, alias := range strings.Split(value, ",") {
+			if alias = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(alias), "@")); alias != "" {
+				aliases[alias] = true
+			}
+		}
+	}
+	return aliases
+}
+
+// isUserAlias reports whether username is one of config.userAliases.
+func isUserAlias(username string) bool {
+	return config.userAliases[strings.ToLower(strings.TrimSpace(username))]
+}
+
+// userAliasList returns config.userAliases sorted, for searches that take one
+// username at a time.
+func userAliasList() []string {
+	aliases := make([]string, 0, len(config.userAliases))
+	for alias := range config.userAliases {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
 func resolveAllowedRepos(platform, allowedReposFlag string) string {
 	if value := strings.TrimSpace(allowedReposFlag); value != "" {
 		return value
@@ -270,48 +715,455 @@ func resolveAllowedRepos(platform, allowedReposFlag string) string {
 	return strings.TrimSpace(os.Getenv("ALLOWED_REPOS"))
 }
 
+// applyColorMode disables fatih/color output for --no-color or NO_COLOR. The
+// library reads NO_COLOR itself at startup, but a value from the .env file is
+// only loaded later, so this runs again after loadEnvFile.
+func applyColorMode(noColorFlag bool) {
+	if noColorFlag || os.Getenv("NO_COLOR") != "" {
+		color.NoColor = true
+	}
+}
+
+// mustLoadLocation resolves --tz, then TZ (which may come from the .env file,
+// loaded after the runtime has already initialised time.Local).
+func mustLoadLocation(tzFlag string) *time.Location {
+	name := strings.TrimSpace(tzFlag)
+	if name == "" {
+		name = strings.TrimSpace(os.Getenv("TZ"))
+	}
+	if name == "" || strings.EqualFold(name, "local") {
+		return time.Local
+	}
+
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		fmt.Printf("Error: invalid timezone %q: %v\n", name, err)
+		os.Exit(1)
+	}
+	return location
+}
+
+// parseWindowDate accepts YYYY-MM-DD (in the display timezone) or RFC 3339.
+// A bare --until date includes that whole day.
+func parseWindowDate(value string, location *time.Location, endOfDay bool) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, location)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q (expected YYYY-MM-DD or RFC 3339)", value)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// resolveActivityWindow turns --since/--until into absolute bounds. Without
+// --since the window is --time long, ending at --until (or now). A zero until
+// means the window is open-ended; without either flag since is zero too, so
+// activityCutoff keeps sliding and repos.<path>.time entries can replace
+// --time per project.
+func resolveActivityWindow(now time.Time, timeRange time.Duration, sinceStr, untilStr string, location *time.Location) (time.Time, time.Time, error) {
+	var since, until time.Time
+	var err error
+
+	if strings.TrimSpace(sinceStr) == "" && strings.TrimSpace(untilStr) == "" {
+		return time.Time{}, time.Time{}, nil
+	}
+	if strings.TrimSpace(untilStr) != "" {
+		if until, err = parseWindowDate(untilStr, location, true); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("--until: %w", err)
+		}
+	}
+
+	end := now
+	if !until.IsZero() {
+		end = until
+	}
+	if strings.TrimSpace(sinceStr) != "" {
+		if since, err = parseWindowDate(sinceStr, location, false); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("--since: %w", err)
+		}
+	} else {
+		since = end.Add(-timeRange)
+	}
+
+	if !since.Before(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("--since %s must be before %s", since.Format(time.RFC3339), end.Format(time.RFC3339))
+	}
+	return since, until, nil
+}
+
+// resolveFeedWindow is the window of a run: --standup and --digest weekly
+// replace --since/--until/--time with their own dates.
+func resolveFeedWindow(now time.Time, timeRange time.Duration, sinceStr, untilStr string, standup bool, digest string, location *time.Location) (time.Time, time.Time, error) {
+	if standup {
+		since, until := standupWindow(now, location)
+		return since, until, nil
+	}
+	if digest == "weekly" {
+		since, until := digestWindow(now, location)
+		return since, until, nil
+	}
+	return resolveActivityWindow(now, timeRange, sinceStr, untilStr, location)
+}
+
+func mustResolveFeedWindow(timeRange time.Duration, sinceStr, untilStr string, standup bool, digest string, location *time.Location) (time.Time, time.Time) {
+	since, until, err := resolveFeedWindow(time.Now(), timeRange, sinceStr, untilStr, standup, digest, location)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	return since, until
+}
+
+// activityCutoff is the oldest update included in the feed.
+func activityCutoff() time.Time {
+	if !config.since.IsZero() {
+		return config.since
+	}
+	return time.Now().Add(-config.timeRange)
+}
+
+// repoCutoff is the cutoff for one project: a repos.<path>.time entry in
+// config.yaml replaces --time for it. Date windows (--since/--until) apply to
+// every project as given.
+func repoCutoff(projectPath string, cutoff time.Time) time.Time {
+	if !config.since.IsZero() {
+		return cutoff
+	}
+	if options, ok := repoOptionsFor(projectPath); ok && options.timeRange > 0 {
+		return time.Now().Add(-options.timeRange)
+	}
+	return cutoff
+}
+
+func hasRepoTimeRanges() bool {
+	for _, options := range config.repoOptions {
+		if options.timeRange > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// earliestRepoCutoff widens cutoff to the longest per-repo time range, for
+// fetches that cannot query each project separately (GitHub search, cache
+// scans). fetchActivities drops what falls outside each project's own window.
+func earliestRepoCutoff(cutoff time.Time) time.Time {
+	if !config.since.IsZero() {
+		return cutoff
+	}
+	earliest := cutoff
+	for _, options := range config.repoOptions {
+		if options.timeRange <= 0 {
+			continue
+		}
+		if projectCutoff := time.Now().Add(-options.timeRange); projectCutoff.Before(earliest) {
+			earliest = projectCutoff
+		}
+	}
+	return earliest
+}
+
+// filterActivitiesByRepoCutoff drops items updated before their project's
+// repoCutoff. Issues nested under a kept merge/pull request stay with it.
+// Without per-repo time ranges the platform already applied cutoff, so the
+// items are returned unchanged.
+func filterActivitiesByRepoCutoff(activities []PRActivity, issueActivities []IssueActivity, cutoff time.Time) ([]PRActivity, []IssueActivity) {
+	if !config.since.IsZero() || !hasRepoTimeRanges() {
+		return activities, issueActivities
+	}
+	filteredPRs := make([]PRActivity, 0, len(activities))
+	for _, activity := range activities {
+		if !activity.UpdatedAt.Before(repoCutoff(activity.Owner+"/"+activity.Repo, cutoff)) {
+			filteredPRs = append(filteredPRs, activity)
+		}
+	}
+	filteredIssues := make([]IssueActivity, 0, len(issueActivities))
+	for _, issue := range issueActivities {
+		if !issue.UpdatedAt.Before(repoCutoff(issue.Owner+"/"+issue.Repo, cutoff)) {
+			filteredIssues = append(filteredIssues, issue)
+		}
+	}
+	return filteredPRs, filteredIssues
+}
+
+// describeActivityWindow renders the window for headers, e.g. "the last 168h0m0s"
+// or "2026-01-01 to 2026-01-15".
+func describeActivityWindow() string {
+	if config.until.IsZero() {
+		if config.since.IsZero() {
+			return fmt.Sprintf("the last %v", config.timeRange)
+		}
+		return "since " + displayTime(config.since).Format("2006-01-02")
+	}
+	return displayTime(config.since).Format("2006-01-02") + " to " + displayTime(config.until.Add(-time.Nanosecond)).Format("2006-01-02")
+}
+
+func displayTime(t time.Time) time.Time {
+	if config.location == nil {
+		return t.Local()
+	}
+	return t.In(config.location)
+}
+
+func mustParseSLATargets(slaFlag string) map[string]time.Duration {
+	value := slaFlag
+	if strings.TrimSpace(value) == "" {
+		value = os.Getenv("SLA_TARGETS")
+	}
+
+	targets, err := parseSLATargets(value)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	return targets
+}
+
+func resolveExcludedRepos(platform, excludedReposFlag string) string {
+	if value := strings.TrimSpace(excludedReposFlag); value != "" {
+		return value
+	}
+
+	platformVar := "GITHUB_EXCLUDED_REPOS"
+	if platform == "gitlab" {
+		platformVar = "GITLAB_EXCLUDED_REPOS"
+	}
+
+	if value := strings.TrimSpace(os.Getenv(platformVar)); value != "" {
+		return value
+	}
+
+	return strings.TrimSpace(os.Getenv("EXCLUDED_REPOS"))
+}
+
+func parseRepoList(value string) map[string]bool {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+
+	repos := make(map[string]bool)
+	for _, repo := range strings.Split(value, ",") {
+		repo = strings.TrimSpace(repo)
+		if repo != "" {
+			repos[repo] = true
+		}
+	}
+	return repos
+}
+
+func isRepoExcluded(projectPath string) bool {
+	if options, ok := repoOptionsFor(projectPath); ok && options.exclude {
+		return true
+	}
+	if len(config.excludedRepos) == 0 {
+		return false
+	}
+
+	normalized := strings.Trim(strings.TrimSpace(projectPath), "/")
+	for repo := range config.excludedRepos {
+		if strings.EqualFold(strings.Trim(strings.TrimSpace(repo), "/"), normalized) {
+			return true
+		}
+	}
+	return false
+}
+
 func main() {
+	// Registered first so it runs after the other deferred calls (the run log
+	// in particular); os.Exit would skip them.
+	exitCode := exitNoActionable
+	defer func() {
+		if exitCode != exitNoActionable {
+			os.Exit(exitCode)
+		}
+	}()
+
 	// Define flags
 	var timeRangeStr string
 	var platform string
 	var debugMode bool
 	var localMode bool
 	var showLinks bool
+	var showBranches bool
 	var llMode bool
 	var allowedReposFlag string
+	var excludedReposFlag string
 	var cleanCache bool
-
-	flag.StringVar(&timeRangeStr, "time", "1m", "Show items from last time range (1h, 2d, 3w, 4m, 1y)")
-	flag.StringVar(&platform, "platform", "github", "Platform to use (gitlab|github)")
+	var groupBy string
+	var apiFlag string
+	var githubSource string
+	var demoMode bool
+	var countOnly bool
+	var slaFlag string
+	var tzFlag string
+	var sinceFlag string
+	var untilFlag string
+	var twoColumn bool
+	var stream bool
+	var runTimeout time.Duration
+	var dryRun bool
+	var noRecency bool
+	var noColor bool
+	var noRepoDetect bool
+	var usersFlag string
+	var standup bool
+	var dueSoonFlag string
+	var minWeight int
+	var digest string
+	var wide bool
+	var outputPath string
+	var logFile string
+	var debugHTTP bool
+	var httpCache bool
+	var perPage int
+	var maxItems int
+	var maxAPICalls int
+	var asciiMode bool
+	var showVersion bool
+	var profileFlag string
+	var proxyFlag string
+	var caCertFlag string
+	var insecureSkipVerify bool
+	states := stateFilterFlag{}
+
+	flag.StringVar(&timeRangeStr, "time", "1m", "Show items from last time range (1h, 2d, 3bd, 3w, 4m, 1y; bd = business days)")
+	flag.StringVar(&sinceFlag, "since", "", "Only show items updated on or after this date (YYYY-MM-DD or RFC 3339; overrides --time)")
+	flag.StringVar(&untilFlag, "until", "", "Only show items created on or before this date (YYYY-MM-DD or RFC 3339)")
+	flag.StringVar(&platform, "platform", "github", "Platform to use ("+strings.Join(platformNames(), "|")+")")
+	flag.StringVar(&profileFlag, "profile", "", "Use the [profile.NAME] settings from the .env file and a separate cache database (env: GIT_FEED_PROFILE)")
+	flag.StringVar(&proxyFlag, "proxy", "", "Send API requests through this proxy, e.g. http://proxy.example.com:3128 (default: HTTP_PROXY/HTTPS_PROXY/NO_PROXY)")
+	flag.StringVar(&caCertFlag, "ca-cert", "", "PEM file with extra CA certificates to trust, e.g. a private CA of a self-managed instance (env: GITLAB_CA_CERT)")
+	flag.BoolVar(&insecureSkipVerify, "insecure-skip-verify", false, "Don't verify TLS certificates (insecure; prefer --ca-cert)")
 	flag.BoolVar(&debugMode, "debug", false, "Show detailed API logging")
 	flag.BoolVar(&localMode, "local", false, "Use local database instead of platform API")
 	flag.BoolVar(&showLinks, "links", false, "Show hyperlinks underneath each PR/issue")
+	flag.BoolVar(&showBranches, "branches", false, "Show the source → target branch underneath each PR/MR")
 	flag.BoolVar(&llMode, "ll", false, "Shortcut for --local --links (offline mode with links)")
 	flag.BoolVar(&cleanCache, "clean", false, "Delete and recreate the database cache")
+	flag.BoolVar(&httpCache, "http-cache", false, "Reuse API responses from the last few minutes and revalidate older ones by ETag, where the server's Cache-Control allows it")
+	flag.BoolVar(&demoMode, "demo", false, "Show a built-in sample feed (no token, network or cache needed)")
+	flag.StringVar(&tzFlag, "tz", "", "Timezone for displayed dates, e.g. local, UTC, Europe/Berlin (env: TZ)")
+	flag.StringVar(&dueSoonFlag, "due-soon", "", "Only show issues due within this range, overdue ones included (e.g. 7d; GitLab due dates)")
+	flag.IntVar(&minWeight, "min-weight", 0, "Only show issues with at least this GitLab weight (story points)")
+	flag.StringVar(&slaFlag, "sla", "", "Response targets per label, e.g. review-requested=24h,assigned=3d (env: SLA_TARGETS)")
+	flag.BoolVar(&countOnly, "count-only", false, "Print only per-label counts of open items on one line (for shell prompts and status bars)")
+	flag.StringVar(&excludedReposFlag, "exclude-repos", "", "Comma-separated list of repos to skip even when otherwise allowed")
+	flag.Var(states, "state", "Only show items in this state (open|closed|merged); repeatable or comma-separated")
+	flag.BoolVar(&showVersion, "version", false, "Print the version, commit and build date and exit")
+	flag.DurationVar(&runTimeout, "timeout", 0, "Give up on the run after this long, e.g. 2m for unattended cron runs (default: no limit)")
+	flag.BoolVar(&dryRun, "dry-run", false, "Resolve the allowed GitLab projects and estimate the API calls a fetch would make, per endpoint, without fetching")
+	flag.StringVar(&logFile, "log-file", "", "Append JSON run logs (API calls, retries, cache warnings) to this file, e.g. ~/git-feed.log")
+	flag.BoolVar(&debugHTTP, "debug-http", false, "Log every HTTP request's method, redacted URL, status and duration to stderr (or to --log-file); never the token")
+	flag.StringVar(&outputPath, "output", "", "Write the feed (or the export JSON) to this file instead of stdout; the file is replaced atomically")
+	flag.BoolVar(&wide, "wide", false, "Don't shorten long titles and project paths to fit the terminal width")
+	flag.BoolVar(&stream, "stream", false, "Print each GitLab project's items as soon as they are fetched, followed by the sorted feed")
+	flag.BoolVar(&twoColumn, "two-column", false, "Show open PRs/MRs and open issues side by side on terminals at least 160 columns wide")
+	flag.BoolVar(&asciiMode, "ascii", false, "Use plain ASCII instead of the ● update marker, 🔗 link icon and other Unicode symbols")
+	flag.BoolVar(&noColor, "no-color", false, "Disable colored output (also honors the NO_COLOR environment variable)")
+	flag.BoolVar(&noRecency, "no-recency", false, "Don't split sections into Today/Yesterday/Earlier this week/Older subheadings")
+	flag.IntVar(&perPage, "per-page", maxPerPage, "Items per page of REST API listings (1-100); lower it for instances that enforce a smaller maximum")
+	flag.IntVar(&maxItems, "max-items", 0, "Only process the N most recently updated MRs and N issues of each GitLab project (default: no limit)")
+	flag.IntVar(&maxAPICalls, "max-api-calls", 0, "Stop fetching after this many GitLab API requests and show what was fetched so far (default: no limit)")
+	flag.StringVar(&apiFlag, "api", "rest", "API used to fetch the feed (rest|graphql); graphql needs far fewer requests")
+	flag.StringVar(&githubSource, "github-source", "search", "Where the GitHub feed starts from (search|notifications); notifications only covers review requests, assignments and mentions")
+	flag.BoolVar(&standup, "standup", false, "Show the previous working day as plain bullets grouped by author, ready to paste into a standup thread")
+	flag.StringVar(&digest, "digest", "", "Print per-project counts of opened/merged/closed items for the previous calendar week (weekly), e.g. from a Monday cron job")
+	flag.StringVar(&usersFlag, "users", "", "Team feed: comma-separated usernames, each with labels derived for that user and shown in its own section")
+	flag.StringVar(&groupBy, "group-by", "", "Group output by project or label instead of by state (project|label)")
+	flag.BoolVar(&noRepoDetect, "no-repo-detect", false, "Don't scope the feed to the origin remote of the current git repository when no allowed repos are set")
 	flag.StringVar(&allowedReposFlag, "allowed-repos", "", "Comma-separated list of allowed repos (GitHub: owner/repo; GitLab: group[/subgroup]/repo)")
 
 	// Custom usage message
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: %s [options]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Usage: %s [options] [repo...] [command]\n\n", os.Args[0])
 		fmt.Fprintln(os.Stderr, "Git Feed - Monitor pull requests and issues across repositories")
+		fmt.Fprintln(os.Stderr, "\nCommands:")
+		fmt.Fprintln(os.Stderr, "  merge group[/subgroup]/repo!iid        - Merge a GitLab MR after approval, pipeline and conflict checks")
+		fmt.Fprintln(os.Stderr, "  share                                  - Upload the feed as a private GitLab snippet and print its URL")
+		fmt.Fprintln(os.Stderr, "  report reviewers                       - Show open GitLab MRs awaiting each reviewer and the oldest pending request")
+		fmt.Fprintln(os.Stderr, "  report latency                         - Show review-request-to-first-review and time-to-merge for MRs in the window")
+		fmt.Fprintln(os.Stderr, "  doctor                                 - Check config syntax, the GitLab URL, token, cache and each allowed project")
+		fmt.Fprintln(os.Stderr, "  export [--anonymize]                   - Print the feed as JSON (pseudonymized for bug reports with --anonymize)")
+		fmt.Fprintln(os.Stderr, "  sync                                   - Update the cache without printing the feed (for cron; read it with --local)")
+		fmt.Fprintln(os.Stderr, "  web [--listen :8080] [--refresh 1m]    - Serve the cached feed as an auto-refreshing web dashboard")
+		fmt.Fprintln(os.Stderr, "  pick [--copy]                          - Fuzzy-find an item of the feed and open it in the browser (or copy its URL)")
+		fmt.Fprintln(os.Stderr, "  open N [--copy]                        - Open item N of the last feed in the browser (or copy its URL)")
+		fmt.Fprintln(os.Stderr, "  completion bash|zsh|fish               - Print a shell completion script (flags, labels and cached projects)")
+		fmt.Fprintln(os.Stderr, "  config get|set|unset|list              - Read or change config.yaml (values are validated on write)")
+		fmt.Fprintln(os.Stderr, "  auth login|logout                      - Store or remove the platform token in the system keyring")
+		fmt.Fprintln(os.Stderr, "  clean [--older-than 90d]               - Delete cached items (and their notes) not updated within the retention")
+		fmt.Fprintln(os.Stderr, "  db compact                             - Rewrite the cache file to reclaim space freed by pruning")
+		fmt.Fprintln(os.Stderr, "  db stats                               - Show cache size, item counts per bucket and project, and the last sync")
+		fmt.Fprintln(os.Stderr, "  db metrics                             - Print sync totals (API retries, items, durations) as Prometheus metrics")
 		fmt.Fprintln(os.Stderr, "\nOptions:")
 		flag.PrintDefaults()
 		fmt.Fprintln(os.Stderr, "\nEnvironment Variables:")
 		fmt.Fprintln(os.Stderr, "  GITLAB_TOKEN or GITLAB_ACTIVITY_TOKEN  - GitLab Personal Access Token")
+		fmt.Fprintln(os.Stderr, "  GITLAB_TOKEN_COMMAND                   - Optional command that prints the GitLab token (used when no token is set)")
+		fmt.Fprintln(os.Stderr, "  GITLAB_USE_GLAB                        - Reuse the glab CLI token without asking (true) or never (false)")
 		fmt.Fprintln(os.Stderr, "  GITLAB_USERNAME or GITLAB_USER         - Optional GitLab username")
 		fmt.Fprintln(os.Stderr, "  GITLAB_HOST                            - Optional GitLab host (overrides GITLAB_BASE_URL when set)")
 		fmt.Fprintln(os.Stderr, "  GITLAB_BASE_URL                        - Optional GitLab base URL (default: https://gitlab.com)")
 		fmt.Fprintln(os.Stderr, "  GITHUB_TOKEN                           - GitHub Personal Access Token")
+		fmt.Fprintln(os.Stderr, "  GITHUB_TOKEN_COMMAND                   - Optional command that prints the GitHub token (used when no token is set)")
 		fmt.Fprintln(os.Stderr, "  GITHUB_USERNAME                        - Required in GitHub online mode")
 		fmt.Fprintln(os.Stderr, "  GITHUB_ALLOWED_REPOS                   - Optional in GitHub online mode (owner/repo)")
 		fmt.Fprintln(os.Stderr, "  GITLAB_ALLOWED_REPOS                   - Required in GitLab online mode (group[/subgroup]/repo)")
 		fmt.Fprintln(os.Stderr, "  ALLOWED_REPOS                          - Legacy fallback when platform-specific vars are unset")
+		fmt.Fprintln(os.Stderr, "  GITHUB_EXCLUDED_REPOS / GITLAB_EXCLUDED_REPOS - Optional repos to skip (fallback: EXCLUDED_REPOS)")
+		fmt.Fprintln(os.Stderr, "  USER_ALIASES (+ GITHUB_/GITLAB_USER_ALIASES) - Other usernames that count as you when deriving labels")
+		fmt.Fprintln(os.Stderr, "  GIT_FEED_PROFILE                       - Default for --profile")
+		fmt.Fprintln(os.Stderr, "  GIT_FEED_HOME                          - One directory for both settings and caches instead of the XDG directories")
+		fmt.Fprintln(os.Stderr, "  CACHE_BACKEND                          - Cache implementation: bolt (default) or sqlite (PLATFORM.sqlite, queryable with SQL)")
+		fmt.Fprintln(os.Stderr, "  CACHE_ENCRYPTION                       - Encrypt cached values with a key kept in the system keyring (on|off, bolt backend only)")
+		fmt.Fprintln(os.Stderr, "  CACHE_RETENTION                        - How long cached items are kept after their last update (default: 90d; off disables pruning)")
+		fmt.Fprintln(os.Stderr, "  HTTP_PROXY / HTTPS_PROXY / NO_PROXY    - Proxy for API requests (overridden by --proxy)")
+		fmt.Fprintln(os.Stderr, "  GITLAB_CA_CERT                         - Default for --ca-cert")
+		fmt.Fprintln(os.Stderr, "  NO_COLOR                               - Disable colored output when set to any value (same as --no-color)")
 		fmt.Fprintln(os.Stderr, "\nConfiguration File:")
-		fmt.Fprintln(os.Stderr, "  ~/.git-feed/.env                       - Shared configuration file (auto-created)")
-		fmt.Fprintln(os.Stderr, "  ~/.git-feed/github.db|gitlab.db        - Platform-specific cache databases")
+		fmt.Fprintln(os.Stderr, "  CONFIG/.env                            - Shared configuration file (auto-created)")
+		fmt.Fprintln(os.Stderr, "  CONFIG/config.yaml                     - Optional structured settings (colors, per-repo options); overrides .env")
+		fmt.Fprintln(os.Stderr, "  DATA/github.db|gitlab.db               - Platform-specific cache databases (github-NAME.db|gitlab-NAME.db with --profile)")
+		fmt.Fprintln(os.Stderr, "  DATA/gitlab@HOST.db                    - GitLab cache for instances other than gitlab.com")
+		fmt.Fprintln(os.Stderr, "  CONFIG is $XDG_CONFIG_HOME/git-feed (~/.config/git-feed), DATA is $XDG_DATA_HOME/git-feed")
+		fmt.Fprintln(os.Stderr, "  (~/.local/share/git-feed); GIT_FEED_HOME or an existing ~/.git-feed holds both")
+		fmt.Fprintln(os.Stderr, "\nExit Codes:")
+		fmt.Fprintln(os.Stderr, "  0  No open items labeled Review Requested or Assigned")
+		fmt.Fprintln(os.Stderr, "  1  Configuration error or failed fetch")
+		fmt.Fprintln(os.Stderr, "  2  Open items labeled Review Requested or Assigned exist")
 	}
 
 	flag.Parse()
+	if showVersion {
+		fmt.Println(formatVersion(resolveBuildMetadata()))
+		return
+	}
+	config.debugHTTP = debugHTTP
+	if logFile != "" {
+		closer, err := openRunLog(logFile)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer closer.Close()
+		runStart := time.Now()
+		logRun(slog.LevelInfo, "run started", "args", os.Args[1:])
+		defer func() {
+			logRun(slog.LevelInfo, "run finished", "duration_ms", time.Since(runStart).Milliseconds(),
+				"api_errors", config.apiErrorCount.Load(), "cache_errors", config.dbErrorCount.Load(), "exit_code", exitCode)
+		}()
+	}
+	// A file is not a terminal, so it gets no ANSI colors.
+	applyColorMode(noColor || outputPath != "")
+	// Redirected output (`git-feed > feed.txt`) is plain text without flags.
+	if asciiMode || !stdoutIsTerminal() {
+		symbols = asciiSymbols
+	}
 
 	// Handle --ll shortcut
 	if llMode {
@@ -320,29 +1172,274 @@ func main() {
 	}
 
 	platform = strings.ToLower(strings.TrimSpace(platform))
-	if platform != "gitlab" && platform != "github" {
-		fmt.Printf("Error: invalid --platform value %q (allowed: gitlab|github)\n", platform)
+	if !isRegisteredPlatform(platform) {
+		fmt.Printf("Error: invalid --platform value %q (allowed: %s)\n", platform, strings.Join(platformNames(), "|"))
 		os.Exit(1)
 	}
 
-	// Parse time range
-	timeRange, err := parseTimeRange(timeRangeStr)
-	if err != nil {
-		fmt.Printf("Error: %v\n", err)
-		fmt.Println("Examples: --time 1h (1 hour), --time 2d (2 days), --time 3w (3 weeks), --time 4m (4 months), --time 1y (1 year)")
-		os.Exit(1)
+	repoArgs, command := splitRepoArgs(flag.Args())
+	if len(repoArgs) > 0 {
+		if strings.TrimSpace(allowedReposFlag) != "" {
+			fmt.Println("Error: use either --allowed-repos or repository arguments, not both")
+			os.Exit(1)
+		}
+		allowedReposFlag = strings.Join(repoArgs, ",")
 	}
-
-	homeDir, err := os.UserHomeDir()
+	if len(command) > 0 {
+		switch command[0] {
+		case "merge", "share", "report", "doctor":
+			if platform != "gitlab" {
+				fmt.Printf("Error: the %s command requires --platform gitlab\n", command[0])
+				os.Exit(1)
+			}
+			if localMode {
+				fmt.Printf("Error: the %s command needs API access and cannot run with --local\n", command[0])
+				os.Exit(1)
+			}
+		case "sync":
+			if localMode {
+				fmt.Println("Error: the sync command needs API access and cannot run with --local")
+				os.Exit(1)
+			}
+		case "web":
+			// The dashboard serves the cache; `sync` keeps it current.
+			localMode = true
+		case "export", "pick", "open", "completion", "config", "auth", "clean", "db":
+		default:
+			fmt.Printf("Error: unknown command %q (allowed: merge|share|report|doctor|export|sync|web|pick|open|completion|config|auth|clean|db)\n", command[0])
+			os.Exit(1)
+		}
+	}
+
+	outputPath = strings.TrimSpace(outputPath)
+	if outputPath != "" && len(command) > 0 && command[0] != "export" {
+		fmt.Printf("Error: --output only applies to the feed and the export command, not %s\n", command[0])
+		os.Exit(1)
+	}
+
+	apiFlag = strings.ToLower(strings.TrimSpace(apiFlag))
+	if apiFlag != "rest" && apiFlag != "graphql" {
+		fmt.Printf("Error: invalid --api value %q (allowed: rest|graphql)\n", apiFlag)
+		os.Exit(1)
+	}
+
+	githubSource = strings.ToLower(strings.TrimSpace(githubSource))
+	if githubSource != "search" && githubSource != "notifications" {
+		fmt.Printf("Error: invalid --github-source value %q (allowed: search|notifications)\n", githubSource)
+		os.Exit(1)
+	}
+	if githubSource == "notifications" && platform != "github" {
+		fmt.Println("Error: --github-source notifications requires --platform github")
+		os.Exit(1)
+	}
+
+	teamUsers := parseTeamUsers(usersFlag)
+	if len(teamUsers) > 0 {
+		switch {
+		case len(command) > 0:
+			fmt.Printf("Error: --users only applies to the feed, not the %s command\n", command[0])
+			os.Exit(1)
+		case localMode:
+			fmt.Println("Error: --users needs API access and cannot run with --local (the cache only holds your own labels)")
+			os.Exit(1)
+		case githubSource == "notifications":
+			fmt.Println("Error: --users cannot be combined with --github-source notifications (notifications only cover your own account)")
+			os.Exit(1)
+		}
+	}
+
+	var dueSoon time.Duration
+	if value := strings.TrimSpace(dueSoonFlag); value != "" {
+		parsed, err := parseTimeRange(value)
+		if err != nil {
+			fmt.Printf("Error: --due-soon: %v\n", err)
+			os.Exit(1)
+		}
+		dueSoon = parsed
+	}
+
+	if minWeight < 0 {
+		fmt.Println("Error: --min-weight must not be negative")
+		os.Exit(1)
+	}
+
+	digest = strings.ToLower(strings.TrimSpace(digest))
+	if digest != "" && digest != "weekly" {
+		fmt.Printf("Error: invalid --digest value %q (allowed: weekly)\n", digest)
+		os.Exit(1)
+	}
+	if standup && digest != "" {
+		fmt.Println("Error: use either --standup or --digest, not both")
+		os.Exit(1)
+	}
+	if standup || digest != "" {
+		report := "--standup"
+		if digest != "" {
+			report = "--digest"
+		}
+		switch {
+		case len(command) > 0:
+			fmt.Printf("Error: %s only applies to the feed, not the %s command\n", report, command[0])
+			os.Exit(1)
+		case len(teamUsers) > 0 || countOnly:
+			fmt.Printf("Error: %s cannot be combined with --users or --count-only\n", report)
+			os.Exit(1)
+		}
+		flag.Visit(func(f *flag.Flag) {
+			if f.Name == "time" || f.Name == "since" || f.Name == "until" {
+				fmt.Printf("Error: %s sets its own window and cannot be combined with --%s\n", report, f.Name)
+				os.Exit(1)
+			}
+		})
+	}
+
+	if stream {
+		switch {
+		case platform != "gitlab":
+			fmt.Println("Error: --stream requires --platform gitlab (GitHub searches all repositories at once)")
+			os.Exit(1)
+		case len(command) > 0:
+			fmt.Printf("Error: --stream only applies to the feed, not the %s command\n", command[0])
+			os.Exit(1)
+		case countOnly || outputPath != "" || len(teamUsers) > 0 || standup || digest != "":
+			fmt.Println("Error: --stream cannot be combined with --count-only, --output, --users, --standup or --digest")
+			os.Exit(1)
+		}
+	}
+
+	if dryRun {
+		switch {
+		case platform != "gitlab":
+			fmt.Println("Error: --dry-run requires --platform gitlab")
+			os.Exit(1)
+		case localMode:
+			fmt.Println("Error: --dry-run estimates an online fetch and cannot run with --local")
+			os.Exit(1)
+		case len(command) > 0 && command[0] != "sync":
+			fmt.Printf("Error: --dry-run only applies to the feed and the sync command, not the %s command\n", command[0])
+			os.Exit(1)
+		}
+	}
+
+	if runTimeout < 0 {
+		fmt.Println("Error: --timeout must not be negative")
+		os.Exit(1)
+	}
+	if perPage < 1 || perPage > maxPerPage {
+		fmt.Printf("Error: --per-page must be between 1 and %d\n", maxPerPage)
+		os.Exit(1)
+	}
+	config.perPage = perPage
+	switch {
+	case maxItems < 0:
+		fmt.Println("Error: --max-items must not be negative")
+		os.Exit(1)
+	case maxItems > 0 && platform != "gitlab":
+		fmt.Println("Error: --max-items requires --platform gitlab")
+		os.Exit(1)
+	}
+	config.maxItems = maxItems
+	switch {
+	case maxAPICalls < 0:
+		fmt.Println("Error: --max-api-calls must not be negative")
+		os.Exit(1)
+	case maxAPICalls > 0 && platform != "gitlab":
+		fmt.Println("Error: --max-api-calls requires --platform gitlab")
+		os.Exit(1)
+	}
+	config.maxAPICalls = maxAPICalls
+	if runTimeout > 0 && len(command) > 0 && command[0] == "web" {
+		fmt.Println("Error: --timeout does not apply to the web command, which serves until it is stopped")
+		os.Exit(1)
+	}
+
+	groupBy = strings.ToLower(strings.TrimSpace(groupBy))
+	if groupBy != "" && groupBy != "project" && groupBy != "label" {
+		fmt.Printf("Error: invalid --group-by value %q (allowed: project|label)\n", groupBy)
+		os.Exit(1)
+	}
+
+	// Parse time range
+	timeRange, err := parseTimeRange(timeRangeStr)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		fmt.Println("Examples: --time 1h (1 hour), --time 2d (2 days), --time 3bd (3 business days), --time 3w (3 weeks), --time 4m (4 months), --time 1y (1 year)")
+		os.Exit(1)
+	}
+	if sinceFlag != "" {
+		flag.Visit(func(f *flag.Flag) {
+			if f.Name == "time" {
+				fmt.Println("Error: use either --time or --since, not both")
+				os.Exit(1)
+			}
+		})
+	}
+
+	if demoMode {
+		if len(command) > 0 {
+			fmt.Printf("Error: the %s command cannot be combined with --demo\n", command[0])
+			os.Exit(1)
+		}
+
+		config.debugMode = debugMode
+		config.showLinks = showLinks
+		config.showBranches = showBranches
+		config.timeRange = timeRange
+		config.groupBy = groupBy
+		config.states = states
+		config.countOnly = countOnly
+		config.twoColumn = twoColumn
+		config.noRecency = noRecency
+		config.lineWidth = itemLineWidth(wide || outputPath != "")
+		config.platform = "gitlab"
+		config.slaTargets = mustParseSLATargets(slaFlag)
+		config.dueSoon = dueSoon
+		config.minWeight = minWeight
+		config.location = mustLoadLocation(tzFlag)
+		config.since, config.until = mustResolveFeedWindow(timeRange, sinceFlag, untilFlag, false, "", config.location)
+		config.projectBadges = demoProjectBadges()
+
+		activities, issueActivities := demoActivities(time.Now(), activityCutoff())
+		render := func(out io.Writer) error {
+			activities, issueActivities := filterActivitiesByWindow(activities, issueActivities, config.until)
+			activities, issueActivities = filterActivitiesByDueSoon(activities, issueActivities, config.dueSoon, time.Now())
+			activities, issueActivities = filterActivitiesByMinWeight(activities, issueActivities, config.minWeight)
+			displayActivities(out, activities, issueActivities)
+			return nil
+		}
+		if outputPath != "" {
+			if err := writeOutputFile(outputPath, render); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+		_ = render(os.Stdout)
+		return
+	}
+
+	runCtx, cancelRun := withRunTimeout(context.Background(), runTimeout)
+	defer cancelRun()
+
+	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		fmt.Printf("Error: Could not determine home directory: %v\n", err)
 		os.Exit(1)
 	}
 
-	configDir := filepath.Join(homeDir, ".git-feed")
-	dbFileName := "github.db"
-	if platform == "gitlab" {
-		dbFileName = "gitlab.db"
+	dirs := resolveAppDirs(homeDir)
+	configDir := dirs.Config
+	if len(command) > 0 && command[0] == "completion" {
+		if err := runCompletionCommand(command[1:], dirs.Data, os.Stdout); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	profile, err := resolveProfile(profileFlag)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
 	}
 
 	envTemplate := `# Activity Feed Configuration
@@ -356,6 +1453,10 @@ func main() {
 GITHUB_TOKEN=
 GITHUB_USERNAME=
 
+# Optional: command that prints the token (used when GITHUB_TOKEN is empty)
+# Example: GITHUB_TOKEN_COMMAND=op read op://vault/github/token
+GITHUB_TOKEN_COMMAND=
+
 	# Optional in GitHub online mode
 	# Comma-separated owner/repo values
 	# Example: owner/repo,owner/another-repo
@@ -370,6 +1471,9 @@ GITHUB_USERNAME=
 GITLAB_TOKEN=
 # Optional alternative token variable supported by the app
 GITLAB_ACTIVITY_TOKEN=
+# Optional: command that prints the token (used when no token is set)
+# Example: GITLAB_TOKEN_COMMAND=op read op://vault/gitlab/token
+GITLAB_TOKEN_COMMAND=
 
 # Optional username (the app can also resolve current user via API)
 GITLAB_USERNAME=
@@ -389,12 +1493,48 @@ GITLAB_BASE_URL=https://gitlab.com
 
 	# Legacy fallback when platform-specific vars are unset
 	ALLOWED_REPOS=
+
+	# Optional: repos to skip even when otherwise allowed
+	# GITHUB_EXCLUDED_REPOS / GITLAB_EXCLUDED_REPOS take precedence over EXCLUDED_REPOS
+	EXCLUDED_REPOS=
+
+	# Optional: other usernames that count as you (old account names, your bots)
+	# GITHUB_USER_ALIASES / GITLAB_USER_ALIASES add to USER_ALIASES per platform
+	USER_ALIASES=
+
+# Optional: named profiles selected with --profile NAME (or GIT_FEED_PROFILE).
+# Profile settings override the values above and each profile gets its own
+# cache database (e.g. gitlab-work.db).
+# [profile.work]
+# GITLAB_HOST=https://gitlab.example.com
+# GITLAB_TOKEN_COMMAND=op read op://work/gitlab/token
+# GITLAB_ALLOWED_REPOS=team/service
+
+# Optional: color overrides, e.g. for light terminals. Sections must come
+# after all KEY=value settings. Colors: black, red, green, yellow, blue,
+# magenta, cyan, white, their hi- variants, plus bold/faint/italic/underline.
+# [colors]
+# label.involved = black
+# state.open = hi-green bold
+# users = blue, magenta, cyan
 	`
 
 	if err := os.MkdirAll(configDir, 0o755); err != nil {
 		fmt.Printf("Error: Could not create config directory %s: %v\n", configDir, err)
 		os.Exit(1)
 	}
+	if err := os.MkdirAll(dirs.Data, 0o755); err != nil {
+		fmt.Printf("Error: Could not create data directory %s: %v\n", dirs.Data, err)
+		os.Exit(1)
+	}
+
+	if len(command) > 0 && command[0] == "config" {
+		if err := runConfigCommand(command[1:], filepath.Join(configDir, "config.yaml"), profile, platform, os.Stdout); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
 
 	envPath := filepath.Join(configDir, ".env")
 	if _, err := os.Stat(envPath); os.IsNotExist(err) {
@@ -403,26 +1543,137 @@ GITLAB_BASE_URL=https://gitlab.com
 		}
 	}
 
+	configFilePath := filepath.Join(configDir, "config.yaml")
+	if len(command) > 0 && command[0] == "doctor" {
+		if len(command) > 1 {
+			fmt.Printf("Error: doctor does not take arguments (got %q)\n", command[1:])
+			os.Exit(1)
+		}
+		opts := doctorOptions{
+			EnvPath:      envPath,
+			ConfigPath:   configFilePath,
+			DataDir:      dirs.Data,
+			Profile:      profile,
+			AllowedRepos: allowedReposFlag,
+			Transport:    transportOptions{proxy: proxyFlag, caCert: caCertFlag, insecureSkipVerify: insecureSkipVerify},
+		}
+		if err := runDoctor(runCtx, opts, os.Stdout); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	fileConfig, err := loadConfigFile(configFilePath)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	fileConfig, fileProfileFound := fileConfig.withProfile(profile)
+	repoOptions, err := parseRepoOptions(fileConfig.Repos)
+	if err != nil {
+		fmt.Printf("Error: %s: %v\n", configFilePath, err)
+		os.Exit(1)
+	}
+
+	// Precedence: environment, then config.yaml, then the .env profile
+	// section, then the rest of .env.
+	applyConfigFileEnv(fileConfig)
+	if profile != "" {
+		envProfileFound, err := applyEnvFileProfile(envPath, profile)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if !envProfileFound && !fileProfileFound {
+			fmt.Printf("Error: profile %q not found (add a [profile.%s] section to %s or a profiles.%s entry to %s)\n", profile, profile, envPath, profile, configFilePath)
+			os.Exit(1)
+		}
+	}
 	_ = loadEnvFile(envPath)
+	applyColorMode(noColor || outputPath != "")
+	theme := mustLoadColorTheme(envPath, fileConfig.Colors.colorEntries())
 
-	allowedReposStr := resolveAllowedRepos(platform, allowedReposFlag)
+	slaTargets := mustParseSLATargets(slaFlag)
+	location := mustLoadLocation(tzFlag)
+	since, until := mustResolveFeedWindow(timeRange, sinceFlag, untilFlag, standup, digest, location)
 
-	var allowedRepos map[string]bool
-	if allowedReposStr != "" {
-		allowedRepos = make(map[string]bool)
-		repos := strings.Split(allowedReposStr, ",")
-		for _, repo := range repos {
-			repo = strings.TrimSpace(repo)
-			if repo != "" {
-				allowedRepos[repo] = true
+	allowedReposStr := resolveAllowedRepos(platform, allowedReposFlag)
+	if allowedReposStr == "" && !noRepoDetect {
+		if remote, ok := originRemote("."); ok {
+			if repo, ok := detectRepoScope(platform, remote); ok {
+				allowedReposStr = repo
+				if debugMode {
+					fmt.Printf("Scoped to %s from the origin remote of the current git repository\n", repo)
+				}
+			} else if platform == "gitlab" && remote.Host != "github.com" && strings.TrimSpace(os.Getenv("GITLAB_HOST")) == "" && strings.TrimSpace(os.Getenv("GITLAB_BASE_URL")) == "" {
+				fmt.Fprintf(os.Stderr, "Note: the origin remote is on %s, not gitlab.com; set GITLAB_HOST=https://%s to scope the feed to %s\n", remote.Host, remote.Host, remote.Path)
 			}
 		}
-		if debugMode && len(allowedRepos) > 0 {
-			fmt.Printf("Filtering to allowed repositories: %v\n", allowedRepos)
+	}
+
+	allowedRepos := parseRepoList(allowedReposStr)
+	if debugMode && len(allowedRepos) > 0 {
+		fmt.Printf("Filtering to allowed repositories: %v\n", allowedRepos)
+	}
+
+	excludedRepos := parseRepoList(resolveExcludedRepos(platform, excludedReposFlag))
+	if debugMode && len(excludedRepos) > 0 {
+		fmt.Printf("Excluding repositories: %v\n", excludedRepos)
+	}
+
+	cacheBackend, err := resolveCacheBackend()
+	if err != nil {
+		fmt.Printf("Configuration Error: %v\n", err)
+		os.Exit(1)
+	}
+	encryptCache, err := resolveCacheEncryption()
+	if err != nil {
+		fmt.Printf("Configuration Error: %v\n", err)
+		os.Exit(1)
+	}
+	var cacheKey *[32]byte
+	if encryptCache {
+		if cacheBackend != cacheBackendBolt {
+			fmt.Println("Configuration Error: CACHE_ENCRYPTION is only supported by the bolt cache backend")
+			os.Exit(1)
+		}
+		cacheKey, err = loadCacheKey()
+		if err != nil {
+			fmt.Printf("Configuration Error: %v\n", err)
+			os.Exit(1)
 		}
 	}
 
-	dbPath := filepath.Join(configDir, dbFileName)
+	normalizedGitLabBaseURL := ""
+	if platform == "gitlab" {
+		rawGitLabHost := os.Getenv("GITLAB_HOST")
+		rawGitLabBaseURL := os.Getenv("GITLAB_BASE_URL")
+		selectedGitLabBaseURL := rawGitLabBaseURL
+		if strings.TrimSpace(rawGitLabHost) != "" {
+			selectedGitLabBaseURL = rawGitLabHost
+		}
+
+		normalizedGitLabBaseURL, err = normalizeGitLabBaseURL(selectedGitLabBaseURL)
+		if err != nil {
+			if strings.TrimSpace(selectedGitLabBaseURL) != "" {
+				fmt.Printf("Configuration Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			normalizedGitLabBaseURL, _ = normalizeGitLabBaseURL("")
+		}
+	}
+
+	// Each GitLab instance gets its own cache file, so switching GITLAB_HOST
+	// never mixes projects from different instances in --local mode.
+	cacheFilePath := func(gitlabBaseURL string) string {
+		dbFileName := profileDBFileName(platform, profile)
+		if platform == "gitlab" {
+			dbFileName = hostDBFileName(dbFileName, gitlabBaseURL)
+		}
+		return filepath.Join(dirs.Data, cacheFileName(dbFileName, cacheBackend))
+	}
+	dbPath := cacheFilePath(normalizedGitLabBaseURL)
 
 	if cleanCache {
 		fmt.Println("Cleaning database cache...")
@@ -435,15 +1686,50 @@ GITLAB_BASE_URL=https://gitlab.com
 		} else {
 			fmt.Println("No existing database cache to clean")
 		}
+		if err := os.RemoveAll(filepath.Join(dirs.Data, "http-cache")); err != nil {
+			fmt.Printf("Warning: Failed to delete HTTP cache: %v\n", err)
+		}
 	}
 
-	db, err := OpenDatabase(dbPath)
+	if len(command) > 0 && command[0] == "db" {
+		if err := runDBCommand(dbPath, cacheKey, command[1:], os.Stdout); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	db, err := OpenDatabase(dbPath, cacheKey)
 	if err != nil {
 		fmt.Printf("Warning: Failed to open database: %v\n", err)
 		fmt.Println("Continuing without database caching...")
 		db = nil
 	} else {
-		defer db.Close()
+		if sharesLockedCache(db) {
+			fmt.Fprintln(os.Stderr, "Note: another git-feed run is using the cache; showing a snapshot of it, and this run's cache updates are applied by the next run that opens it")
+		}
+		// A closure, since the glab host below may reopen db on another file.
+		defer func() {
+			if db != nil {
+				db.Close()
+			}
+		}()
+	}
+
+	if len(command) > 0 && command[0] == "clean" {
+		if err := runCleanCommand(db, command[1:], time.Now(), os.Stdout); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if len(command) > 0 && command[0] == "open" {
+		if err := runOpenCommand(db, command[1:], os.Stdout); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
 	}
 
 	var token string
@@ -455,26 +1741,118 @@ GITLAB_BASE_URL=https://gitlab.com
 	} else {
 		token = os.Getenv("GITHUB_TOKEN")
 	}
+	if token == "" && !localMode {
+		if command := strings.TrimSpace(os.Getenv(tokenCommandEnvVar(platform))); command != "" {
+			token, err = runTokenCommand(command)
+			if err != nil {
+				fmt.Printf("Configuration Error: %s: %v\n", tokenCommandEnvVar(platform), err)
+				os.Exit(1)
+			}
+		}
+	}
 
 	githubUsername := strings.TrimSpace(os.Getenv("GITHUB_USERNAME"))
 
-	normalizedGitLabBaseURL := ""
-	if platform == "gitlab" {
-		rawGitLabHost := os.Getenv("GITLAB_HOST")
-		rawGitLabBaseURL := os.Getenv("GITLAB_BASE_URL")
-		selectedGitLabBaseURL := rawGitLabBaseURL
-		if strings.TrimSpace(rawGitLabHost) != "" {
-			selectedGitLabBaseURL = rawGitLabHost
+	if strings.TrimSpace(caCertFlag) == "" {
+		caCertFlag = strings.TrimSpace(os.Getenv("GITLAB_CA_CERT"))
+	}
+	transport, err := newHTTPTransport(transportOptions{proxy: proxyFlag, caCert: caCertFlag, insecureSkipVerify: insecureSkipVerify})
+	if err != nil {
+		fmt.Printf("Configuration Error: %v\n", err)
+		os.Exit(1)
+	}
+	if insecureSkipVerify {
+		fmt.Fprintln(os.Stderr, "Warning: TLS certificate verification is disabled (--insecure-skip-verify); use --ca-cert instead")
+	}
+	config.transport = transport
+	if httpCache {
+		httpCacheDir := filepath.Join(dirs.Data, "http-cache")
+		if err := pruneHTTPCache(httpCacheDir, httpCacheMaxAge); err != nil {
+			fmt.Printf("Warning: HTTP cache disabled: %v\n", err)
+		} else {
+			config.httpCacheDir = httpCacheDir
+			config.cacheKey = cacheKey
 		}
+	}
 
-		normalizedGitLabBaseURL, err = normalizeGitLabBaseURL(selectedGitLabBaseURL)
+	if len(command) > 0 && command[0] == "auth" {
+		if err := runAuthCommand(command[1:], platform, normalizedGitLabBaseURL, os.Stdin, os.Stdout); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if token == "" && !localMode {
+		stored, err := keyringToken(platform, normalizedGitLabBaseURL)
+		if err != nil && debugMode {
+			fmt.Printf("Could not read token from the system keyring: %v\n", err)
+		}
+		token = stored
+	}
+
+	// useGitLabHostCache switches to the cache of a GitLab host chosen after
+	// the cache was opened (glab credentials or the setup wizard).
+	useGitLabHostCache := func(baseURL string) {
+		hostDBPath := cacheFilePath(baseURL)
+		if db == nil || hostDBPath == dbPath {
+			return
+		}
+		db.Close()
+		dbPath = hostDBPath
+		db, err = OpenDatabase(dbPath, cacheKey)
 		if err != nil {
-			if strings.TrimSpace(selectedGitLabBaseURL) != "" {
-				fmt.Printf("Configuration Error: %v\n", err)
+			fmt.Printf("Warning: Failed to open database: %v\n", err)
+			fmt.Println("Continuing without database caching...")
+			db = nil
+		}
+	}
+
+	if platform == "gitlab" && token == "" && !localMode {
+		hostConfigured := strings.TrimSpace(os.Getenv("GITLAB_HOST")) != "" || strings.TrimSpace(os.Getenv("GITLAB_BASE_URL")) != ""
+		if creds, ok := loadGlabCredentials(glabConfigPath(), normalizedGitLabBaseURL, hostConfigured); ok {
+			if offerGlabCredentials(creds, term.IsTerminal(int(os.Stdin.Fd())), os.Stdin) {
+				token = creds.Token
+				normalizedGitLabBaseURL = creds.BaseURL
+				os.Setenv("GITLAB_HOST", creds.BaseURL)
+				if debugMode {
+					fmt.Printf("Using glab CLI token for %s\n", creds.Host)
+				}
+				useGitLabHostCache(creds.BaseURL)
+			}
+		}
+	}
+
+	if platform == "gitlab" && token == "" && !localMode && len(command) == 0 {
+		if offerGitLabSetup(os.Stdin, term.IsTerminal(int(os.Stdin.Fd())) && stdoutIsTerminal()) {
+			defaultHost := strings.TrimSuffix(normalizedGitLabBaseURL, "/api/v4")
+			setup, err := runGitLabSetupWizard(runCtx, os.Stdin, os.Stdout, configFilePath, profile, defaultHost, allowedReposStr)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
 				os.Exit(1)
 			}
+			token = setup.Token
+			normalizedGitLabBaseURL = setup.BaseURL
+			os.Setenv("GITLAB_HOST", setup.Host)
+			os.Setenv("GITLAB_USERNAME", setup.Username)
+			allowedRepos = parseRepoList(strings.Join(setup.AllowedRepos, ","))
+			useGitLabHostCache(setup.BaseURL)
+		}
+	}
 
-			normalizedGitLabBaseURL, _ = normalizeGitLabBaseURL("")
+	if db != nil {
+		retention, enabled, err := resolveCacheRetention()
+		if err != nil {
+			fmt.Printf("Configuration Error: %v\n", err)
+			os.Exit(1)
+		}
+		if enabled {
+			windowStart := since
+			if windowStart.IsZero() {
+				windowStart = time.Now().Add(-timeRange)
+			}
+			pruned := startAutoPrune(db, retention, windowStart, time.Now(), debugMode)
+			defer func() { <-pruned }()
 		}
 	}
 
@@ -496,19 +1874,68 @@ GITLAB_BASE_URL=https://gitlab.com
 		}
 		gitlabClient = client
 
-		currentUser, _, err := gitlabClient.Users.CurrentUser(gitlab.WithContext(context.Background()))
+		capabilities, err := detectGitLabCapabilities(runCtx, gitlabClient)
 		if err != nil {
-			fmt.Printf("Configuration Error: failed to fetch GitLab current user: %v\n", err)
+			fmt.Printf("Configuration Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := capabilities.requireReadAPI(gitlabClient); err != nil {
+			fmt.Printf("Configuration Error: %v\n", err)
 			os.Exit(1)
 		}
-		gitlabUsername = strings.TrimSpace(currentUser.Username)
-		gitlabUserID = currentUser.ID
+		if capabilities.expiresSoon(time.Now(), 7*24*time.Hour) {
+			fmt.Printf("Warning: GitLab token expires on %s\n", capabilities.ExpiresAt.Format("2006-01-02"))
+		}
+		if disabled := capabilities.disabledFeatures(); len(disabled) > 0 {
+			fmt.Printf("Warning: GitLab token scopes [%s] do not cover every feature. Disabled:\n", strings.Join(capabilities.Scopes, ", "))
+			for _, feature := range disabled {
+				fmt.Printf("  - %s\n", feature)
+			}
+		}
+
+		if len(command) > 0 && !capabilities.Write {
+			fmt.Printf("Configuration Error: the %s command needs a GitLab token with the api scope\n", command[0])
+			os.Exit(1)
+		}
+
+		if capabilities.CurrentUser {
+			currentUser, _, err := gitlabClient.Users.CurrentUser(gitlab.WithContext(runCtx))
+			if err != nil {
+				fmt.Printf("Configuration Error: failed to fetch GitLab current user: %v\n", err)
+				os.Exit(1)
+			}
+			gitlabUsername = strings.TrimSpace(currentUser.Username)
+			gitlabUserID = currentUser.ID
+		} else {
+			gitlabUsername = strings.TrimSpace(os.Getenv("GITLAB_USERNAME"))
+			if gitlabUsername == "" {
+				gitlabUsername = strings.TrimSpace(os.Getenv("GITLAB_USER"))
+			}
+			if gitlabUsername == "" {
+				fmt.Println("Configuration Error: GitLab token cannot read the current user; set GITLAB_USERNAME or add the read_user scope")
+				os.Exit(1)
+			}
+		}
 		if gitlabUsername == "" {
 			fmt.Println("Configuration Error: GitLab current user has empty username")
 			os.Exit(1)
 		}
 	}
 
+	if len(command) > 0 && command[0] == "merge" {
+		if gitlabClient == nil {
+			fmt.Printf("Configuration Error: the merge command requires GITLAB_TOKEN, GITLAB_ACTIVITY_TOKEN, GITLAB_TOKEN_COMMAND or `auth login` (see %s)\n", envPath)
+			os.Exit(1)
+		}
+		config.debugMode = debugMode
+		config.ctx = runCtx
+		if err := runGitLabMergeCommand(config.ctx, gitlabClient, command[1:], os.Stdin); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Validate configuration
 	if err := validateConfig(platform, token, githubUsername, localMode, envPath, allowedRepos); err != nil {
 		fmt.Printf("Configuration Error: %v\n\n", err)
@@ -522,7 +1949,15 @@ GITLAB_BASE_URL=https://gitlab.com
 		} else {
 			fmt.Println("Monitoring GitHub pull request and issue activity")
 		}
-		fmt.Printf("Showing items from the last %v\n", timeRange)
+		if sinceFlag != "" || untilFlag != "" || standup || digest != "" {
+			end := "now"
+			if !until.IsZero() {
+				end = until.Format(time.RFC3339)
+			}
+			fmt.Printf("Showing items active between %s and %s\n", since.Format(time.RFC3339), end)
+		} else {
+			fmt.Printf("Showing items from the last %v\n", timeRange)
+		}
 	}
 	if debugMode {
 		fmt.Println("Debug mode enabled")
@@ -534,14 +1969,181 @@ GITLAB_BASE_URL=https://gitlab.com
 	config.githubToken = token
 	config.githubUsername = githubUsername
 	config.showLinks = showLinks
+	config.showBranches = showBranches
 	config.timeRange = timeRange
 	config.gitlabUsername = gitlabUsername
+	config.userAliases = resolveUserAliases(platform)
 	config.allowedRepos = allowedRepos
+	config.excludedRepos = excludedRepos
 	config.db = db
-	config.ctx = context.Background()
+	config.ctx = runCtx
 	config.gitlabClient = gitlabClient
+	config.gitlabNotes = newGitLabNoteCache()
+	config.groupBy = groupBy
+	config.states = states
+	config.countOnly = countOnly
+	config.twoColumn = twoColumn
+	config.stream = stream
+	config.noRecency = noRecency
+	config.theme = theme
+	config.repoOptions = repoOptions
+	config.apiBackend = apiFlag
+	config.githubSource = githubSource
+	config.lineWidth = itemLineWidth(wide || outputPath != "")
+	// With --output, stdout is the file; progress and warnings go to stderr.
+	config.quiet = countOnly || outputPath != "" || (len(command) > 0 && (command[0] == "export" || command[0] == "sync" || command[0] == "web"))
+	config.interactive = stdoutIsTerminal()
+	config.platform = platform
+	config.slaTargets = slaTargets
+	config.dueSoon = dueSoon
+	config.minWeight = minWeight
+	config.location = location
+	config.since = since
+	config.until = until
+	config.chunkedListings = time.Since(earliestRepoCutoff(activityCutoff())) > gitLabChunkedWindow
+
+	if len(command) > 0 && command[0] == "share" {
+		if err := runGitLabShareCommand(config.ctx, gitlabClient, command[1:]); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		displayErrorBudget()
+		return
+	}
+
+	if len(command) > 0 && command[0] == "report" {
+		if err := runGitLabReportCommand(config.ctx, gitlabClient, command[1:], time.Now(), os.Stdout); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		displayErrorBudget()
+		return
+	}
 
-	fetchAndDisplayActivity(platform)
+	if len(command) > 0 && command[0] == "web" {
+		// The dashboard reopens the cache for each refresh, so a `sync` cron
+		// job can write to it in between.
+		if db != nil {
+			db.Close()
+			db, config.db = nil, nil
+		}
+		openCache := func() (Database, error) { return OpenDatabase(dbPath, cacheKey) }
+		if err := runWebCommand(platform, command[1:], openCache, os.Stdout); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if dryRun {
+		if err := runGitLabDryRun(config.ctx, newGitLabPlatform(), activityCutoff(), os.Stdout); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		displayErrorBudget()
+		return
+	}
+
+	if len(command) > 0 && command[0] == "sync" {
+		if err := runSyncCommand(platform, command[1:], os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			logRun(slog.LevelError, "run failed", "error", err.Error())
+			os.Exit(1)
+		}
+		displayErrorBudget()
+		return
+	}
+
+	if localMode {
+		displayCacheFreshness(time.Now())
+	}
+
+	actionable := false
+	render := func(out io.Writer) error {
+		var err error
+		actionable, err = fetchAndDisplayActivity(platform, out)
+		return err
+	}
+	if len(teamUsers) > 0 {
+		render = func(out io.Writer) error {
+			var err error
+			actionable, err = fetchAndDisplayTeamActivity(platform, teamUsers, out)
+			return err
+		}
+	}
+	if standup {
+		render = func(out io.Writer) error {
+			var err error
+			actionable, err = fetchAndDisplayStandup(platform, out)
+			return err
+		}
+	}
+	if digest != "" {
+		render = func(out io.Writer) error {
+			var err error
+			actionable, err = fetchAndDisplayDigest(platform, out)
+			return err
+		}
+	}
+	if len(command) > 0 && command[0] == "export" {
+		render = func(out io.Writer) error { return runExportCommand(platform, dirs.Data, command[1:], out) }
+	}
+	if len(command) > 0 && command[0] == "pick" {
+		render = func(io.Writer) error { return runPickCommand(platform, command[1:]) }
+	}
+	if outputPath != "" {
+		if err := writeOutputFile(outputPath, render); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			logRun(slog.LevelError, "run failed", "error", err.Error())
+			os.Exit(1)
+		}
+		displayErrorBudget()
+		if actionable {
+			exitCode = exitActionable
+		}
+		return
+	}
+
+	if len(command) > 0 && command[0] == "export" {
+		if err := render(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			logRun(slog.LevelError, "run failed", "error", err.Error())
+			os.Exit(1)
+		}
+		displayErrorBudget()
+		return
+	}
+
+	if err := render(os.Stdout); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		logRun(slog.LevelError, "run failed", "error", err.Error())
+		exitCode = exitError
+	}
+	displayErrorBudget()
+	if actionable {
+		exitCode = exitActionable
+	}
+}
+
+// withRunTimeout bounds the run with --timeout; zero means no limit. Errors
+// from the deadline name the flag through timeoutError.
+func withRunTimeout(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(parent)
+	}
+	return context.WithTimeoutCause(parent, timeout, fmt.Errorf("the run did not finish within --timeout %s", timeout))
+}
+
+// timeoutError puts the --timeout cause in front of an error caused by the
+// run's deadline, which otherwise reads "context deadline exceeded".
+func timeoutError(ctx context.Context, err error) error {
+	if !errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	if cause := context.Cause(ctx); cause != nil && !errors.Is(cause, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", cause, err)
+	}
+	return err
 }
 
 func validateConfig(platform, token, githubUsername string, localMode bool, envPath string, allowedRepos map[string]bool) error {
@@ -552,14 +2154,14 @@ func validateConfig(platform, token, githubUsername string, localMode bool, envP
 	switch platform {
 	case "gitlab":
 		if token == "" {
-			return fmt.Errorf("token is required for GitLab API mode.\n\nTo fix this:\n  - Set GITLAB_TOKEN or GITLAB_ACTIVITY_TOKEN\n  - Or add it to %s", envPath)
+			return fmt.Errorf("token is required for GitLab API mode.\n\nTo fix this:\n  - Set GITLAB_TOKEN or GITLAB_ACTIVITY_TOKEN\n  - Or set GITLAB_TOKEN_COMMAND to a command that prints the token\n  - Or run `git-feed --platform gitlab auth login` to store it in the system keyring\n  - Or log in with the glab CLI (`glab auth login`)\n  - Or add it to %s", envPath)
 		}
 		if len(allowedRepos) == 0 {
-			return fmt.Errorf("GITLAB_ALLOWED_REPOS is required for GitLab API mode to keep API usage bounded.\n\nTo fix this:\n  - Set GITLAB_ALLOWED_REPOS with group[/subgroup]/repo paths\n  - Example: GITLAB_ALLOWED_REPOS=team/service,platform/backend/git-feed\n  - Or use legacy fallback ALLOWED_REPOS\n  - Or add it to %s", envPath)
+			return fmt.Errorf("GITLAB_ALLOWED_REPOS is required for GitLab API mode to keep API usage bounded.\n\nTo fix this:\n  - Set GITLAB_ALLOWED_REPOS with group[/subgroup]/repo paths\n  - Example: GITLAB_ALLOWED_REPOS=team/service,platform/backend/git-feed\n  - Or use legacy fallback ALLOWED_REPOS\n  - Or run git-feed inside a git checkout of the project (its origin remote is used)\n  - Or add it to %s", envPath)
 		}
 	case "github":
 		if token == "" {
-			return fmt.Errorf("token is required for GitHub API mode.\n\nTo fix this:\n  - Set GITHUB_TOKEN\n  - Or add it to %s", envPath)
+			return fmt.Errorf("token is required for GitHub API mode.\n\nTo fix this:\n  - Set GITHUB_TOKEN\n  - Or set GITHUB_TOKEN_COMMAND to a command that prints the token\n  - Or run `git-feed auth login` to store it in the system keyring\n  - Or add it to %s", envPath)
 		}
 		if githubUsername == "" {
 			return fmt.Errorf("username is required for GitHub API mode.\n\nTo fix this:\n  - Set GITHUB_USERNAME\n  - Or add it to %s", envPath)
@@ -570,14 +2172,387 @@ func validateConfig(platform, token, githubUsername string, localMode bool, envP
 	return nil
 }
 
-func fetchAndDisplayActivity(platform string) {
-	switch platform {
-	case "gitlab":
-		fetchAndDisplayGitLabActivity()
-	case "github":
-		fetchAndDisplayGitHubActivity()
+// formatErrorBudget summarizes degraded behavior that is otherwise only visible
+// with --debug: API calls that failed and were skipped, and cache writes that
+// failed. Skipped calls mean the feed itself may be missing data; failed
+// writes only affect what --local will show later.
+func formatErrorBudget(apiErrors, dbErrors int32) string {
+	if apiErrors == 0 && dbErrors == 0 {
+		return "Error budget: all API calls and cache writes succeeded"
+	}
+
+	plural := func(n int32, word string) string {
+		if n == 1 {
+			return fmt.Sprintf("%d %s", n, word)
+		}
+		return fmt.Sprintf("%d %ss", n, word)
+	}
+	line := fmt.Sprintf("Error budget: %s skipped, %s failed", plural(apiErrors, "failed API call"), plural(dbErrors, "cache write"))
+	if apiErrors > 0 {
+		return line + " " + symbols.Dash + " results may be partial (rerun with --debug for details)"
+	}
+	return line + " " + symbols.Dash + " results are complete, but the offline cache may be missing items"
+}
+
+// staleCacheAge is how old the last online sync can be before --local results
+// are flagged as stale.
+const staleCacheAge = 24 * time.Hour
+
+// formatCacheFreshness describes how long ago the cache was last synced online.
+func formatCacheFreshness(lastSync, now time.Time) string {
+	if lastSync.IsZero() {
+		return "Cache has never been updated online; run once without --local to fill it"
+	}
+	return fmt.Sprintf("Cache last updated %s ago", formatSLADuration(now.Sub(lastSync)))
+}
+
+// displayCacheFreshness tells --local runs how old the cached results are,
+// in yellow once they are older than staleCacheAge. Quiet modes write it to
+// stderr to keep stdout clean.
+func displayCacheFreshness(now time.Time) {
+	if config.db == nil {
+		return
+	}
+	lastSync, err := config.db.LastSync()
+	if err != nil {
+		recordDBWarning("Failed to read last sync time: %v", err)
+		return
+	}
+
+	var out io.Writer = os.Stdout
+	if config.quiet {
+		out = os.Stderr
+	}
+	line := formatCacheFreshness(lastSync, now)
+	if lastSync.IsZero() || now.Sub(lastSync) > staleCacheAge {
+		line = color.New(color.FgYellow).Sprint(line)
+	}
+	fmt.Fprintln(out, line)
+}
+
+// displayErrorBudget prints the error budget line when anything degraded (or
+// always with --debug). Quiet modes write it to stderr to keep stdout clean.
+func displayErrorBudget() {
+	apiErrors, dbErrors := config.apiErrorCount.Load(), config.dbErrorCount.Load()
+	if apiErrors == 0 && dbErrors == 0 && !config.debugMode {
+		return
+	}
+
+	var out io.Writer = os.Stdout
+	if config.quiet {
+		out = os.Stderr
+	}
+	line := formatErrorBudget(apiErrors, dbErrors)
+	if apiErrors > 0 || dbErrors > 0 {
+		line = color.New(color.FgYellow).Sprint(line)
+	}
+	fmt.Fprintf(out, "\n%s\n", line)
+	if len(config.failedProjects) > 0 {
+		fmt.Fprintf(out, "%s\n", color.New(color.FgYellow).Sprint("Warnings: the feed is missing items of these projects"))
+		for _, failure := range config.failedProjects {
+			fmt.Fprintf(out, "  %s: %v\n", failure.Project, failure.Err)
+		}
+	}
+	if config.debugMode {
+		writeAPICallSummary(out, config.apiCalls.snapshot())
+	}
+}
+
+func displayActivities(out io.Writer, activities []PRActivity, issueActivities []IssueActivity) {
+	if config.countOnly {
+		displayCountOnly(out, activities, issueActivities)
+		return
+	}
+
+	activities, issueActivities = filterActivitiesByState(activities, issueActivities, config.states)
+	if len(activities) == 0 && len(issueActivities) == 0 {
+		fmt.Fprintln(out, "No open activity found")
+		return
+	}
+
+	sort.Slice(activities, func(i, j int) bool {
+		return activities[i].UpdatedAt.After(activities[j].UpdatedAt)
+	})
+	sort.Slice(issueActivities, func(i, j int) bool {
+		return issueActivities[i].UpdatedAt.After(issueActivities[j].UpdatedAt)
+	})
+
+	displaySummary(out, summarizeActivities(activities, issueActivities))
+
+	switch config.groupBy {
+	case "project":
+		displayActivitiesByProject(out, activities, issueActivities)
+	case "label":
+		displayActivitiesByLabel(out, activities, issueActivities)
 	default:
-		fmt.Printf("Unsupported platform: %s\n", platform)
+		displayActivitiesByState(out, activities, issueActivities)
+	}
+}
+
+func displayActivitiesByState(out io.Writer, activities []PRActivity, issueActivities []IssueActivity) {
+	var openPRs, closedPRs, mergedPRs []PRActivity
+	for _, activity := range activities {
+		if activity.MR.State == "closed" {
+			if activity.MR.Merged {
+				mergedPRs = append(mergedPRs, activity)
+			} else {
+				closedPRs = append(closedPRs, activity)
+			}
+		} else {
+			openPRs = append(openPRs, activity)
+		}
+	}
+
+	var openIssues, closedIssues []IssueActivity
+	for _, issue := range issueActivities {
+		if issue.Issue.State == "closed" {
+			closedIssues = append(closedIssues, issue)
+		} else {
+			openIssues = append(openIssues, issue)
+		}
+	}
+
+	// With --two-column on a wide terminal, open pull requests and open issues
+	// share the first rows side by side and closed sections follow as usual.
+	width := 0
+	if config.twoColumn && len(openPRs) > 0 && len(openIssues) > 0 {
+		width = terminalWidth()
+	}
+	twoColumn := width >= twoColumnMinWidth
+
+	if twoColumn {
+		titleColor := color.New(color.FgHiGreen, color.Bold)
+		columnWidth := sideBySideColumnWidth(width)
+		left := []string{titleColor.Sprint("OPEN PULL REQUESTS:"), "------------------------------------------"}
+		headings := newRecencyHeadings()
+		for _, activity := range openPRs {
+			if heading, ok := headings.next(activity.UpdatedAt); ok {
+				left = append(left, heading)
+			}
+			mrConfig := mergeRequestDisplayConfig(activity.Label, activity.Owner, activity.Repo, activity.MR, activity.HasUpdates)
+			mrConfig.MaxWidth = columnWidth
+			numberItem(&mrConfig)
+			left = append(left, formatItem(mrConfig)...)
+			for _, issue := range activity.Issues {
+				issueConfig := issueDisplayConfig(issue.Label, issue.Owner, issue.Repo, issue.Issue, true, issue.HasUpdates)
+				issueConfig.MaxWidth = columnWidth
+				numberItem(&issueConfig)
+				left = append(left, formatItem(issueConfig)...)
+			}
+		}
+		right := []string{titleColor.Sprint("OPEN ISSUES:"), "------------------------------------------"}
+		headings = newRecencyHeadings()
+		for _, issue := range openIssues {
+			if heading, ok := headings.next(issue.UpdatedAt); ok {
+				right = append(right, heading)
+			}
+			issueConfig := issueDisplayConfig(issue.Label, issue.Owner, issue.Repo, issue.Issue, false, issue.HasUpdates)
+			issueConfig.MaxWidth = columnWidth
+			numberItem(&issueConfig)
+			right = append(right, formatItem(issueConfig)...)
+		}
+		for _, line := range sideBySide(left, right, width) {
+			fmt.Fprintln(out, line)
+		}
+	} else if len(openPRs) > 0 {
+		titleColor := color.New(color.FgHiGreen, color.Bold)
+		fmt.Fprintln(out, titleColor.Sprint("OPEN PULL REQUESTS:"))
+		fmt.Fprintln(out, "------------------------------------------")
+		headings := newRecencyHeadings()
+		for _, activity := range openPRs {
+			if heading, ok := headings.next(activity.UpdatedAt); ok {
+				fmt.Fprintln(out, heading)
+			}
+			displayMergeRequestWithIssues(out, activity)
+		}
+	}
+
+	if len(closedPRs) > 0 || len(mergedPRs) > 0 {
+		fmt.Fprintln(out)
+		titleColor := color.New(color.FgHiRed, color.Bold)
+		fmt.Fprintln(out, titleColor.Sprint("CLOSED/MERGED PULL REQUESTS:"))
+		fmt.Fprintln(out, "------------------------------------------")
+		closedOrMerged := append(append([]PRActivity{}, mergedPRs...), closedPRs...)
+		headings := newRecencyHeadings()
+		if headings.enabled {
+			// Merged-before-closed would restart the recency buckets halfway
+			// through the section, so order the whole section by update time.
+			sort.SliceStable(closedOrMerged, func(i, j int) bool {
+				return closedOrMerged[i].UpdatedAt.After(closedOrMerged[j].UpdatedAt)
+			})
+		}
+		for _, activity := range closedOrMerged {
+			if heading, ok := headings.next(activity.UpdatedAt); ok {
+				fmt.Fprintln(out, heading)
+			}
+			displayMergeRequestWithIssues(out, activity)
+		}
+	}
+
+	if len(openIssues) > 0 && !twoColumn {
+		fmt.Fprintln(out)
+		titleColor := color.New(color.FgHiGreen, color.Bold)
+		fmt.Fprintln(out, titleColor.Sprint("OPEN ISSUES:"))
+		fmt.Fprintln(out, "------------------------------------------")
+		headings := newRecencyHeadings()
+		for _, issue := range openIssues {
+			if heading, ok := headings.next(issue.UpdatedAt); ok {
+				fmt.Fprintln(out, heading)
+			}
+			displayIssue(out, issue.Label, issue.Owner, issue.Repo, issue.Issue, false, issue.HasUpdates)
+		}
+	}
+
+	if len(closedIssues) > 0 {
+		fmt.Fprintln(out)
+		titleColor := color.New(color.FgHiRed, color.Bold)
+		fmt.Fprintln(out, titleColor.Sprint("CLOSED ISSUES:"))
+		fmt.Fprintln(out, "------------------------------------------")
+		headings := newRecencyHeadings()
+		for _, issue := range closedIssues {
+			if heading, ok := headings.next(issue.UpdatedAt); ok {
+				fmt.Fprintln(out, heading)
+			}
+			displayIssue(out, issue.Label, issue.Owner, issue.Repo, issue.Issue, false, issue.HasUpdates)
+		}
+	}
+}
+
+func displayActivitiesByProject(out io.Writer, activities []PRActivity, issueActivities []IssueActivity) {
+	prsByProject := make(map[string][]PRActivity)
+	issuesByProject := make(map[string][]IssueActivity)
+	seenProjects := make(map[string]bool)
+	var projects []string
+
+	for _, activity := range activities {
+		project := projectDisplayPath(activity.Owner, activity.Repo)
+		if !seenProjects[project] {
+			seenProjects[project] = true
+			projects = append(projects, project)
+		}
+		prsByProject[project] = append(prsByProject[project], activity)
+	}
+	for _, issue := range issueActivities {
+		project := projectDisplayPath(issue.Owner, issue.Repo)
+		if !seenProjects[project] {
+			seenProjects[project] = true
+			projects = append(projects, project)
+		}
+		issuesByProject[project] = append(issuesByProject[project], issue)
+	}
+
+	sort.Slice(projects, func(i, j int) bool {
+		return strings.ToLower(projects[i]) < strings.ToLower(projects[j])
+	})
+
+	titleColor := color.New(color.FgHiCyan, color.Bold)
+	badgeColor := color.New(color.Faint)
+	for i, project := range projects {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		header := titleColor.Sprint(project + ":")
+		for _, badge := range config.projectBadges[strings.ToLower(project)] {
+			header += " " + badgeColor.Sprintf("[%s]", badge)
+		}
+		fmt.Fprintln(out, header)
+		fmt.Fprintln(out, "------------------------------------------")
+		displayGroupItems(out, prsByProject[project], issuesByProject[project])
+	}
+}
+
+// labelGroupOrder is the triage order used by --group-by label: items waiting
+// on the user come first, their own work last.
+var labelGroupOrder = []string{
+	"Review Requested",
+	"Approval Requested",
+	"Assigned",
+	"Mentioned",
+	"Commented",
+	"Reviewed",
+	"Authored",
+	"Involved",
+	"Recent Activity",
+}
+
+func labelGroupRank(label string) int {
+	for i, candidate := range labelGroupOrder {
+		if candidate == label {
+			return i
+		}
+	}
+	return len(labelGroupOrder)
+}
+
+func displayActivitiesByLabel(out io.Writer, activities []PRActivity, issueActivities []IssueActivity) {
+	prsByLabel := make(map[string][]PRActivity)
+	issuesByLabel := make(map[string][]IssueActivity)
+	seenLabels := make(map[string]bool)
+	var labels []string
+
+	for _, activity := range activities {
+		if !seenLabels[activity.Label] {
+			seenLabels[activity.Label] = true
+			labels = append(labels, activity.Label)
+		}
+		prsByLabel[activity.Label] = append(prsByLabel[activity.Label], activity)
+	}
+	for _, issue := range issueActivities {
+		if !seenLabels[issue.Label] {
+			seenLabels[issue.Label] = true
+			labels = append(labels, issue.Label)
+		}
+		issuesByLabel[issue.Label] = append(issuesByLabel[issue.Label], issue)
+	}
+
+	sort.Slice(labels, func(i, j int) bool {
+		rankI, rankJ := labelGroupRank(labels[i]), labelGroupRank(labels[j])
+		if rankI != rankJ {
+			return rankI < rankJ
+		}
+		return labels[i] < labels[j]
+	})
+
+	for i, label := range labels {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintln(out, getLabelColor(label).Add(color.Bold).Sprint(strings.ToUpper(label)+":"))
+		fmt.Fprintln(out, "------------------------------------------")
+		displayGroupItems(out, prsByLabel[label], issuesByLabel[label])
+	}
+}
+
+// displayGroupItems prints one --group-by section: merge requests, then
+// issues, each newest first with recency subheadings like the state sections.
+func displayGroupItems(out io.Writer, activities []PRActivity, issueActivities []IssueActivity) {
+	headings := newRecencyHeadings()
+	for _, activity := range activities {
+		if heading, ok := headings.next(activity.UpdatedAt); ok {
+			fmt.Fprintln(out, heading)
+		}
+		displayMergeRequestWithIssues(out, activity)
+	}
+	headings = newRecencyHeadings()
+	for _, issue := range issueActivities {
+		if heading, ok := headings.next(issue.UpdatedAt); ok {
+			fmt.Fprintln(out, heading)
+		}
+		displayIssue(out, issue.Label, issue.Owner, issue.Repo, issue.Issue, false, issue.HasUpdates)
+	}
+}
+
+func projectDisplayPath(owner, repo string) string {
+	if repo == "" {
+		return owner
+	}
+	return owner + "/" + repo
+}
+
+func displayMergeRequestWithIssues(out io.Writer, activity PRActivity) {
+	displayMergeRequest(out, activity.Label, activity.Owner, activity.Repo, activity.MR, activity.HasUpdates)
+	for _, issue := range activity.Issues {
+		displayIssue(out, issue.Label, issue.Owner, issue.Repo, issue.Issue, true, issue.HasUpdates)
 	}
 }
 
@@ -587,18 +2562,49 @@ type DisplayConfig struct {
 	Number     int
 	Title      string
 	User       string
+	CreatedAt  time.Time
 	UpdatedAt  time.Time
+	InvolvedAt time.Time
 	WebURL     string
 	Label      string
 	HasUpdates bool
 	IsIndented bool
 	State      string
+	DueDate    time.Time
+	Weight     int
+	Assignees  []string
+	Reviewers  []string
+	Source     string
+	Badges     []string
+	Checks     string
+	MaxWidth   int
+	Labels     []ProjectLabel
+	// Index is the item's number in the feed (`open N`); 0 prints none.
+	Index int
+
+	// IsMergeRequest, SourceBranch and TargetBranch are only set for
+	// merge/pull requests.
+	IsMergeRequest bool
+	SourceBranch   string
+	TargetBranch   string
+}
+
+func displayItem(out io.Writer, cfg DisplayConfig) {
+	if cfg.MaxWidth == 0 {
+		cfg.MaxWidth = config.lineWidth
+	}
+	numberItem(&cfg)
+	for _, line := range formatItem(cfg) {
+		fmt.Fprintln(out, line)
+	}
 }
 
-func displayItem(cfg DisplayConfig) {
+// formatItem renders an item as its display lines (the item itself, plus its
+// link when --links is set) so layouts can place them without printing.
+func formatItem(cfg DisplayConfig) []string {
 	dateStr := "          "
 	if !cfg.UpdatedAt.IsZero() {
-		dateStr = cfg.UpdatedAt.Format("2006/01/02")
+		dateStr = displayTime(cfg.UpdatedAt).Format("2006/01/02")
 	}
 
 	indent := ""
@@ -613,60 +2619,221 @@ func displayItem(cfg DisplayConfig) {
 	labelColor := getLabelColor(cfg.Label)
 	userColor := getUserColor(cfg.User)
 
+	number := ""
+	if cfg.Index > 0 {
+		number = color.New(color.Faint).Sprintf("%3d ", cfg.Index)
+		linkIndent += "    "
+	}
+
 	updateIcon := ""
 	if cfg.HasUpdates {
-		updateIcon = color.New(color.FgYellow, color.Bold).Sprint("● ")
+		updateIcon = color.New(color.FgYellow, color.Bold).Sprint(symbols.Update + " ")
 	}
 
-	repoDisplay := ""
+	repoPath := ""
 	if cfg.Repo == "" {
-		repoDisplay = fmt.Sprintf("%s#%d", cfg.Owner, cfg.Number)
+		repoPath = fmt.Sprintf("%s#%d", cfg.Owner, cfg.Number)
 	} else {
-		repoDisplay = fmt.Sprintf("%s/%s#%d", cfg.Owner, cfg.Repo, cfg.Number)
+		repoPath = fmt.Sprintf("%s/%s#%d", cfg.Owner, cfg.Repo, cfg.Number)
+	}
+	repoExtras := ""
+	if icon := checkStatusIcon(cfg.Checks); icon != "" {
+		repoExtras += " " + icon
+	}
+	if cfg.Source != "" {
+		repoExtras += color.New(color.Faint).Sprintf(" (from %s)", cfg.Source)
+	}
+	if reviewers := formatReviewers(cfg.State, cfg.Reviewers); reviewers != "" {
+		repoExtras += " " + reviewers
+	}
+	for _, badge := range cfg.Badges {
+		repoExtras += " " + color.New(color.Faint).Sprintf("[%s]", badge)
+	}
+	if cfg.Weight > 0 {
+		repoExtras += " " + color.New(color.Faint).Sprintf("[weight %d]", cfg.Weight)
+	}
+	for _, label := range cfg.Labels {
+		repoExtras += " " + projectLabelColor(label.Color).Sprintf("[%s]", label.Name)
+	}
+	if status, ok := evaluateSLA(projectDisplayPath(cfg.Owner, cfg.Repo), cfg.Label, cfg.State, cfg.InvolvedAt, cfg.CreatedAt, cfg.UpdatedAt, time.Now()); ok {
+		repoExtras += " " + slaBadge(status)
+	}
+	if badge := dueBadge(cfg.State, cfg.DueDate, time.Now()); badge != "" {
+		repoExtras += " " + badge
 	}
 
-	fmt.Printf("%s%s%s %s %s %s - %s\n",
+	head := fmt.Sprintf("%s%s%s%s %s %s%s ",
+		number,
 		updateIcon,
 		indent,
 		dateStr,
 		labelColor.Sprint(strings.ToUpper(cfg.Label)),
 		userColor.Sprint(cfg.User),
-		repoDisplay,
-		cfg.Title,
+		formatAssignees(cfg.User, cfg.Assignees),
 	)
+	title := cfg.Title
+	if cfg.MaxWidth > 0 {
+		fixed := visibleWidth(head) + visibleWidth(repoExtras) + len(" - ")
+		repoPath, title = fitItemLine(fixed, repoPath, title, cfg.MaxWidth)
+	}
+	if isOverdue(cfg.State, cfg.DueDate, time.Now()) {
+		title = color.New(color.FgRed).Sprint(title)
+	}
+
+	line := head + repoPath + repoExtras + " - " + title
+	if cfg.MaxWidth > 0 {
+		line = truncateVisible(line, cfg.MaxWidth)
+	}
+	lines := []string{line}
 
+	if config.showBranches && cfg.SourceBranch != "" {
+		branches := color.New(color.Faint).Sprintf("%s%s %s %s", linkIndent, cfg.SourceBranch, symbols.Arrow, cfg.TargetBranch)
+		if cfg.MaxWidth > 0 {
+			branches = truncateVisible(branches, cfg.MaxWidth)
+		}
+		lines = append(lines, branches)
+	}
 	if config.showLinks && cfg.WebURL != "" {
-		fmt.Printf("%s🔗 %s\n", linkIndent, cfg.WebURL)
+		lines = append(lines, fmt.Sprintf("%s%s %s", linkIndent, symbols.Link, cfg.WebURL))
 	}
+	return lines
 }
 
-func displayMergeRequest(label, owner, repo string, mr MergeRequestModel, hasUpdates bool) {
-	displayItem(DisplayConfig{
-		Owner:      owner,
-		Repo:       repo,
-		Number:     mr.Number,
-		Title:      mr.Title,
-		User:       mr.UserLogin,
-		UpdatedAt:  mr.UpdatedAt,
-		WebURL:     mr.WebURL,
-		Label:      label,
-		HasUpdates: hasUpdates,
-		IsIndented: false,
-	})
+// formatAssignees is the " → alice, bob" part after the author, each name in
+// its user color. It is empty when nobody but the author is assigned.
+func formatAssignees(author string, assignees []string) string {
+	if len(assignees) == 0 || (len(assignees) == 1 && strings.EqualFold(assignees[0], author)) {
+		return ""
+	}
+	names := make([]string, 0, len(assignees))
+	for _, assignee := range assignees {
+		names = append(names, getUserColor(assignee).Sprint(assignee))
+	}
+	return color.New(color.Faint).Sprintf(" %s ", symbols.Arrow) + strings.Join(names, ", ")
+}
+
+// projectLabelColor renders a label in its forge color ("#rrggbb"), or faint
+// when the color is unknown.
+func projectLabelColor(hex string) *color.Color {
+	var r, g, b int
+	if _, err := fmt.Sscanf(strings.TrimPrefix(hex, "#"), "%02x%02x%02x", &r, &g, &b); err != nil || len(hex) != 7 {
+		return color.New(color.Faint)
+	}
+	return color.RGB(r, g, b)
+}
+
+// formatReviewers is the "(review: alice, bob)" suffix of open merge/pull
+// requests, naming whom to ping. Closed ones have none.
+func formatReviewers(state string, reviewers []string) string {
+	if len(reviewers) == 0 || state == "closed" {
+		return ""
+	}
+	names := make([]string, 0, len(reviewers))
+	for _, reviewer := range reviewers {
+		names = append(names, getUserColor(reviewer).Sprint(reviewer))
+	}
+	faint := color.New(color.Faint)
+	return faint.Sprint("(review: ") + strings.Join(names, faint.Sprint(", ")) + faint.Sprint(")")
+}
+
+func displayMergeRequest(out io.Writer, label, owner, repo string, mr MergeRequestModel, hasUpdates bool) {
+	displayItem(out, mergeRequestDisplayConfig(label, owner, repo, mr, hasUpdates))
+}
+
+func mergeRequestDisplayConfig(label, owner, repo string, mr MergeRequestModel, hasUpdates bool) DisplayConfig {
+	return DisplayConfig{
+		Owner:          owner,
+		Repo:           repo,
+		Number:         mr.Number,
+		Title:          mr.Title,
+		User:           mr.UserLogin,
+		CreatedAt:      mr.CreatedAt,
+		UpdatedAt:      mr.UpdatedAt,
+		InvolvedAt:     mr.InvolvedAt,
+		WebURL:         mr.WebURL,
+		Label:          label,
+		HasUpdates:     hasUpdates,
+		IsIndented:     false,
+		State:          mr.State,
+		Source:         mr.SourceProject,
+		Badges:         mergeSettingsBadges(mr),
+		Checks:         mr.CheckStatus,
+		Assignees:      mr.Assignees,
+		Reviewers:      mr.Reviewers,
+		Labels:         mr.ProjectLabels,
+		IsMergeRequest: true,
+		SourceBranch:   mr.SourceBranch,
+		TargetBranch:   mr.TargetBranch,
+	}
+}
+
+// checkStatusIcon renders a combined CI status as a colored ✓, ✗ or ●.
+func checkStatusIcon(status string) string {
+	switch status {
+	case checkStatusSuccess:
+		return color.New(color.FgGreen).Sprint(symbols.Passed)
+	case checkStatusFailure:
+		return color.New(color.FgRed).Sprint(symbols.Blocked)
+	case checkStatusPending:
+		return color.New(color.FgYellow).Sprint(symbols.Pending)
+	}
+	return ""
+}
+
+func mergeSettingsBadges(mr MergeRequestModel) []string {
+	if mr.State == "closed" {
+		return nil
+	}
+
+	var badges []string
+	if mr.Squash {
+		badges = append(badges, "squash")
+	}
+	switch mr.MergeMethod {
+	case "ff":
+		badges = append(badges, "ff-only")
+	case "rebase_merge":
+		badges = append(badges, "semi-linear")
+	}
+	return badges
 }
 
-func displayIssue(label, owner, repo string, issue IssueModel, indented bool, hasUpdates bool) {
-	displayItem(DisplayConfig{
+func mergeSettingsWarnings(mr MergeRequestModel) []string {
+	var warnings []string
+	if mr.Squash {
+		warnings = append(warnings, "Commits will be squashed: the squash commit message replaces the individual commit messages")
+	}
+	switch mr.MergeMethod {
+	case "ff":
+		warnings = append(warnings, "Project requires fast-forward merges: no merge commit is created and the source branch must be rebased onto the target first")
+	case "rebase_merge":
+		warnings = append(warnings, "Project uses semi-linear history: the source branch must be rebased onto the target before a merge commit is created")
+	}
+	return warnings
+}
+
+func displayIssue(out io.Writer, label, owner, repo string, issue IssueModel, indented bool, hasUpdates bool) {
+	displayItem(out, issueDisplayConfig(label, owner, repo, issue, indented, hasUpdates))
+}
+
+func issueDisplayConfig(label, owner, repo string, issue IssueModel, indented bool, hasUpdates bool) DisplayConfig {
+	return DisplayConfig{
 		Owner:      owner,
 		Repo:       repo,
 		Number:     issue.Number,
 		Title:      issue.Title,
 		User:       issue.UserLogin,
+		CreatedAt:  issue.CreatedAt,
 		UpdatedAt:  issue.UpdatedAt,
+		InvolvedAt: issue.InvolvedAt,
 		WebURL:     issue.WebURL,
 		Label:      label,
 		HasUpdates: hasUpdates,
 		IsIndented: indented,
 		State:      issue.State,
-	})
+		DueDate:    issue.DueDate,
+		Weight:     issue.Weight,
+		Assignees:  issue.Assignees,
+		Labels:     issue.ProjectLabels,
+	}
 }
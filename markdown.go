@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// renderActivitiesMarkdown renders the feed with the same sections as the
+// terminal output so it can be pasted into snippets, issues or chat.
+func renderActivitiesMarkdown(platform string, activities []PRActivity, issueActivities []IssueActivity, generatedAt time.Time) string {
+	activities, issueActivities = filterActivitiesByState(activities, issueActivities, config.states)
+	sort.Slice(activities, func(i, j int) bool {
+		return activities[i].UpdatedAt.After(activities[j].UpdatedAt)
+	})
+	sort.Slice(issueActivities, func(i, j int) bool {
+		return issueActivities[i].UpdatedAt.After(issueActivities[j].UpdatedAt)
+	})
+
+	mrName := "Pull Requests"
+	mrSeparator := "#"
+	if platform == "gitlab" {
+		mrName = "Merge Requests"
+		mrSeparator = "!"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Git Feed\n\n_Generated %s, covering %s._\n", displayTime(generatedAt).Format("2006-01-02 15:04 MST"), describeActivityWindow())
+
+	var openPRs, closedPRs []PRActivity
+	for _, activity := range activities {
+		if activity.MR.State == "closed" {
+			closedPRs = append(closedPRs, activity)
+		} else {
+			openPRs = append(openPRs, activity)
+		}
+	}
+	var openIssues, closedIssues []IssueActivity
+	for _, issue := range issueActivities {
+		if issue.Issue.State == "closed" {
+			closedIssues = append(closedIssues, issue)
+		} else {
+			openIssues = append(openIssues, issue)
+		}
+	}
+
+	writeMergeRequests := func(title string, items []PRActivity) {
+		if len(items) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n", title)
+		for _, activity := range items {
+			state := activityState(activity.MR)
+			ref := fmt.Sprintf("%s%s%d", projectDisplayPath(activity.Owner, activity.Repo), mrSeparator, activity.MR.Number)
+			b.WriteString(markdownItemLine("", activity.Label, ref, activity.MR.WebURL, activity.MR.Title, activity.MR.UserLogin, state, activity.UpdatedAt))
+			for _, issue := range activity.Issues {
+				issueRef := fmt.Sprintf("%s#%d", projectDisplayPath(issue.Owner, issue.Repo), issue.Issue.Number)
+				b.WriteString(markdownItemLine("  ", issue.Label, issueRef, issue.Issue.WebURL, issue.Issue.Title, issue.Issue.UserLogin, issue.Issue.State, issue.UpdatedAt))
+			}
+		}
+	}
+	writeIssues := func(title string, items []IssueActivity) {
+		if len(items) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n", title)
+		for _, issue := range items {
+			ref := fmt.Sprintf("%s#%d", projectDisplayPath(issue.Owner, issue.Repo), issue.Issue.Number)
+			b.WriteString(markdownItemLine("", issue.Label, ref, issue.Issue.WebURL, issue.Issue.Title, issue.Issue.UserLogin, issue.Issue.State, issue.UpdatedAt))
+		}
+	}
+
+	writeMergeRequests("Open "+mrName, openPRs)
+	writeMergeRequests("Closed/Merged "+mrName, closedPRs)
+	writeIssues("Open Issues", openIssues)
+	writeIssues("Closed Issues", closedIssues)
+
+	if len(openPRs)+len(closedPRs)+len(openIssues)+len(closedIssues) == 0 {
+		b.WriteString("\nNo activity found.\n")
+	}
+
+	return b.String()
+}
+
+func markdownItemLine(indent, label, ref, url, title, user, state string, updatedAt time.Time) string {
+	link := ref
+	if url != "" {
+		link = fmt.Sprintf("[%s](%s)", ref, url)
+	}
+	line := fmt.Sprintf("%s- **%s** %s %s", indent, strings.ToUpper(label), link, escapeMarkdown(title))
+	if user != "" {
+		line += fmt.Sprintf(" (@%s)", user)
+	}
+	line += fmt.Sprintf(" — %s, updated %s\n", state, displayTime(updatedAt).Format("2006-01-02"))
+	return line
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	"*", `\*`,
+	"_", `\_`,
+	"[", `\[`,
+	"]", `\]`,
+	"<", `\<`,
+	">", `\>`,
+)
+
+func escapeMarkdown(text string) string {
+	return markdownEscaper.Replace(text)
+}
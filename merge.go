@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+var gitLabMergeRequestRefPattern = regexp.MustCompile(`^([A-Za-z0-9_.-]+(?:/[A-Za-z0-9_.-]+)+)!([0-9]+)$`)
+
+func parseGitLabMergeRequestRef(ref string) (string, int64, error) {
+	match := gitLabMergeRequestRefPattern.FindStringSubmatch(strings.TrimSpace(ref))
+	if match == nil {
+		return "", 0, fmt.Errorf("invalid merge request reference %q (expected group[/subgroup]/repo!iid)", ref)
+	}
+	iid, err := strconv.ParseInt(match[2], 10, 64)
+	if err != nil || iid <= 0 {
+		return "", 0, fmt.Errorf("invalid merge request IID in %q", ref)
+	}
+	return normalizeProjectPathWithNamespace(match[1]), iid, nil
+}
+
+// gitLabMergeBlockers lists every reason the merge request must not be merged
+// yet. An empty result means approvals, pipeline and mergeability all check out.
+func gitLabMergeBlockers(mr *gitlab.MergeRequest, approvals *gitlab.MergeRequestApprovals, whenPipelineSucceeds bool) []string {
+	var blockers []string
+
+	if mr.State != "opened" {
+		blockers = append(blockers, fmt.Sprintf("merge request is %s", mr.State))
+	}
+	if mr.Draft {
+		blockers = append(blockers, "merge request is marked as draft")
+	}
+	if mr.HasConflicts || mr.DetailedMergeStatus == "conflict" {
+		blockers = append(blockers, "merge request has conflicts with the target branch")
+	}
+	switch mr.DetailedMergeStatus {
+	case "need_rebase":
+		blockers = append(blockers, "source branch must be rebased onto the target branch")
+	case "discussions_not_resolved":
+		blockers = append(blockers, "merge request has unresolved discussions")
+	}
+
+	if approvals != nil && !approvals.Approved {
+		if approvals.ApprovalsLeft > 0 {
+			blockers = append(blockers, fmt.Sprintf("merge request still needs %d approval(s)", approvals.ApprovalsLeft))
+		} else {
+			blockers = append(blockers, "merge request is not approved")
+		}
+	}
+
+	if mr.HeadPipeline == nil {
+		blockers = append(blockers, "no pipeline has run for the latest commit")
+	} else {
+		switch status := mr.HeadPipeline.Status; status {
+		case "success":
+		case "created", "waiting_for_resource", "preparing", "pending", "running", "scheduled":
+			if !whenPipelineSucceeds {
+				blockers = append(blockers, fmt.Sprintf("pipeline is %s; use --when-pipeline-succeeds to merge once it passes", status))
+			}
+		default:
+			blockers = append(blockers, fmt.Sprintf("pipeline is %s", status))
+		}
+	}
+
+	return blockers
+}
+
+func confirmPrompt(in io.Reader, prompt string) bool {
+	fmt.Printf("%s [y/N]: ", prompt)
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
+func runGitLabMergeCommand(ctx context.Context, client *gitlab.Client, args []string, in io.Reader) error {
+	flags := flag.NewFlagSet("merge", flag.ContinueOnError)
+	whenPipelineSucceeds := flags.Bool("when-pipeline-succeeds", false, "Schedule the merge to happen once the running pipeline succeeds")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s --platform gitlab merge [--when-pipeline-succeeds] group[/subgroup]/repo!iid\n\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+
+	// Allow flags both before and after the merge request reference.
+	var refs []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+		if flags.NArg() == 0 {
+			break
+		}
+		refs = append(refs, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+	if len(refs) != 1 {
+		flags.Usage()
+		return fmt.Errorf("merge expects exactly one merge request reference")
+	}
+
+	projectPath, iid, err := parseGitLabMergeRequestRef(refs[0])
+	if err != nil {
+		return err
+	}
+	reference := fmt.Sprintf("%s!%d", projectPath, iid)
+
+	var mr *gitlab.MergeRequest
+	err = retryWithBackoff(func() error {
+		var apiErr error
+		mr, _, apiErr = client.MergeRequests.GetMergeRequest(projectPath, iid, &gitlab.GetMergeRequestsOptions{}, gitlab.WithContext(ctx))
+		return apiErr
+	}, fmt.Sprintf("GitLabGetMergeRequest %s", reference))
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", reference, err)
+	}
+
+	var approvals *gitlab.MergeRequestApprovals
+	err = retryWithBackoff(func() error {
+		var apiErr error
+		approvals, _, apiErr = client.MergeRequestApprovals.GetConfiguration(projectPath, iid, gitlab.WithContext(ctx))
+		return apiErr
+	}, fmt.Sprintf("GitLabGetApprovals %s", reference))
+	if err != nil {
+		return fmt.Errorf("failed to load approvals for %s: %w", reference, err)
+	}
+
+	var project *gitlab.Project
+	err = retryWithBackoff(func() error {
+		var apiErr error
+		project, _, apiErr = client.Projects.GetProject(projectPath, nil, gitlab.WithContext(ctx))
+		return apiErr
+	}, fmt.Sprintf("GitLabGetProject %s", projectPath))
+	if err != nil {
+		return fmt.Errorf("failed to load project %s: %w", projectPath, err)
+	}
+
+	fmt.Printf("%s %s\n", color.New(color.Bold).Sprint(reference), mr.Title)
+	fmt.Printf("  %s -> %s\n", mr.SourceBranch, mr.TargetBranch)
+
+	if blockers := gitLabMergeBlockers(mr, approvals, *whenPipelineSucceeds); len(blockers) > 0 {
+		for _, blocker := range blockers {
+			fmt.Printf("  %s %s\n", color.New(color.FgRed).Sprint(symbols.Blocked), blocker)
+		}
+		return fmt.Errorf("refusing to merge %s", reference)
+	}
+
+	model := MergeRequestModel{
+		Squash:      mr.Squash || mr.SquashOnMerge,
+		MergeMethod: string(project.MergeMethod),
+	}
+	for _, warning := range mergeSettingsWarnings(model) {
+		fmt.Printf("  %s %s\n", color.New(color.FgYellow).Sprint("!"), warning)
+	}
+
+	prompt := fmt.Sprintf("Merge %s into %s?", reference, mr.TargetBranch)
+	if *whenPipelineSucceeds && mr.HeadPipeline.Status != "success" {
+		prompt = fmt.Sprintf("Merge %s into %s once the pipeline succeeds?", reference, mr.TargetBranch)
+	}
+	if !confirmPrompt(in, prompt) {
+		return fmt.Errorf("merge of %s cancelled", reference)
+	}
+
+	opts := &gitlab.AcceptMergeRequestOptions{
+		// Pin the reviewed head commit so a late push cannot sneak into the merge.
+		SHA: gitlab.Ptr(mr.SHA),
+	}
+	if *whenPipelineSucceeds {
+		// Older instances only understand the deprecated parameter.
+		opts.AutoMerge = gitlab.Ptr(true)
+		opts.MergeWhenPipelineSucceeds = gitlab.Ptr(true)
+	}
+
+	// Sent once: GitLab may have merged even when the response is an error.
+	merged, _, err := client.MergeRequests.AcceptMergeRequest(projectPath, iid, opts, gitlab.WithContext(ctx), gitLabNoRetry())
+	if err != nil {
+		return fmt.Errorf("failed to merge %s: %w", reference, err)
+	}
+
+	if merged.State == "merged" {
+		fmt.Printf("Merged %s\n", reference)
+	} else {
+		fmt.Printf("%s will be merged when the pipeline succeeds\n", reference)
+	}
+	if merged.WebURL != "" {
+		fmt.Println(merged.WebURL)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// fetchCounters counts the retries and rate-limit waits of retryWithBackoff
+// for the whole process; fetchActivities turns their growth during a fetch
+// into that sync's share of syncMetrics.
+type fetchCounters struct {
+	retries        atomic.Int64
+	rateLimitWaits atomic.Int64
+}
+
+// syncMetrics are the totals over every online fetch into a cache, kept in
+// its meta bucket so `web` can serve them on /metrics while a cron job runs
+// `sync` in another process. They only grow, as Prometheus counters must.
+type syncMetrics struct {
+	Syncs           int64            `json:"syncs"`
+	APICalls        map[string]int64 `json:"api_calls,omitempty"`
+	Retries         int64            `json:"retries"`
+	RateLimitWaits  int64            `json:"rate_limit_waits"`
+	ItemsFetched    int64            `json:"items_fetched"`
+	SyncSeconds     float64          `json:"sync_seconds"`
+	LastSyncSeconds float64          `json:"last_sync_seconds"`
+	LastSyncItems   int64            `json:"last_sync_items_fetched"`
+}
+
+// syncMetricsRun measures one fetch from its start.
+type syncMetricsRun struct {
+	start          time.Time
+	apiCalls       map[string]apiCallCategory
+	retries        int64
+	rateLimitWaits int64
+}
+
+func startSyncMetrics(now time.Time) syncMetricsRun {
+	return syncMetricsRun{
+		start:          now,
+		apiCalls:       config.apiCalls.snapshot(),
+		retries:        config.fetchCounters.retries.Load(),
+		rateLimitWaits: config.fetchCounters.rateLimitWaits.Load(),
+	}
+}
+
+// add folds the fetch that started at run.start into the totals.
+func (m *syncMetrics) add(run syncMetricsRun, items int, now time.Time) {
+	seconds := now.Sub(run.start).Seconds()
+	m.Syncs++
+	m.Retries += config.fetchCounters.retries.Load() - run.retries
+	m.RateLimitWaits += config.fetchCounters.rateLimitWaits.Load() - run.rateLimitWaits
+	m.ItemsFetched += int64(items)
+	m.SyncSeconds += seconds
+	m.LastSyncSeconds = seconds
+	m.LastSyncItems = int64(items)
+	for category, stats := range config.apiCalls.snapshot() {
+		if calls := int64(stats.Calls - run.apiCalls[category].Calls); calls > 0 {
+			if m.APICalls == nil {
+				m.APICalls = make(map[string]int64)
+			}
+			m.APICalls[category] += calls
+		}
+	}
+}
+
+// recordSyncMetrics adds a successful online fetch to the cache's totals.
+func recordSyncMetrics(db Database, run syncMetricsRun, items int, now time.Time) {
+	if db == nil {
+		return
+	}
+	metrics, err := db.SyncMetrics()
+	if err != nil {
+		recordDBWarning("Failed to read sync metrics: %v", err)
+		return
+	}
+	metrics.add(run, items, now)
+	if err := db.SetSyncMetrics(metrics); err != nil {
+		recordDBWarning("Failed to record sync metrics: %v", err)
+	}
+}
+
+// writePrometheusMetrics renders the totals in the Prometheus text format.
+func writePrometheusMetrics(out io.Writer, metrics syncMetrics) {
+	counter := func(name, help string, value any) {
+		fmt.Fprintf(out, "# HELP %s %s\n# TYPE %s counter\n%s %v\n", name, help, name, name, value)
+	}
+	gauge := func(name, help string, value any) {
+		fmt.Fprintf(out, "# HELP %s %s\n# TYPE %s gauge\n%s %v\n", name, help, name, name, value)
+	}
+
+	categories := make([]string, 0, len(metrics.APICalls))
+	for category := range metrics.APICalls {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	fmt.Fprintf(out, "# HELP git_feed_api_calls_total API requests made by syncs, by endpoint category.\n# TYPE git_feed_api_calls_total counter\n")
+	for _, category := range categories {
+		fmt.Fprintf(out, "git_feed_api_calls_total{category=%q} %d\n", category, metrics.APICalls[category])
+	}
+
+	counter("git_feed_syncs_total", "Successful online fetches into the cache.", metrics.Syncs)
+	counter("git_feed_retries_total", "API calls retried after an error.", metrics.Retries)
+	counter("git_feed_rate_limit_waits_total", "Retries that waited for a rate limit.", metrics.RateLimitWaits)
+	counter("git_feed_items_fetched_total", "Merge/pull requests and issues fetched by syncs.", metrics.ItemsFetched)
+	counter("git_feed_sync_duration_seconds_total", "Time spent in syncs.", metrics.SyncSeconds)
+	gauge("git_feed_last_sync_duration_seconds", "Duration of the last sync.", metrics.LastSyncSeconds)
+	gauge("git_feed_last_sync_items_fetched", "Merge/pull requests and issues fetched by the last sync.", metrics.LastSyncItems)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// numberedItem is an item as numbered in the feed, saved after each run so
+// `open N` can find it again without fetching.
+type numberedItem struct {
+	Ref string `json:"ref"`
+	URL string `json:"url"`
+}
+
+// numberItem gives cfg the next number of this run's feed. Its ref uses
+// GitLab's "!" for merge requests, as pick does.
+func numberItem(cfg *DisplayConfig) {
+	separator := "#"
+	if cfg.IsMergeRequest && config.platform == "gitlab" {
+		separator = "!"
+	}
+	ref := fmt.Sprintf("%s%s%d", projectDisplayPath(cfg.Owner, cfg.Repo), separator, cfg.Number)
+	config.numberedItems = append(config.numberedItems, numberedItem{Ref: ref, URL: cfg.WebURL})
+	cfg.Index = len(config.numberedItems)
+}
+
+// recordItemNumbers saves the numbering of the feed just displayed. Runs
+// that number nothing (--count-only) keep the previous numbering.
+func recordItemNumbers(db Database) {
+	if db == nil || len(config.numberedItems) == 0 {
+		return
+	}
+	if err := db.SetLastItems(config.numberedItems); err != nil {
+		recordDBWarning("Failed to save item numbers: %v", err)
+	}
+}
+
+// runOpenCommand implements `open N [--copy]`: it opens (or copies the URL
+// of) item N of the last displayed feed.
+func runOpenCommand(db Database, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("open", flag.ContinueOnError)
+	copyURL := flags.Bool("copy", false, "Copy the item's URL to the clipboard instead of opening it")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [options] open N [--copy]\n\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	positional := flags.Args()
+	if len(positional) > 0 {
+		// Flags may also follow the number (`open 7 --copy`).
+		if err := flags.Parse(positional[1:]); err != nil {
+			return err
+		}
+		positional = append([]string{positional[0]}, flags.Args()...)
+	}
+	if len(positional) != 1 {
+		flags.Usage()
+		return fmt.Errorf("open takes one item number (got %q)", positional)
+	}
+	number, err := strconv.Atoi(positional[0])
+	if err != nil || number < 1 {
+		return fmt.Errorf("invalid item number %q", positional[0])
+	}
+	if db == nil {
+		return fmt.Errorf("the cache database is not available")
+	}
+
+	items, err := db.LastItems()
+	if err != nil {
+		return fmt.Errorf("failed to read the last feed's item numbers: %w", err)
+	}
+	if len(items) == 0 {
+		return fmt.Errorf("no numbered items yet; run git-feed first")
+	}
+	if number > len(items) {
+		return fmt.Errorf("the last feed has items 1-%d, not %d", len(items), number)
+	}
+	item := items[number-1]
+	if item.URL == "" {
+		return fmt.Errorf("%s has no URL", item.Ref)
+	}
+
+	if *copyURL {
+		if err := copyToClipboard(item.URL, out); err != nil {
+			return fmt.Errorf("failed to copy the URL: %w", err)
+		}
+		fmt.Fprintf(out, "Copied %s: %s\n", item.Ref, item.URL)
+		return nil
+	}
+	fmt.Fprintf(out, "Opening %s: %s\n", item.Ref, item.URL)
+	return openInBrowser(item.URL)
+}
@@ -0,0 +1,338 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// pickItem is one line of the picker: the text the query is matched against
+// and the URL opened when it is chosen.
+type pickItem struct {
+	Text string
+	URL  string
+}
+
+// pickItems lists every item of the feed, nested issues included, newest
+// first, as "project!iid title @author [Label]".
+func pickItems(platform string, activities []PRActivity, issueActivities []IssueActivity) []pickItem {
+	activities, issueActivities = filterActivitiesByState(activities, issueActivities, config.states)
+
+	mrSeparator := "#"
+	if platform == "gitlab" {
+		mrSeparator = "!"
+	}
+
+	type dated struct {
+		item      pickItem
+		updatedAt int64
+	}
+	var all []dated
+	addIssue := func(issue IssueActivity) {
+		text := fmt.Sprintf("%s#%d %s @%s [%s]", projectDisplayPath(issue.Owner, issue.Repo), issue.Issue.Number, issue.Issue.Title, issue.Issue.UserLogin, issue.Label)
+		all = append(all, dated{pickItem{Text: text, URL: issue.Issue.WebURL}, issue.UpdatedAt.UnixNano()})
+	}
+	for _, activity := range activities {
+		text := fmt.Sprintf("%s%s%d %s @%s [%s]", projectDisplayPath(activity.Owner, activity.Repo), mrSeparator, activity.MR.Number, activity.MR.Title, activity.MR.UserLogin, activity.Label)
+		all = append(all, dated{pickItem{Text: text, URL: activity.MR.WebURL}, activity.UpdatedAt.UnixNano()})
+		for _, issue := range activity.Issues {
+			addIssue(issue)
+		}
+	}
+	for _, issue := range issueActivities {
+		addIssue(issue)
+	}
+
+	sort.SliceStable(all, func(i, j int) bool { return all[i].updatedAt > all[j].updatedAt })
+	items := make([]pickItem, 0, len(all))
+	for _, entry := range all {
+		items = append(items, entry.item)
+	}
+	return items
+}
+
+// fuzzyMatch reports whether every whitespace-separated term of query occurs
+// in text as a case-insensitive subsequence, fzf style. The score favors
+// consecutive characters and matches at word starts; positions are the
+// matched rune indexes, for highlighting.
+func fuzzyMatch(query, text string) (int, []int, bool) {
+	runes := []rune(strings.ToLower(text))
+	score := 0
+	var positions []int
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		wordRunes := []rune(word)
+		matched, last := 0, -2
+		for i := 0; i < len(runes) && matched < len(wordRunes); i++ {
+			if runes[i] != wordRunes[matched] {
+				continue
+			}
+			score++
+			if last == i-1 {
+				score += 4
+			}
+			if i == 0 || !unicode.IsLetter(runes[i-1]) && !unicode.IsDigit(runes[i-1]) {
+				score += 2
+			}
+			positions = append(positions, i)
+			last = i
+			matched++
+		}
+		if matched < len(wordRunes) {
+			return 0, nil, false
+		}
+	}
+	return score, positions, true
+}
+
+// picker is the state of the fuzzy finder, kept apart from the terminal so
+// the key handling can be tested.
+type picker struct {
+	items    []pickItem
+	query    []rune
+	matches  []int // indexes into items, best first
+	selected int   // index into matches
+}
+
+func newPicker(items []pickItem) *picker {
+	p := &picker{items: items}
+	p.filter()
+	return p
+}
+
+// filter ranks the items matching the query; ties keep the feed order.
+func (p *picker) filter() {
+	type scored struct{ index, score int }
+	var ranked []scored
+	for i, item := range p.items {
+		if score, _, ok := fuzzyMatch(string(p.query), item.Text); ok {
+			ranked = append(ranked, scored{i, score})
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].score > ranked[j].score })
+	p.matches = p.matches[:0]
+	for _, entry := range ranked {
+		p.matches = append(p.matches, entry.index)
+	}
+	p.selected = 0
+}
+
+type pickerAction int
+
+const (
+	pickerContinue pickerAction = iota
+	pickerAccept
+	pickerCancel
+)
+
+// handleInput applies raw terminal input: typing filters, Backspace and
+// Ctrl-U edit the query, Up/Down (or Ctrl-P/Ctrl-N) move the selection,
+// Enter accepts and Esc or Ctrl-C cancels.
+func (p *picker) handleInput(input []byte) pickerAction {
+	for len(input) > 0 {
+		switch {
+		case len(input) >= 2 && input[0] == 0x1b && input[1] == '[':
+			// A CSI sequence runs up to its final byte, e.g. "\x1b[1;5A" for
+			// Ctrl+Up or "\x1b[3~" for Delete; a truncated one is dropped.
+			end := 2
+			for end < len(input) && (input[end] < 0x40 || input[end] > 0x7e) {
+				end++
+			}
+			if end == len(input) {
+				return pickerContinue
+			}
+			switch input[end] {
+			case 'A':
+				p.move(-1)
+			case 'B':
+				p.move(1)
+			}
+			input = input[end+1:]
+			continue
+		case len(input) >= 3 && input[0] == 0x1b && input[1] == 'O':
+			switch input[2] {
+			case 'A':
+				p.move(-1)
+			case 'B':
+				p.move(1)
+			}
+			input = input[3:]
+			continue
+		case input[0] == 0x1b || input[0] == 0x03:
+			return pickerCancel
+		case input[0] == '\r' || input[0] == '\n':
+			if len(p.matches) == 0 {
+				return pickerContinue
+			}
+			return pickerAccept
+		case input[0] == 0x10:
+			p.move(-1)
+		case input[0] == 0x0e:
+			p.move(1)
+		case input[0] == 0x7f || input[0] == 0x08:
+			if len(p.query) > 0 {
+				p.query = p.query[:len(p.query)-1]
+				p.filter()
+			}
+		case input[0] == 0x15:
+			p.query = p.query[:0]
+			p.filter()
+		case input[0] >= 0x20:
+			r, size := utf8.DecodeRune(input)
+			p.query = append(p.query, r)
+			p.filter()
+			input = input[size:]
+			continue
+		}
+		input = input[1:]
+	}
+	return pickerContinue
+}
+
+func (p *picker) move(delta int) {
+	if len(p.matches) == 0 {
+		return
+	}
+	p.selected = min(max(p.selected+delta, 0), len(p.matches)-1)
+}
+
+// choice is the selected item, if any item matches.
+func (p *picker) choice() (pickItem, bool) {
+	if len(p.matches) == 0 {
+		return pickItem{}, false
+	}
+	return p.items[p.matches[p.selected]], true
+}
+
+// render draws the prompt and as many matches as fit in height rows, the
+// selected one in reverse video and matched characters in bold.
+func (p *picker) render(width, height int) string {
+	var b strings.Builder
+	b.WriteString("\x1b[H\x1b[2J")
+	rows := max(height-2, 1)
+	first := max(p.selected-rows+1, 0)
+	for i := first; i < len(p.matches) && i < first+rows; i++ {
+		item := p.items[p.matches[i]]
+		_, positions, _ := fuzzyMatch(string(p.query), item.Text)
+		line := highlightPositions(item.Text, positions, width-2)
+		if i == p.selected {
+			fmt.Fprintf(&b, "\x1b[7m> %s\x1b[0m\r\n", line)
+		} else {
+			fmt.Fprintf(&b, "  %s\r\n", line)
+		}
+	}
+	fmt.Fprintf(&b, "\x1b[%d;1H  %d/%d\r\n> %s", height-1, len(p.matches), len(p.items), string(p.query))
+	return b.String()
+}
+
+// highlightPositions bolds the runes at positions and cuts text to width
+// cells.
+func highlightPositions(text string, positions []int, width int) string {
+	bold := make(map[int]bool, len(positions))
+	for _, position := range positions {
+		bold[position] = true
+	}
+	var b strings.Builder
+	used := 0
+	for i, r := range []rune(text) {
+		if width > 0 && used+runeWidth(r) > width {
+			break
+		}
+		used += runeWidth(r)
+		if bold[i] {
+			b.WriteString("\x1b[1m" + string(r) + "\x1b[22m")
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// runPicker shows the picker on the terminal (in the alternate screen) until
+// an item is chosen or the picker is cancelled.
+func runPicker(in *os.File, out io.Writer, items []pickItem) (pickItem, bool, error) {
+	fd := int(in.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return pickItem{}, false, fmt.Errorf("failed to set up the terminal: %w", err)
+	}
+	defer term.Restore(fd, state)
+	fmt.Fprint(out, "\x1b[?1049h")
+	defer fmt.Fprint(out, "\x1b[?1049l")
+
+	p := newPicker(items)
+	buf := make([]byte, 256)
+	for {
+		width, height, err := term.GetSize(int(os.Stdout.Fd()))
+		if err != nil {
+			width, height = 80, 24
+		}
+		fmt.Fprint(out, p.render(width, height))
+
+		n, err := in.Read(buf)
+		if err != nil {
+			return pickItem{}, false, err
+		}
+		switch p.handleInput(buf[:n]) {
+		case pickerAccept:
+			item, ok := p.choice()
+			return item, ok, nil
+		case pickerCancel:
+			return pickItem{}, false, nil
+		}
+	}
+}
+
+// runPickCommand fetches the feed and opens the item chosen in the picker,
+// or with --copy puts its URL on the clipboard.
+func runPickCommand(platform string, args []string) error {
+	flags := flag.NewFlagSet("pick", flag.ContinueOnError)
+	copyURL := flags.Bool("copy", false, "Copy the chosen item's URL to the clipboard instead of opening it")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [options] pick [--copy]\n\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return fmt.Errorf("pick does not take positional arguments (got %q)", flags.Args())
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) || !stdoutIsTerminal() {
+		return fmt.Errorf("pick needs an interactive terminal")
+	}
+
+	activities, issueActivities, err := fetchActivities(platform)
+	if err != nil {
+		return fmt.Errorf("failed to fetch activity: %w", err)
+	}
+	items := pickItems(platform, activities, issueActivities)
+	if len(items) == 0 {
+		fmt.Println("No activity found.")
+		return nil
+	}
+
+	item, ok, err := runPicker(os.Stdin, os.Stdout, items)
+	if err != nil || !ok {
+		return err
+	}
+	if *copyURL {
+		if item.URL == "" {
+			return fmt.Errorf("the item has no URL")
+		}
+		if err := copyToClipboard(item.URL, os.Stdout); err != nil {
+			return fmt.Errorf("failed to copy the URL: %w", err)
+		}
+		fmt.Printf("Copied %s\n", item.URL)
+		return nil
+	}
+	fmt.Println(item.URL)
+	return openInBrowser(item.URL)
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// Platform is a forge the feed can be read from. Implementations register a
+// constructor from an init function with registerPlatform; fetchActivities
+// creates a fresh instance per run, so state can be kept on it between the
+// three steps.
+type Platform interface {
+	// DisplayName is the forge's name in messages, e.g. "GitLab".
+	DisplayName() string
+	// ResolveProjects turns the allowed repos into what FetchActivities scans
+	// (GitHub owner/* wildcards, GitLab project IDs). It is skipped with --local.
+	ResolveProjects(ctx context.Context) error
+	// FetchActivities returns the merge/pull requests and issues updated after
+	// cutoff, from the API (saving them to the cache) or, with --local, from
+	// the cache. Projects with their own time range (repoCutoff) may need an
+	// earlier cutoff; later items than that are filtered out afterwards.
+	FetchActivities(ctx context.Context, cutoff time.Time) ([]PRActivity, []IssueActivity, error)
+	// LinkCrossReferences nests issues under the merge/pull requests that
+	// reference them and returns the issues left standalone.
+	LinkCrossReferences(ctx context.Context, activities []PRActivity, issueActivities []IssueActivity) ([]PRActivity, []IssueActivity, error)
+}
+
+var platforms = map[string]func() Platform{}
+
+// registerPlatform makes a Platform available as --platform name.
+func registerPlatform(name string, newPlatform func() Platform) {
+	if _, exists := platforms[name]; exists {
+		panic(fmt.Sprintf("platform %q registered twice", name))
+	}
+	platforms[name] = newPlatform
+}
+
+// platformNames lists the registered --platform values, sorted.
+func platformNames() []string {
+	names := make([]string, 0, len(platforms))
+	for name := range platforms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func isRegisteredPlatform(name string) bool {
+	_, ok := platforms[name]
+	return ok
+}
+
+// fetchActivities runs the platform's resolve, fetch and link steps, records
+// the sync time after a successful online fetch and applies --until.
+func fetchActivities(platform string) ([]PRActivity, []IssueActivity, error) {
+	newPlatform, ok := platforms[platform]
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported platform %q", platform)
+	}
+	p := newPlatform()
+	startTime := time.Now()
+	metricsRun := startSyncMetrics(startTime)
+
+	if config.debugMode {
+		fmt.Printf("Fetching data from %s...\n", p.DisplayName())
+	} else if !config.quiet && config.interactive {
+		fmt.Printf("Fetching data from %s... ", p.DisplayName())
+	}
+
+	ctx := config.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	// Platforms may show a progress bar in config.progress while fetching.
+	defer func() { config.progress = nil }()
+	if !config.localMode {
+		if err := p.ResolveProjects(ctx); err != nil {
+			return nil, nil, timeoutError(ctx, err)
+		}
+	}
+	cutoff := activityCutoff()
+	activities, issueActivities, err := p.FetchActivities(ctx, cutoff)
+	if err != nil {
+		return nil, nil, timeoutError(ctx, err)
+	}
+	fetchedItems := len(activities) + len(issueActivities)
+	activities, issueActivities, err = p.LinkCrossReferences(ctx, activities, issueActivities)
+	if err != nil {
+		return nil, nil, timeoutError(ctx, err)
+	}
+	activities, issueActivities = filterActivitiesByRepoCutoff(activities, issueActivities, cutoff)
+	if !config.localMode {
+		recordLastSync(config.db, startTime)
+		recordSyncMetrics(config.db, metricsRun, fetchedItems, time.Now())
+	}
+	activities, issueActivities = filterActivitiesByWindow(activities, issueActivities, config.until)
+	activities, issueActivities = filterActivitiesByDueSoon(activities, issueActivities, config.dueSoon, time.Now())
+	activities, issueActivities = filterActivitiesByMinWeight(activities, issueActivities, config.minWeight)
+
+	if config.debugMode {
+		fmt.Println()
+		fmt.Printf("Total fetch time: %v\n", time.Since(startTime).Round(time.Millisecond))
+		fmt.Printf("Found %d unique merge/pull requests and %d unique issues\n", len(activities), len(issueActivities))
+		fmt.Println()
+	} else if !config.quiet && config.interactive {
+		clearProgressLine()
+	}
+
+	return activities, issueActivities, nil
+}
+
+// fetchAndDisplayActivity renders the feed and reports whether it contains
+// actionable items (see hasActionableItems).
+func fetchAndDisplayActivity(platform string, out io.Writer) (bool, error) {
+	activities, issueActivities, err := fetchActivities(platform)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch activity: %w", err)
+	}
+
+	displayStreamDivider(out)
+	displayActivities(out, activities, issueActivities)
+	recordItemNumbers(config.db)
+	return hasActionableItems(activities, issueActivities), nil
+}
@@ -2,7 +2,9 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
 	"regexp"
 	"sort"
@@ -10,7 +12,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/fatih/color"
 	"github.com/google/go-github/v57/github"
 	"golang.org/x/oauth2"
 )
@@ -22,148 +23,96 @@ var (
 	githubCrossRefURLPattern     = regexp.MustCompile(`(?i)https?://github\.com/([a-z0-9_.-]+)/([a-z0-9_.-]+)/(?:issues|pull)/([0-9]+)\b`)
 )
 
-func fetchAndDisplayGitHubActivity() {
-	startTime := time.Now()
-
-	if config.debugMode {
-		fmt.Println("Fetching data from GitHub...")
-	} else {
-		fmt.Print("Fetching data from GitHub... ")
-	}
-
-	cutoffTime := time.Now().Add(-config.timeRange)
-	var (
-		activities      []PRActivity
-		issueActivities []IssueActivity
-		err             error
-	)
-
-	if config.localMode {
-		activities, issueActivities, err = loadGitHubCachedActivities(cutoffTime)
-	} else {
-		ctx := config.ctx
-		if ctx == nil {
-			ctx = context.Background()
-		}
-		activities, issueActivities, err = fetchGitHubActivitiesOnline(ctx, cutoffTime)
-	}
-	if err != nil {
-		fmt.Printf("Error fetching GitHub activity: %v\n", err)
-		return
-	}
-
-	if config.debugMode {
-		fmt.Println()
-		fmt.Printf("Total fetch time: %v\n", time.Since(startTime).Round(time.Millisecond))
-		fmt.Printf("Found %d unique pull requests and %d unique issues\n", len(activities), len(issueActivities))
-		fmt.Println()
-	} else {
-		fmt.Print("\r" + strings.Repeat(" ", 80) + "\r")
-	}
-
-	if len(activities) == 0 && len(issueActivities) == 0 {
-		fmt.Println("No open activity found")
-		return
-	}
-
-	sort.Slice(activities, func(i, j int) bool {
-		return activities[i].UpdatedAt.After(activities[j].UpdatedAt)
-	})
-	sort.Slice(issueActivities, func(i, j int) bool {
-		return issueActivities[i].UpdatedAt.After(issueActivities[j].UpdatedAt)
-	})
-
-	var openPRs, closedPRs, mergedPRs []PRActivity
-	for _, activity := range activities {
-		if activity.MR.State == "closed" {
-			if activity.MR.Merged {
-				mergedPRs = append(mergedPRs, activity)
-			} else {
-				closedPRs = append(closedPRs, activity)
-			}
-		} else {
-			openPRs = append(openPRs, activity)
-		}
-	}
-
-	var openIssues, closedIssues []IssueActivity
-	for _, issue := range issueActivities {
-		if issue.Issue.State == "closed" {
-			closedIssues = append(closedIssues, issue)
-		} else {
-			openIssues = append(openIssues, issue)
-		}
-	}
+func init() {
+	registerPlatform("github", func() Platform { return &gitHubPlatform{} })
+}
 
-	if len(openPRs) > 0 {
-		titleColor := color.New(color.FgHiGreen, color.Bold)
-		fmt.Println(titleColor.Sprint("OPEN PULL REQUESTS:"))
-		fmt.Println("------------------------------------------")
-		for _, activity := range openPRs {
-			displayMergeRequest(activity.Label, activity.Owner, activity.Repo, activity.MR, activity.HasUpdates)
-			for _, issue := range activity.Issues {
-				displayIssue(issue.Label, issue.Owner, issue.Repo, issue.Issue, true, issue.HasUpdates)
-			}
+// gitHubRetryInfo reads a go-github error for retryWithBackoff: the response
+// status and, for rate limits, how long GitHub asked to wait (Retry-After on
+// secondary limits and 429s, the reset time of the primary limit). ok is
+// false for errors that are not GitHub API responses.
+func gitHubRetryInfo(err error) (statusCode int, rateLimited bool, wait time.Duration, ok bool) {
+	var abuseErr *github.AbuseRateLimitError
+	var rateErr *github.RateLimitError
+	var responseErr *github.ErrorResponse
+	switch {
+	case errors.As(err, &abuseErr):
+		if abuseErr.RetryAfter != nil {
+			wait = *abuseErr.RetryAfter
 		}
-	}
-
-	if len(closedPRs) > 0 || len(mergedPRs) > 0 {
-		fmt.Println()
-		titleColor := color.New(color.FgHiRed, color.Bold)
-		fmt.Println(titleColor.Sprint("CLOSED/MERGED PULL REQUESTS:"))
-		fmt.Println("------------------------------------------")
-		for _, activity := range mergedPRs {
-			displayMergeRequest(activity.Label, activity.Owner, activity.Repo, activity.MR, activity.HasUpdates)
-			for _, issue := range activity.Issues {
-				displayIssue(issue.Label, issue.Owner, issue.Repo, issue.Issue, true, issue.HasUpdates)
-			}
+		return gitHubResponseStatus(abuseErr.Response), true, wait, true
+	case errors.As(err, &rateErr):
+		return gitHubResponseStatus(rateErr.Response), true, time.Until(rateErr.Rate.Reset.Time), true
+	case errors.As(err, &responseErr) && responseErr.Response != nil:
+		statusCode = responseErr.Response.StatusCode
+		if statusCode != http.StatusTooManyRequests {
+			return statusCode, false, 0, true
 		}
-		for _, activity := range closedPRs {
-			displayMergeRequest(activity.Label, activity.Owner, activity.Repo, activity.MR, activity.HasUpdates)
-			for _, issue := range activity.Issues {
-				displayIssue(issue.Label, issue.Owner, issue.Repo, issue.Issue, true, issue.HasUpdates)
-			}
+		if seconds, err := strconv.Atoi(strings.TrimSpace(responseErr.Response.Header.Get("Retry-After"))); err == nil && seconds > 0 {
+			wait = time.Duration(seconds) * time.Second
 		}
+		return statusCode, true, wait, true
 	}
+	return 0, false, 0, false
+}
 
-	if len(openIssues) > 0 {
-		fmt.Println()
-		titleColor := color.New(color.FgHiGreen, color.Bold)
-		fmt.Println(titleColor.Sprint("OPEN ISSUES:"))
-		fmt.Println("------------------------------------------")
-		for _, issue := range openIssues {
-			displayIssue(issue.Label, issue.Owner, issue.Repo, issue.Issue, false, issue.HasUpdates)
-		}
+func gitHubResponseStatus(resp *http.Response) int {
+	if resp == nil {
+		return 0
 	}
+	return resp.StatusCode
+}
 
-	if len(closedIssues) > 0 {
-		fmt.Println()
-		titleColor := color.New(color.FgHiRed, color.Bold)
-		fmt.Println(titleColor.Sprint("CLOSED ISSUES:"))
-		fmt.Println("------------------------------------------")
-		for _, issue := range closedIssues {
-			displayIssue(issue.Label, issue.Owner, issue.Repo, issue.Issue, false, issue.HasUpdates)
-		}
-	}
+// gitHubPlatform seeds the feed from search (or notifications). FetchActivities
+// keeps the PR review comments for LinkCrossReferences.
+type gitHubPlatform struct {
+	client           *github.Client
+	prReviewComments map[string][]GitHubPRReviewCommentRecord
 }
 
-func fetchGitHubActivitiesOnline(ctx context.Context, cutoff time.Time) ([]PRActivity, []IssueActivity, error) {
-	client := newGitHubClient(config.githubToken)
-	dateFilter := cutoff.Format("2006-01-02")
+func (p *gitHubPlatform) DisplayName() string {
+	return "GitHub"
+}
 
-	prActivities, prReviewComments, err := collectGitHubPRSearchResults(ctx, client, config.githubUsername, dateFilter, cutoff)
+func (p *gitHubPlatform) ResolveProjects(ctx context.Context) error {
+	p.client = newGitHubClient(config.githubToken)
+	allowedRepos, err := expandGitHubOrgWildcards(ctx, p.client, config.allowedRepos)
 	if err != nil {
-		return nil, nil, err
+		return err
 	}
+	config.allowedRepos = allowedRepos
+	return nil
+}
 
-	issueActivities, err := collectGitHubIssueSearchResults(ctx, client, config.githubUsername, dateFilter, cutoff)
+func (p *gitHubPlatform) FetchActivities(ctx context.Context, cutoff time.Time) ([]PRActivity, []IssueActivity, error) {
+	var (
+		prActivities    []PRActivity
+		issueActivities []IssueActivity
+		err             error
+	)
+	// Searches span all repositories, so they cover the longest per-repo window.
+	cutoff = earliestRepoCutoff(cutoff)
+	switch {
+	case p.client == nil: // ResolveProjects only runs online
+		prActivities, p.prReviewComments, issueActivities, err = loadGitHubCachedItems(cutoff)
+	case config.githubSource == "notifications":
+		prActivities, p.prReviewComments, issueActivities, err = collectGitHubNotificationResults(ctx, p.client, cutoff)
+	default:
+		dateFilter := cutoff.Format("2006-01-02")
+		prActivities, p.prReviewComments, err = collectGitHubPRSearchResults(ctx, p.client, config.githubUsername, dateFilter, cutoff)
+		if err == nil {
+			issueActivities, err = collectGitHubIssueSearchResults(ctx, p.client, config.githubUsername, dateFilter, cutoff)
+		}
+	}
 	if err != nil {
 		return nil, nil, err
 	}
+	return prActivities, issueActivities, nil
+}
 
-	nestedPRs := nestGitHubIssues(prActivities, issueActivities, prReviewComments)
-	standaloneIssues := filterStandaloneGitHubIssues(nestedPRs, issueActivities)
-	return nestedPRs, standaloneIssues, nil
+func (p *gitHubPlatform) LinkCrossReferences(ctx context.Context, activities []PRActivity, issueActivities []IssueActivity) ([]PRActivity, []IssueActivity, error) {
+	nestedPRs := nestGitHubIssues(activities, issueActivities, p.prReviewComments)
+	return nestedPRs, filterStandaloneGitHubIssues(nestedPRs, issueActivities), nil
 }
 
 func collectGitHubPRSearchResults(
@@ -176,24 +125,54 @@ func collectGitHubPRSearchResults(
 		Label string
 		Query string
 	}{
-		{Label: "Reviewed", Query: fmt.Sprintf("is:pr reviewed-by:%s updated:>=%s", username, dateFilter)},
-		{Label: "Review Requested", Query: fmt.Sprintf("is:pr review-requested:%s updated:>=%s", username, dateFilter)},
-		{Label: "Authored", Query: fmt.Sprintf("is:pr author:%s updated:>=%s", username, dateFilter)},
-		{Label: "Assigned", Query: fmt.Sprintf("is:pr assignee:%s updated:>=%s", username, dateFilter)},
-		{Label: "Commented", Query: fmt.Sprintf("is:pr commenter:%s updated:>=%s", username, dateFilter)},
-		{Label: "Mentioned", Query: fmt.Sprintf("is:pr mentions:%s updated:>=%s", username, dateFilter)},
+		{Label: "Reviewed", Query: "is:pr reviewed-by:%s updated:>=%s"},
+		{Label: "Review Requested", Query: "is:pr review-requested:%s updated:>=%s"},
+		{Label: "Authored", Query: "is:pr author:%s updated:>=%s"},
+		{Label: "Assigned", Query: "is:pr assignee:%s updated:>=%s"},
+		{Label: "Commented", Query: "is:pr commenter:%s updated:>=%s"},
+		{Label: "Mentioned", Query: "is:pr mentions:%s updated:>=%s"},
 	}
 
-	byKey := make(map[string]PRActivity)
-	prReviewComments := make(map[string][]GitHubPRReviewCommentRecord)
-
+	// Search qualifiers take one user each, so aliases get their own searches.
+	logins := append([]string{username}, userAliasList()...)
+	batches := make([]gitHubLabeledItems, 0, len(queries)*len(logins))
 	for _, q := range queries {
-		items, err := searchGitHubIssues(ctx, client, q.Query)
-		if err != nil {
-			return nil, nil, fmt.Errorf("search pull requests for %s: %w", q.Label, err)
+		for _, login := range logins {
+			items, prefetched, err := searchGitHubItems(ctx, client, fmt.Sprintf(q.Query, login, dateFilter))
+			if err != nil {
+				return nil, nil, fmt.Errorf("search pull requests for %s: %w", q.Label, err)
+			}
+			batches = append(batches, gitHubLabeledItems{Label: q.Label, Items: items, Prefetched: prefetched})
 		}
+	}
 
-		for _, item := range items {
+	return collectGitHubPullRequests(ctx, client, batches, cutoff)
+}
+
+// gitHubLabeledItems are the search items (or notification subjects) found for
+// one label, with whatever the source already fetched about them.
+type gitHubLabeledItems struct {
+	Label      string
+	Items      []*github.Issue
+	Prefetched *gitHubPrefetched
+}
+
+// collectGitHubPullRequests hydrates the pull requests in batches, fetches
+// their review comments and check status, and merges them into one activity
+// per PR with the highest-priority label.
+func collectGitHubPullRequests(
+	ctx context.Context,
+	client *github.Client,
+	batches []gitHubLabeledItems,
+	cutoff time.Time,
+) ([]PRActivity, map[string][]GitHubPRReviewCommentRecord, error) {
+	byKey := make(map[string]PRActivity)
+	prReviewComments := make(map[string][]GitHubPRReviewCommentRecord)
+	checkStatuses := make(map[string]string)
+
+	for _, batch := range batches {
+		prefetched := batch.Prefetched
+		for _, item := range batch.Items {
 			if item == nil || item.GetPullRequestLinks() == nil {
 				continue
 			}
@@ -202,7 +181,7 @@ func collectGitHubPRSearchResults(
 				continue
 			}
 
-			pr, err := getGitHubPullRequest(ctx, client, owner, repo, item.GetNumber())
+			pr, err := getGitHubPullRequestPrefetched(ctx, client, prefetched, owner, repo, item.GetNumber())
 			if err != nil {
 				return nil, nil, err
 			}
@@ -212,6 +191,18 @@ func collectGitHubPRSearchResults(
 			}
 
 			key := buildGitHubItemKey(owner, repo, model.Number)
+			if model.State == "open" {
+				status, checked := checkStatuses[key]
+				if !checked {
+					status, checked = prefetched.checkStatus(key)
+				}
+				if !checked {
+					status = getGitHubCheckStatus(ctx, client, owner, repo, pr.GetHead().GetSHA())
+				}
+				checkStatuses[key] = status
+				model.CheckStatus = status
+			}
+
 			activity, exists := byKey[key]
 			if !exists {
 				activity = PRActivity{Owner: owner, Repo: repo, MR: model, UpdatedAt: model.UpdatedAt}
@@ -221,20 +212,17 @@ func collectGitHubPRSearchResults(
 				}
 				activity.MR = model
 			}
-			if shouldUpdateLabel(activity.Label, q.Label, true) {
-				activity.Label = q.Label
+			if shouldUpdateLabel(activity.Label, batch.Label, true) {
+				activity.Label = batch.Label
 			}
 
 			if config.db != nil {
 				if err := config.db.SaveGitHubPullRequestWithLabel(owner, repo, model, activity.Label, config.debugMode); err != nil {
-					config.dbErrorCount.Add(1)
-					if config.debugMode {
-						fmt.Printf("  [DB] Warning: Failed to save GitHub PR %s/%s#%d: %v\n", owner, repo, model.Number, err)
-					}
+					recordDBWarning("Failed to save GitHub PR %s/%s#%d: %v", owner, repo, model.Number, err)
 				}
 			}
 
-			reviewComments, err := listGitHubPRReviewComments(ctx, client, owner, repo, model.Number)
+			reviewComments, err := listGitHubPRReviewCommentsPrefetched(ctx, client, prefetched, owner, repo, model.Number)
 			if err != nil {
 				return nil, nil, err
 			}
@@ -244,10 +232,7 @@ func collectGitHubPRSearchResults(
 				records = append(records, record)
 				if config.db != nil {
 					if err := config.db.SaveGitHubPRReviewComment(record, config.debugMode); err != nil {
-						config.dbErrorCount.Add(1)
-						if config.debugMode {
-							fmt.Printf("  [DB] Warning: Failed to save GitHub PR review comment %s/%s#%d/%d: %v\n", owner, repo, model.Number, record.CommentID, err)
-						}
+						recordDBWarning("Failed to save GitHub PR review comment %s/%s#%d/%d: %v", owner, repo, model.Number, record.CommentID, err)
 					}
 				}
 			}
@@ -274,21 +259,40 @@ func collectGitHubIssueSearchResults(
 		Label string
 		Query string
 	}{
-		{Label: "Authored", Query: fmt.Sprintf("is:issue author:%s updated:>=%s", username, dateFilter)},
-		{Label: "Mentioned", Query: fmt.Sprintf("is:issue mentions:%s updated:>=%s", username, dateFilter)},
-		{Label: "Assigned", Query: fmt.Sprintf("is:issue assignee:%s updated:>=%s", username, dateFilter)},
-		{Label: "Commented", Query: fmt.Sprintf("is:issue commenter:%s updated:>=%s", username, dateFilter)},
+		{Label: "Authored", Query: "is:issue author:%s updated:>=%s"},
+		{Label: "Mentioned", Query: "is:issue mentions:%s updated:>=%s"},
+		{Label: "Assigned", Query: "is:issue assignee:%s updated:>=%s"},
+		{Label: "Commented", Query: "is:issue commenter:%s updated:>=%s"},
 	}
 
-	byKey := make(map[string]IssueActivity)
-
+	logins := append([]string{username}, userAliasList()...)
+	batches := make([]gitHubLabeledItems, 0, len(queries)*len(logins))
 	for _, q := range queries {
-		items, err := searchGitHubIssues(ctx, client, q.Query)
-		if err != nil {
-			return nil, fmt.Errorf("search issues for %s: %w", q.Label, err)
+		for _, login := range logins {
+			items, prefetched, err := searchGitHubItems(ctx, client, fmt.Sprintf(q.Query, login, dateFilter))
+			if err != nil {
+				return nil, fmt.Errorf("search issues for %s: %w", q.Label, err)
+			}
+			batches = append(batches, gitHubLabeledItems{Label: q.Label, Items: items, Prefetched: prefetched})
 		}
+	}
+
+	return collectGitHubIssues(ctx, client, batches, cutoff)
+}
+
+// collectGitHubIssues hydrates the issues in batches and merges them into one
+// activity per issue with the highest-priority label.
+func collectGitHubIssues(
+	ctx context.Context,
+	client *github.Client,
+	batches []gitHubLabeledItems,
+	cutoff time.Time,
+) ([]IssueActivity, error) {
+	byKey := make(map[string]IssueActivity)
 
-		for _, item := range items {
+	for _, batch := range batches {
+		prefetched := batch.Prefetched
+		for _, item := range batch.Items {
 			if item == nil || item.GetPullRequestLinks() != nil {
 				continue
 			}
@@ -297,7 +301,7 @@ func collectGitHubIssueSearchResults(
 				continue
 			}
 
-			issue, err := getGitHubIssue(ctx, client, owner, repo, item.GetNumber())
+			issue, err := getGitHubIssuePrefetched(ctx, client, prefetched, owner, repo, item.GetNumber())
 			if err != nil {
 				return nil, err
 			}
@@ -316,16 +320,13 @@ func collectGitHubIssueSearchResults(
 				}
 				activity.Issue = model
 			}
-			if shouldUpdateLabel(activity.Label, q.Label, false) {
-				activity.Label = q.Label
+			if shouldUpdateLabel(activity.Label, batch.Label, false) {
+				activity.Label = batch.Label
 			}
 
 			if config.db != nil {
 				if err := config.db.SaveGitHubIssueWithLabel(owner, repo, model, activity.Label, config.debugMode); err != nil {
-					config.dbErrorCount.Add(1)
-					if config.debugMode {
-						fmt.Printf("  [DB] Warning: Failed to save GitHub issue %s/%s#%d: %v\n", owner, repo, model.Number, err)
-					}
+					recordDBWarning("Failed to save GitHub issue %s/%s#%d: %v", owner, repo, model.Number, err)
 				}
 			}
 
@@ -340,14 +341,16 @@ func collectGitHubIssueSearchResults(
 	return activities, nil
 }
 
-func loadGitHubCachedActivities(cutoff time.Time) ([]PRActivity, []IssueActivity, error) {
+// loadGitHubCachedItems reads the cached pull requests (with their review
+// comments) and issues updated after cutoff.
+func loadGitHubCachedItems(cutoff time.Time) ([]PRActivity, map[string][]GitHubPRReviewCommentRecord, []IssueActivity, error) {
 	if config.db == nil {
-		return []PRActivity{}, []IssueActivity{}, nil
+		return []PRActivity{}, nil, []IssueActivity{}, nil
 	}
 
 	allPRs, prLabels, err := config.db.GetAllGitHubPullRequestsWithLabels(config.debugMode)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	activities := make([]PRActivity, 0, len(allPRs))
@@ -372,14 +375,14 @@ func loadGitHubCachedActivities(cutoff time.Time) ([]PRActivity, []IssueActivity
 
 		comments, err := config.db.GetGitHubPRReviewComments(owner, repo, pr.Number)
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
 		prReviewComments[key] = comments
 	}
 
 	allIssues, issueLabels, err := config.db.GetAllGitHubIssuesWithLabels(config.debugMode)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	issueActivities := make([]IssueActivity, 0, len(allIssues))
@@ -402,14 +405,23 @@ func loadGitHubCachedActivities(cutoff time.Time) ([]PRActivity, []IssueActivity
 		})
 	}
 
-	nestedPRs := nestGitHubIssues(activities, issueActivities, prReviewComments)
-	standaloneIssues := filterStandaloneGitHubIssues(nestedPRs, issueActivities)
-	return nestedPRs, standaloneIssues, nil
+	return activities, prReviewComments, issueActivities, nil
+}
+
+// searchGitHubItems runs a search query. With --api graphql the items' details
+// and review comments come back with the results as prefetched data; over REST
+// prefetched is nil and they are fetched per item.
+func searchGitHubItems(ctx context.Context, client *github.Client, query string) ([]*github.Issue, *gitHubPrefetched, error) {
+	if config.apiBackend == "graphql" {
+		return searchGitHubGraphQL(ctx, client, query)
+	}
+	items, err := searchGitHubIssues(ctx, client, query)
+	return items, nil, err
 }
 
 func searchGitHubIssues(ctx context.Context, client *github.Client, query string) ([]*github.Issue, error) {
 	allIssues := make([]*github.Issue, 0)
-	options := &github.SearchOptions{ListOptions: github.ListOptions{PerPage: 100, Page: 1}}
+	options := &github.SearchOptions{ListOptions: github.ListOptions{PerPage: pageSize(), Page: 1}}
 
 	for {
 		result, resp, err := client.Search.Issues(ctx, query, options)
@@ -428,8 +440,8 @@ func searchGitHubIssues(ctx context.Context, client *github.Client, query string
 
 func newGitHubClient(token string) *github.Client {
 	tokenSource := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: strings.TrimSpace(token)})
-	httpClient := oauth2.NewClient(context.Background(), tokenSource)
-	return github.NewClient(httpClient)
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, httpClient())
+	return github.NewClient(oauth2.NewClient(ctx, tokenSource))
 }
 
 func getGitHubPullRequest(ctx context.Context, client *github.Client, owner, repo string, number int) (*github.PullRequest, error) {
@@ -448,9 +460,33 @@ func getGitHubIssue(ctx context.Context, client *github.Client, owner, repo stri
 	return issue, nil
 }
 
+// getGitHubPullRequestPrefetched, getGitHubIssuePrefetched and
+// listGitHubPRReviewCommentsPrefetched return the prefetched data for an item
+// when there is any and call the REST API otherwise.
+func getGitHubPullRequestPrefetched(ctx context.Context, client *github.Client, prefetched *gitHubPrefetched, owner, repo string, number int) (*github.PullRequest, error) {
+	if pr, ok := prefetched.pullRequest(buildGitHubItemKey(owner, repo, number)); ok {
+		return pr, nil
+	}
+	return getGitHubPullRequest(ctx, client, owner, repo, number)
+}
+
+func getGitHubIssuePrefetched(ctx context.Context, client *github.Client, prefetched *gitHubPrefetched, owner, repo string, number int) (*github.Issue, error) {
+	if issue, ok := prefetched.issue(buildGitHubItemKey(owner, repo, number)); ok {
+		return issue, nil
+	}
+	return getGitHubIssue(ctx, client, owner, repo, number)
+}
+
+func listGitHubPRReviewCommentsPrefetched(ctx context.Context, client *github.Client, prefetched *gitHubPrefetched, owner, repo string, number int) ([]*github.PullRequestComment, error) {
+	if comments, ok := prefetched.pullRequestReviewComments(buildGitHubItemKey(owner, repo, number)); ok {
+		return comments, nil
+	}
+	return listGitHubPRReviewComments(ctx, client, owner, repo, number)
+}
+
 func listGitHubPRReviewComments(ctx context.Context, client *github.Client, owner, repo string, number int) ([]*github.PullRequestComment, error) {
 	allComments := make([]*github.PullRequestComment, 0)
-	options := &github.PullRequestListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100, Page: 1}}
+	options := &github.PullRequestListCommentsOptions{ListOptions: github.ListOptions{PerPage: pageSize(), Page: 1}}
 
 	for {
 		comments, resp, err := client.PullRequests.ListComments(ctx, owner, repo, number, options)
@@ -467,6 +503,96 @@ func listGitHubPRReviewComments(ctx context.Context, client *github.Client, owne
 	return allComments, nil
 }
 
+const (
+	checkStatusSuccess = "success"
+	checkStatusFailure = "failure"
+	checkStatusPending = "pending"
+)
+
+// getGitHubCheckStatus combines the check runs and commit statuses of a pull
+// request's head commit. A failed lookup counts toward the error budget and
+// leaves the PR without a status instead of aborting the run.
+func getGitHubCheckStatus(ctx context.Context, client *github.Client, owner, repo, sha string) string {
+	if sha == "" {
+		return ""
+	}
+
+	runs := make([]*github.CheckRun, 0)
+	options := &github.ListCheckRunsOptions{ListOptions: github.ListOptions{PerPage: pageSize(), Page: 1}}
+	for {
+		result, resp, err := client.Checks.ListCheckRunsForRef(ctx, owner, repo, sha, options)
+		if err != nil {
+			config.apiErrorCount.Add(1)
+			if config.debugMode {
+				fmt.Printf("  [GitHub] Failed to list check runs for %s/%s@%s: %v\n", owner, repo, sha, err)
+			}
+			return ""
+		}
+		runs = append(runs, result.CheckRuns...)
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		options.Page = resp.NextPage
+	}
+
+	combined, _, err := client.Repositories.GetCombinedStatus(ctx, owner, repo, sha, nil)
+	if err != nil {
+		config.apiErrorCount.Add(1)
+		if config.debugMode {
+			fmt.Printf("  [GitHub] Failed to get commit status for %s/%s@%s: %v\n", owner, repo, sha, err)
+		}
+		return ""
+	}
+
+	return combineGitHubCheckStatus(combined, runs)
+}
+
+// combineGitHubCheckStatus reduces check runs and the legacy commit statuses
+// to one status: any failure wins over anything still running, which wins over
+// success. It returns "" when the commit has no checks at all.
+func combineGitHubCheckStatus(combined *github.CombinedStatus, runs []*github.CheckRun) string {
+	status := ""
+	merge := func(next string) {
+		switch {
+		case status == checkStatusFailure || next == checkStatusFailure:
+			status = checkStatusFailure
+		case status == checkStatusPending || next == checkStatusPending:
+			status = checkStatusPending
+		default:
+			status = next
+		}
+	}
+
+	for _, run := range runs {
+		if run.GetStatus() != "completed" {
+			merge(checkStatusPending)
+			continue
+		}
+		switch run.GetConclusion() {
+		case "failure", "timed_out", "cancelled", "action_required", "startup_failure":
+			merge(checkStatusFailure)
+		default:
+			// success, neutral and skipped don't block the PR.
+			merge(checkStatusSuccess)
+		}
+	}
+
+	// GitHub reports "pending" for commits without any statuses, so the state
+	// only counts when there is at least one.
+	if combined != nil && combined.GetTotalCount() > 0 {
+		switch combined.GetState() {
+		case "failure", "error":
+			merge(checkStatusFailure)
+		case "pending":
+			merge(checkStatusPending)
+		default:
+			merge(checkStatusSuccess)
+		}
+	}
+
+	return status
+}
+
 func parseGitHubRepoFromSearchItem(item *github.Issue) (string, string, bool) {
 	if item == nil {
 		return "", "", false
@@ -525,11 +651,26 @@ func toMergeRequestModelFromGitHubPR(pr *github.PullRequest) MergeRequestModel {
 		return MergeRequestModel{}
 	}
 
+	createdAt := time.Time{}
+	if pr.CreatedAt != nil {
+		createdAt = pr.CreatedAt.Time
+	}
+
 	updatedAt := time.Time{}
 	if pr.UpdatedAt != nil {
 		updatedAt = pr.UpdatedAt.Time
 	}
 
+	mergedAt := time.Time{}
+	if pr.MergedAt != nil {
+		mergedAt = pr.MergedAt.Time
+	}
+
+	closedAt := time.Time{}
+	if pr.ClosedAt != nil {
+		closedAt = pr.ClosedAt.Time
+	}
+
 	state := strings.ToLower(pr.GetState())
 	if state == "" {
 		state = "open"
@@ -541,14 +682,22 @@ func toMergeRequestModelFromGitHubPR(pr *github.PullRequest) MergeRequestModel {
 	}
 
 	return MergeRequestModel{
-		Number:    pr.GetNumber(),
-		Title:     pr.GetTitle(),
-		Body:      pr.GetBody(),
-		State:     state,
-		UpdatedAt: updatedAt,
-		WebURL:    pr.GetHTMLURL(),
-		UserLogin: userLogin,
-		Merged:    pr.GetMerged(),
+		Number:        pr.GetNumber(),
+		Title:         pr.GetTitle(),
+		Body:          pr.GetBody(),
+		State:         state,
+		CreatedAt:     createdAt,
+		UpdatedAt:     updatedAt,
+		WebURL:        pr.GetHTMLURL(),
+		UserLogin:     userLogin,
+		Merged:        pr.GetMerged(),
+		MergedAt:      mergedAt,
+		ClosedAt:      closedAt,
+		Assignees:     gitHubLogins(pr.Assignees),
+		Reviewers:     gitHubLogins(pr.RequestedReviewers),
+		SourceBranch:  pr.GetHead().GetRef(),
+		TargetBranch:  pr.GetBase().GetRef(),
+		ProjectLabels: gitHubProjectLabels(pr.Labels),
 	}
 }
 
@@ -557,11 +706,21 @@ func toIssueModelFromGitHubIssue(issue *github.Issue) IssueModel {
 		return IssueModel{}
 	}
 
+	createdAt := time.Time{}
+	if issue.CreatedAt != nil {
+		createdAt = issue.CreatedAt.Time
+	}
+
 	updatedAt := time.Time{}
 	if issue.UpdatedAt != nil {
 		updatedAt = issue.UpdatedAt.Time
 	}
 
+	closedAt := time.Time{}
+	if issue.ClosedAt != nil {
+		closedAt = issue.ClosedAt.Time
+	}
+
 	state := strings.ToLower(issue.GetState())
 	if state == "" {
 		state = "open"
@@ -573,14 +732,45 @@ func toIssueModelFromGitHubIssue(issue *github.Issue) IssueModel {
 	}
 
 	return IssueModel{
-		Number:    issue.GetNumber(),
-		Title:     issue.GetTitle(),
-		Body:      issue.GetBody(),
-		State:     state,
-		UpdatedAt: updatedAt,
-		WebURL:    issue.GetHTMLURL(),
-		UserLogin: userLogin,
+		Number:        issue.GetNumber(),
+		Title:         issue.GetTitle(),
+		Body:          issue.GetBody(),
+		State:         state,
+		CreatedAt:     createdAt,
+		UpdatedAt:     updatedAt,
+		ClosedAt:      closedAt,
+		WebURL:        issue.GetHTMLURL(),
+		UserLogin:     userLogin,
+		Assignees:     gitHubLogins(issue.Assignees),
+		ProjectLabels: gitHubProjectLabels(issue.Labels),
+	}
+}
+
+func gitHubLogins(users []*github.User) []string {
+	var logins []string
+	for _, user := range users {
+		if login := user.GetLogin(); login != "" {
+			logins = append(logins, login)
+		}
 	}
+	return logins
+}
+
+// gitHubProjectLabels keeps the labels' names and colors; GitHub sends colors
+// without the leading "#".
+func gitHubProjectLabels(labels []*github.Label) []ProjectLabel {
+	var out []ProjectLabel
+	for _, label := range labels {
+		if label.GetName() == "" {
+			continue
+		}
+		labelColor := label.GetColor()
+		if labelColor != "" && !strings.HasPrefix(labelColor, "#") {
+			labelColor = "#" + labelColor
+		}
+		out = append(out, ProjectLabel{Name: label.GetName(), Color: labelColor})
+	}
+	return out
 }
 
 func toGitHubPRReviewCommentRecord(owner, repo string, prNumber int, comment *github.PullRequestComment) GitHubPRReviewCommentRecord {
@@ -625,6 +815,9 @@ func parseGitHubItemKey(key string) (string, string, int, bool) {
 }
 
 func isGitHubRepoAllowed(owner, repo string) bool {
+	if isRepoExcluded(owner + "/" + repo) {
+		return false
+	}
 	if len(config.allowedRepos) == 0 {
 		return true
 	}
@@ -634,10 +827,91 @@ func isGitHubRepoAllowed(owner, repo string) bool {
 		if strings.ToLower(strings.TrimSpace(allowed)) == target {
 			return true
 		}
+		// Online runs expand owner/* first; cached runs match on the owner.
+		if wildcardOwner, ok := githubWildcardOwner(allowed); ok && strings.EqualFold(wildcardOwner, owner) {
+			return true
+		}
 	}
 	return false
 }
 
+// githubWildcardOwner returns myorg for an allowed repos entry of myorg/*.
+func githubWildcardOwner(entry string) (string, bool) {
+	owner, found := strings.CutSuffix(strings.TrimSpace(entry), "/*")
+	if !found || owner == "" || strings.Contains(owner, "/") {
+		return "", false
+	}
+	return owner, true
+}
+
+// expandGitHubOrgWildcards replaces owner/* entries with the owner's
+// repositories, listed through the organization repositories API or, for
+// personal accounts, the user repositories API. Other entries are kept.
+func expandGitHubOrgWildcards(ctx context.Context, client *github.Client, allowedRepos map[string]bool) (map[string]bool, error) {
+	expanded := make(map[string]bool, len(allowedRepos))
+	for entry := range allowedRepos {
+		owner, ok := githubWildcardOwner(entry)
+		if !ok {
+			expanded[entry] = true
+			continue
+		}
+
+		repos, err := listGitHubOwnerRepos(ctx, client, owner)
+		if err != nil {
+			return nil, fmt.Errorf("expand allowed repos %s: %w", entry, err)
+		}
+		if config.debugMode {
+			fmt.Printf("  [GitHub] %s matches %d repositories\n", entry, len(repos))
+		}
+		for _, repo := range repos {
+			expanded[repo.GetFullName()] = true
+		}
+	}
+	return expanded, nil
+}
+
+func listGitHubOwnerRepos(ctx context.Context, client *github.Client, owner string) ([]*github.Repository, error) {
+	allRepos := make([]*github.Repository, 0)
+	orgOptions := &github.RepositoryListByOrgOptions{Type: "all", ListOptions: github.ListOptions{PerPage: pageSize(), Page: 1}}
+
+	for {
+		repos, resp, err := client.Repositories.ListByOrg(ctx, owner, orgOptions)
+		if err != nil {
+			var errResp *github.ErrorResponse
+			if orgOptions.Page == 1 && errors.As(err, &errResp) && errResp.Response != nil && errResp.Response.StatusCode == http.StatusNotFound {
+				return listGitHubUserRepos(ctx, client, owner)
+			}
+			return nil, fmt.Errorf("list repositories of %s: %w", owner, err)
+		}
+		allRepos = append(allRepos, repos...)
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		orgOptions.Page = resp.NextPage
+	}
+
+	return allRepos, nil
+}
+
+func listGitHubUserRepos(ctx context.Context, client *github.Client, owner string) ([]*github.Repository, error) {
+	allRepos := make([]*github.Repository, 0)
+	options := &github.RepositoryListByUserOptions{Type: "owner", ListOptions: github.ListOptions{PerPage: pageSize(), Page: 1}}
+
+	for {
+		repos, resp, err := client.Repositories.ListByUser(ctx, owner, options)
+		if err != nil {
+			return nil, fmt.Errorf("list repositories of %s: %w", owner, err)
+		}
+		allRepos = append(allRepos, repos...)
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		options.Page = resp.NextPage
+	}
+
+	return allRepos, nil
+}
+
 func nestGitHubIssues(
 	activities []PRActivity,
 	issueActivities []IssueActivity,
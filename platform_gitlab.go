@@ -4,21 +4,33 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"math"
+	"math/rand/v2"
 	"net/http"
 	"net/url"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/fatih/color"
 	gitlab "gitlab.com/gitlab-org/api/client-go"
 )
 
 var retryAfter = time.After
 
+// backoffJitter adds up to half of d on top of a computed backoff, so workers
+// that failed together don't retry together. Waits the server asked for
+// (Retry-After, RateLimit-Reset) are used as given.
+var backoffJitter = func(d time.Duration) time.Duration {
+	if d <= 0 {
+		return d
+	}
+	return d + rand.N(d/2+1)
+}
+
 const defaultGitLabBaseURL = "https://gitlab.com"
 
 func normalizeGitLabBaseURL(raw string) (string, error) {
@@ -55,7 +67,7 @@ func newGitLabClient(token, rawBaseURL string) (*gitlab.Client, string, error) {
 		return nil, "", err
 	}
 
-	client, err := gitlab.NewClient(token, gitlab.WithBaseURL(normalizedBaseURL))
+	client, err := gitlab.NewClient(token, gitlab.WithBaseURL(normalizedBaseURL), gitlab.WithHTTPClient(httpClient()))
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to create GitLab client: %w", err)
 	}
@@ -63,14 +75,107 @@ func newGitLabClient(token, rawBaseURL string) (*gitlab.Client, string, error) {
 	return client, normalizedBaseURL, nil
 }
 
+type gitLabCapabilities struct {
+	Scopes      []string
+	CurrentUser bool
+	ReadAPI     bool
+	Write       bool
+	ExpiresAt   *time.Time
+}
+
+func gitLabCapabilitiesFromScopes(scopes []string) gitLabCapabilities {
+	caps := gitLabCapabilities{Scopes: scopes}
+	for _, scope := range scopes {
+		switch strings.ToLower(strings.TrimSpace(scope)) {
+		case "api":
+			caps.CurrentUser = true
+			caps.ReadAPI = true
+			caps.Write = true
+		case "read_api":
+			caps.CurrentUser = true
+			caps.ReadAPI = true
+		case "read_user":
+			caps.CurrentUser = true
+		}
+	}
+	return caps
+}
+
+// detectGitLabCapabilities inspects the scopes of the configured token. When the
+// instance cannot report them (older versions, OAuth or job tokens) every
+// capability is assumed to be available and errors surface as before. A token
+// GitLab rejects outright, or one that is revoked or expired, is an error so the
+// run stops before the first fetch instead of failing with 401/403s midway.
+func detectGitLabCapabilities(ctx context.Context, client *gitlab.Client) (gitLabCapabilities, error) {
+	assumeAll := gitLabCapabilities{CurrentUser: true, ReadAPI: true, Write: true}
+	if client == nil {
+		return assumeAll, nil
+	}
+
+	token, resp, err := client.PersonalAccessTokens.GetSinglePersonalAccessToken(gitlab.WithContext(ctx))
+	if resp != nil && resp.StatusCode == http.StatusUnauthorized {
+		return gitLabCapabilities{}, fmt.Errorf("GitLab rejected the token (401 Unauthorized); it is invalid, expired or revoked. Create a new token with the read_api scope at %s", personalAccessTokensURL(client))
+	}
+	if err != nil || token == nil {
+		if config.debugMode {
+			fmt.Printf("  [GitLab] Could not determine token scopes, assuming full access: %v\n", err)
+		}
+		return assumeAll, nil
+	}
+
+	if token.Revoked {
+		return gitLabCapabilities{}, fmt.Errorf("GitLab token %q has been revoked. Create a new token with the read_api scope at %s", token.Name, personalAccessTokensURL(client))
+	}
+	if token.ExpiresAt != nil && !time.Time(*token.ExpiresAt).After(time.Now()) {
+		return gitLabCapabilities{}, fmt.Errorf("GitLab token %q expired on %s. Rotate it at %s", token.Name, token.ExpiresAt.String(), personalAccessTokensURL(client))
+	}
+
+	caps := gitLabCapabilitiesFromScopes(token.Scopes)
+	if token.ExpiresAt != nil {
+		expiresAt := time.Time(*token.ExpiresAt)
+		caps.ExpiresAt = &expiresAt
+	}
+	return caps, nil
+}
+
+// personalAccessTokensURL points at the token settings page of the instance the
+// client talks to.
+func personalAccessTokensURL(client *gitlab.Client) string {
+	base := client.BaseURL()
+	return strings.TrimSuffix(base.Scheme+"://"+base.Host+strings.TrimSuffix(base.Path, "/api/v4/"), "/") + "/-/user_settings/personal_access_tokens"
+}
+
+// expiresSoon reports whether the token expires within the given window.
+func (c gitLabCapabilities) expiresSoon(now time.Time, within time.Duration) bool {
+	return c.ExpiresAt != nil && c.ExpiresAt.Sub(now) < within
+}
+
+func (c gitLabCapabilities) disabledFeatures() []string {
+	var disabled []string
+	if !c.CurrentUser {
+		disabled = append(disabled, "current user lookup (needs read_user or read_api); using GITLAB_USERNAME/GITLAB_USER instead")
+	}
+	return disabled
+}
+
+// requireReadAPI fails for tokens that cannot read merge requests and issues,
+// rather than quietly showing the cached feed in their place.
+func (c gitLabCapabilities) requireReadAPI(client *gitlab.Client) error {
+	if c.ReadAPI {
+		return nil
+	}
+	return fmt.Errorf("GitLab token scopes [%s] do not include read_api, which fetching merge requests and issues needs. Create a token with the read_api scope at %s, or pass --local to show the cached feed", strings.Join(c.Scopes, ", "), personalAccessTokensURL(client))
+}
+
 func getPRLabelPriority(label string) int {
 	priorities := map[string]int{
-		"Authored":         1,
-		"Assigned":         2,
-		"Reviewed":         3,
-		"Review Requested": 4,
-		"Commented":        5,
-		"Mentioned":        6,
+		"Authored":           1,
+		"Assigned":           2,
+		"Reviewed":           3,
+		"Review Requested":   4,
+		"Approval Requested": 5,
+		"Commented":          6,
+		"Mentioned":          7,
 	}
 	if priority, ok := priorities[label]; ok {
 		return priority
@@ -108,6 +213,15 @@ func shouldUpdateLabel(currentLabel, newLabel string, isPR bool) bool {
 	return newPriority < currentPriority
 }
 
+// gitLabNoRetry keeps client-go from resending a request GitLab may already
+// have acted on, such as creating a snippet or accepting a merge request: its
+// default policy replays every request that got a 5xx response.
+func gitLabNoRetry() gitlab.RequestOptionFunc {
+	return gitlab.WithRequestRetry(func(context.Context, *http.Response, error) (bool, error) {
+		return false, nil
+	})
+}
+
 func retryWithBackoff(operation func() error, operationName string) error {
 	const (
 		initialBackoff = 1 * time.Second
@@ -117,6 +231,7 @@ func retryWithBackoff(operation func() error, operationName string) error {
 
 	backoff := initialBackoff
 	attempt := 1
+	serverErrors := 0
 	retryCtx := config.ctx
 	if retryCtx == nil {
 		retryCtx = context.Background()
@@ -127,6 +242,11 @@ func retryWithBackoff(operation func() error, operationName string) error {
 		if err == nil {
 			return nil
 		}
+		// A cancelled call (e.g. the project lister stopped by fetchProjectItems)
+		// would fail the same way on every retry.
+		if errors.Is(err, gitlab.ErrNotFound) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, errAPICallBudget) {
+			return err
+		}
 
 		var gitLabErr *gitlab.ErrorResponse
 		var waitTime time.Duration
@@ -145,21 +265,58 @@ func retryWithBackoff(operation func() error, operationName string) error {
 				} else if resetWait, ok := gitLabRateLimitResetWait(gitLabErr.Response.Header.Get("Ratelimit-Reset")); ok {
 					waitTime = resetWait
 				} else {
-					waitTime = time.Duration(math.Min(float64(backoff), float64(maxBackoff)))
+					waitTime = backoffJitter(time.Duration(math.Min(float64(backoff), float64(maxBackoff))))
 				}
 
 				if config.debugMode {
 					fmt.Printf("  [%s] GitLab rate limit hit (attempt %d), waiting %v before retry...\n",
 						operationName, attempt, waitTime.Round(time.Second))
 				}
+				logRun(slog.LevelWarn, "retry", "operation", operationName, "attempt", attempt, "status", statusCode, "wait", waitTime.String())
 			} else if statusCode >= http.StatusInternalServerError && statusCode <= 599 {
+				serverErrors++
+				if serverErrors >= maxConsecutiveServerErrors {
+					logRun(slog.LevelError, "giving up after server errors", "operation", operationName, "attempt", attempt, "status", statusCode)
+					return fmt.Errorf("%w (%s, %d attempts): %w", errGitLabServerUnavailable, operationName, attempt, err)
+				}
 				isTransientServerError = true
-				waitTime = time.Duration(math.Min(float64(backoff), float64(maxBackoff)))
+				waitTime = backoffJitter(time.Duration(math.Min(float64(backoff), float64(maxBackoff))))
 
 				if config.debugMode {
 					fmt.Printf("  [%s] GitLab server error %d (attempt %d), waiting %v before retry...\n",
 						operationName, statusCode, attempt, waitTime)
 				}
+				logRun(slog.LevelWarn, "retry", "operation", operationName, "attempt", attempt, "status", statusCode, "wait", waitTime.String())
+			} else {
+				shouldRetry = false
+			}
+		} else if statusCode, rateLimited, serverWait, ok := gitHubRetryInfo(err); ok {
+			if rateLimited {
+				isRateLimitError = true
+				waitTime = serverWait
+				if waitTime <= 0 {
+					waitTime = backoffJitter(time.Duration(math.Min(float64(backoff), float64(maxBackoff))))
+				}
+
+				if config.debugMode {
+					fmt.Printf("  [%s] GitHub rate limit hit (attempt %d), waiting %v before retry...\n",
+						operationName, attempt, waitTime.Round(time.Second))
+				}
+				logRun(slog.LevelWarn, "retry", "operation", operationName, "attempt", attempt, "status", statusCode, "wait", waitTime.String())
+			} else if statusCode >= http.StatusInternalServerError && statusCode <= 599 {
+				serverErrors++
+				if serverErrors >= maxConsecutiveServerErrors {
+					logRun(slog.LevelError, "giving up after server errors", "operation", operationName, "attempt", attempt, "status", statusCode)
+					return fmt.Errorf("%w (%s, %d attempts): %w", errGitHubServerUnavailable, operationName, attempt, err)
+				}
+				isTransientServerError = true
+				waitTime = backoffJitter(time.Duration(math.Min(float64(backoff), float64(maxBackoff))))
+
+				if config.debugMode {
+					fmt.Printf("  [%s] GitHub server error %d (attempt %d), waiting %v before retry...\n",
+						operationName, statusCode, attempt, waitTime)
+				}
+				logRun(slog.LevelWarn, "retry", "operation", operationName, "attempt", attempt, "status", statusCode, "wait", waitTime.String())
 			} else {
 				shouldRetry = false
 			}
@@ -169,17 +326,28 @@ func retryWithBackoff(operation func() error, operationName string) error {
 				strings.Contains(err.Error(), "403")
 
 			if isRateLimitError {
-				waitTime = time.Duration(math.Min(float64(backoff), float64(maxBackoff)))
+				waitTime = backoffJitter(time.Duration(math.Min(float64(backoff), float64(maxBackoff))))
 				if config.debugMode {
 					fmt.Printf("  [%s] Rate limit hit (attempt %d), waiting %v before retry...\n",
 						operationName, attempt, waitTime)
 				}
+				logRun(slog.LevelWarn, "retry", "operation", operationName, "attempt", attempt, "error", err.Error(), "wait", waitTime.String())
 			}
 		}
 
 		if !shouldRetry {
 			return err
 		}
+		// Only 5xx responses in a row count toward giving up: an attempt the
+		// server answered otherwise (a rate limit) shows it is back, so a later
+		// outage starts a fresh count.
+		if !isTransientServerError {
+			serverErrors = 0
+		}
+		config.fetchCounters.retries.Add(1)
+		if isRateLimitError {
+			config.fetchCounters.rateLimitWaits.Add(1)
+		}
 
 		if isRateLimitError {
 			if config.debugMode {
@@ -192,7 +360,7 @@ func retryWithBackoff(operation func() error, operationName string) error {
 				ticker := time.NewTicker(1 * time.Second)
 				defer ticker.Stop()
 
-				remaining := int(waitTime.Seconds())
+				remaining := int(math.Ceil(waitTime.Seconds()))
 				for remaining > 0 {
 					if config.progress != nil {
 						config.progress.displayWithWarning(fmt.Sprintf("Rate limit hit, retrying in %ds", remaining))
@@ -219,7 +387,7 @@ func retryWithBackoff(operation func() error, operationName string) error {
 				ticker := time.NewTicker(1 * time.Second)
 				defer ticker.Stop()
 
-				remaining := int(waitTime.Seconds())
+				remaining := int(math.Ceil(waitTime.Seconds()))
 				for remaining > 0 {
 					if config.progress != nil {
 						config.progress.displayWithWarning(fmt.Sprintf("API error, retrying in %ds", remaining))
@@ -236,7 +404,7 @@ func retryWithBackoff(operation func() error, operationName string) error {
 
 			backoff = time.Duration(float64(backoff) * backoffFactor)
 		} else {
-			waitTime := time.Duration(math.Min(float64(backoff)/2, float64(5*time.Second)))
+			waitTime := backoffJitter(time.Duration(math.Min(float64(backoff)/2, float64(5*time.Second))))
 
 			if config.debugMode {
 				fmt.Printf("  [%s] Error (attempt %d): %v, waiting %v before retry...\n",
@@ -250,7 +418,7 @@ func retryWithBackoff(operation func() error, operationName string) error {
 				ticker := time.NewTicker(1 * time.Second)
 				defer ticker.Stop()
 
-				remaining := int(waitTime.Seconds())
+				remaining := int(math.Ceil(waitTime.Seconds()))
 				for remaining > 0 {
 					if config.progress != nil {
 						config.progress.displayWithWarning(fmt.Sprintf("API error, retrying in %ds", remaining))
@@ -290,143 +458,79 @@ func gitLabRateLimitResetWait(rawHeader string) (time.Duration, bool) {
 type gitLabProject struct {
 	PathWithNamespace string
 	ID                int64
+	Topics            []string
+	Language          string
+	MergeMethod       string
 }
 
-func fetchAndDisplayGitLabActivity() {
-	startTime := time.Now()
-
-	if config.debugMode {
-		fmt.Println("Fetching data from GitLab...")
-	} else {
-		fmt.Print("Fetching data from GitLab... ")
-	}
-
-	cutoffTime := time.Now().Add(-config.timeRange)
-	var (
-		activities      []PRActivity
-		issueActivities []IssueActivity
-		err             error
-	)
-
-	if config.localMode {
-		activities, issueActivities, err = loadGitLabCachedActivities(cutoffTime)
-	} else {
-		activities, issueActivities, err = fetchGitLabProjectActivities(
-			config.ctx,
-			config.gitlabClient,
-			config.allowedRepos,
-			cutoffTime,
-			config.gitlabUsername,
-			config.gitlabUserID,
-			config.db,
-		)
-	}
-	if err != nil {
-		fmt.Printf("Error fetching GitLab activity: %v\n", err)
-		return
-	}
-
-	if config.debugMode {
-		fmt.Println()
-		fmt.Printf("Total fetch time: %v\n", time.Since(startTime).Round(time.Millisecond))
-		fmt.Printf("Found %d unique merge requests and %d unique issues\n", len(activities), len(issueActivities))
-		fmt.Println()
-	} else {
-		fmt.Print("\r" + strings.Repeat(" ", 80) + "\r")
-	}
+func init() {
+	registerPlatform("gitlab", func() Platform { return newGitLabPlatform() })
+}
 
-	if len(activities) == 0 && len(issueActivities) == 0 {
-		fmt.Println("No open activity found")
-		return
+// newGitLabPlatform sets up a GitLab fetch from config.
+func newGitLabPlatform() *gitLabPlatform {
+	return &gitLabPlatform{
+		client:          config.gitlabClient,
+		allowedRepos:    config.allowedRepos,
+		currentUsername: config.gitlabUsername,
+		currentUserID:   config.gitlabUserID,
+		db:              config.db,
+		notes:           config.gitlabNotes,
 	}
+}
 
-	sort.Slice(activities, func(i, j int) bool {
-		return activities[i].UpdatedAt.After(activities[j].UpdatedAt)
-	})
-	sort.Slice(issueActivities, func(i, j int) bool {
-		return issueActivities[i].UpdatedAt.After(issueActivities[j].UpdatedAt)
-	})
-
-	var openPRs, closedPRs, mergedPRs []PRActivity
-	for _, activity := range activities {
-		if activity.MR.State == "closed" {
-			if activity.MR.Merged {
-				mergedPRs = append(mergedPRs, activity)
-			} else {
-				closedPRs = append(closedPRs, activity)
-			}
-		} else {
-			openPRs = append(openPRs, activity)
-		}
-	}
+// gitLabPlatform scans the allowed projects one by one. ResolveProjects and
+// FetchActivities leave the project IDs, fetched notes and the circuit breaker
+// on it for the online cross-reference linking.
+type gitLabPlatform struct {
+	client          *gitlab.Client
+	allowedRepos    map[string]bool
+	currentUsername string
+	currentUserID   int64
+	db              Database
+	notes           *gitLabNoteCache
+
+	online          bool
+	projects        []gitLabProject
+	projectIDByPath map[string]int64
+	breaker         *projectCircuitBreaker
+}
 
-	var openIssues, closedIssues []IssueActivity
-	for _, issue := range issueActivities {
-		if issue.Issue.State == "closed" {
-			closedIssues = append(closedIssues, issue)
-		} else {
-			openIssues = append(openIssues, issue)
-		}
-	}
+func (p *gitLabPlatform) DisplayName() string {
+	return "GitLab"
+}
 
-	if len(openPRs) > 0 {
-		titleColor := color.New(color.FgHiGreen, color.Bold)
-		fmt.Println(titleColor.Sprint("OPEN PULL REQUESTS:"))
-		fmt.Println("------------------------------------------")
-		for _, activity := range openPRs {
-			displayMergeRequest(activity.Label, activity.Owner, activity.Repo, activity.MR, activity.HasUpdates)
-			if len(activity.Issues) > 0 {
-				for _, issue := range activity.Issues {
-					displayIssue(issue.Label, issue.Owner, issue.Repo, issue.Issue, true, issue.HasUpdates)
-				}
-			}
-		}
+func (p *gitLabPlatform) ResolveProjects(ctx context.Context) error {
+	p.breaker = newProjectCircuitBreaker()
+	projects, err := resolveAllowedGitLabProjects(ctx, p.client, p.allowedRepos, p.db, p.breaker)
+	if err != nil {
+		return err
 	}
+	p.projects = projects
+	p.online = true
+	return nil
+}
 
-	if len(closedPRs) > 0 || len(mergedPRs) > 0 {
-		fmt.Println()
-		titleColor := color.New(color.FgHiRed, color.Bold)
-		fmt.Println(titleColor.Sprint("CLOSED/MERGED PULL REQUESTS:"))
-		fmt.Println("------------------------------------------")
-		for _, activity := range mergedPRs {
-			displayMergeRequest(activity.Label, activity.Owner, activity.Repo, activity.MR, activity.HasUpdates)
-			if len(activity.Issues) > 0 {
-				for _, issue := range activity.Issues {
-					displayIssue(issue.Label, issue.Owner, issue.Repo, issue.Issue, true, issue.HasUpdates)
-				}
-			}
-		}
-		for _, activity := range closedPRs {
-			displayMergeRequest(activity.Label, activity.Owner, activity.Repo, activity.MR, activity.HasUpdates)
-			if len(activity.Issues) > 0 {
-				for _, issue := range activity.Issues {
-					displayIssue(issue.Label, issue.Owner, issue.Repo, issue.Issue, true, issue.HasUpdates)
-				}
-			}
-		}
+func (p *gitLabPlatform) FetchActivities(ctx context.Context, cutoff time.Time) ([]PRActivity, []IssueActivity, error) {
+	if !p.online {
+		return loadGitLabCachedItems(config.db, earliestRepoCutoff(cutoff))
 	}
+	return p.fetchProjectItems(ctx, cutoff)
+}
 
-	if len(openIssues) > 0 {
-		fmt.Println()
-		titleColor := color.New(color.FgHiGreen, color.Bold)
-		fmt.Println(titleColor.Sprint("OPEN ISSUES:"))
-		fmt.Println("------------------------------------------")
-		for _, issue := range openIssues {
-			displayIssue(issue.Label, issue.Owner, issue.Repo, issue.Issue, false, issue.HasUpdates)
-		}
+func (p *gitLabPlatform) LinkCrossReferences(ctx context.Context, activities []PRActivity, issueActivities []IssueActivity) ([]PRActivity, []IssueActivity, error) {
+	if !p.online {
+		return linkGitLabCrossReferencesOffline(p.db, activities, issueActivities)
 	}
-
-	if len(closedIssues) > 0 {
-		fmt.Println()
-		titleColor := color.New(color.FgHiRed, color.Bold)
-		fmt.Println(titleColor.Sprint("CLOSED ISSUES:"))
-		fmt.Println("------------------------------------------")
-		for _, issue := range closedIssues {
-			displayIssue(issue.Label, issue.Owner, issue.Repo, issue.Issue, false, issue.HasUpdates)
-		}
+	if p.breaker == nil {
+		p.breaker = newProjectCircuitBreaker()
 	}
+	defer func() { config.failedProjects = p.breaker.failures() }()
+	return linkGitLabCrossReferencesOnline(ctx, p.client, activities, issueActivities, p.projectIDByPath, p.notes, p.db, p.breaker)
 }
 
+// fetchGitLabProjectActivities runs the whole online GitLab fetch with
+// explicit arguments instead of config.
 func fetchGitLabProjectActivities(
 	ctx context.Context,
 	client *gitlab.Client,
@@ -434,72 +538,209 @@ func fetchGitLabProjectActivities(
 	cutoff time.Time,
 	currentUsername string,
 	currentUserID int64,
-	db *Database,
+	db Database,
 ) ([]PRActivity, []IssueActivity, error) {
-	projects, err := resolveAllowedGitLabProjects(ctx, client, allowedRepos)
+	p := &gitLabPlatform{
+		client:          client,
+		allowedRepos:    allowedRepos,
+		currentUsername: currentUsername,
+		currentUserID:   currentUserID,
+		db:              db,
+	}
+	if err := p.ResolveProjects(ctx); err != nil {
+		return nil, nil, err
+	}
+	activities, issueActivities, err := p.FetchActivities(ctx, cutoff)
 	if err != nil {
 		return nil, nil, err
 	}
+	return p.LinkCrossReferences(ctx, activities, issueActivities)
+}
 
-	currentUsername = strings.TrimSpace(currentUsername)
+// fetchProjectItems lists and labels the merge requests and issues of the
+// resolved projects and saves them to the cache.
+func (p *gitLabPlatform) fetchProjectItems(ctx context.Context, cutoff time.Time) ([]PRActivity, []IssueActivity, error) {
+	client, projects, db := p.client, p.projects, p.db
+	currentUserID := p.currentUserID
+	currentUsername := strings.TrimSpace(p.currentUsername)
 	if currentUsername == "" {
 		return nil, nil, fmt.Errorf("gitlab current username is required")
 	}
 
+	p.projectIDByPath = make(map[string]int64, len(projects))
+	if p.notes == nil {
+		p.notes = newGitLabNoteCache()
+	}
+	if p.breaker == nil {
+		p.breaker = newProjectCircuitBreaker()
+	}
+	// So far the breaker only holds the projects that could not be resolved.
+	requested := len(projects) + len(p.breaker.open)
 	if len(projects) == 0 {
+		if err := p.breaker.allFailed(requested); err != nil {
+			return nil, nil, err
+		}
+		config.failedProjects = p.breaker.failures()
 		return []PRActivity{}, []IssueActivity{}, nil
 	}
 
+	// The bar starts with the first list page(s) of every project; further
+	// pages and the listed items are added as they become known.
+	if config.progress == nil && config.interactive && !config.quiet && !config.debugMode {
+		config.progress = &Progress{}
+	}
+	progress := config.progress
+	firstPagesPerProject := 2
+	if config.apiBackend == "graphql" {
+		firstPagesPerProject = 1
+	}
+	progress.addToTotal(len(projects) * firstPagesPerProject)
+	progress.display()
+
 	activities := make([]PRActivity, 0)
 	issueActivities := make([]IssueActivity, 0)
 	seenMergeRequests := make(map[string]struct{})
 	seenIssues := make(map[string]struct{})
-	projectIDByPath := make(map[string]int64, len(projects))
-	mrNotesByKey := make(map[string][]*gitlab.Note)
+	projectIDByPath, noteCache, breaker := p.projectIDByPath, p.notes, p.breaker
+	projectPathByID := make(map[int64]string, len(projects))
 
-	for _, project := range projects {
-		projectIDByPath[normalizeProjectPathWithNamespace(project.PathWithNamespace)] = project.ID
+	if config.projectBadges == nil {
+		config.projectBadges = make(map[string][]string, len(projects))
 	}
-
 	for _, project := range projects {
-		projectMergeRequests, err := listGitLabProjectMergeRequests(ctx, client, project.ID, cutoff)
+		projectIDByPath[normalizeProjectPathWithNamespace(project.PathWithNamespace)] = project.ID
+		projectPathByID[project.ID] = normalizeProjectPathWithNamespace(project.PathWithNamespace)
+		if badges := gitLabProjectBadges(project); len(badges) > 0 {
+			config.projectBadges[strings.ToLower(normalizeProjectPathWithNamespace(project.PathWithNamespace))] = badges
+		}
+	}
+
+	defer func() { config.failedProjects = breaker.failures() }()
+
+	// With --stream, the items of each project are printed once it is done,
+	// including those fetched before a tripped breaker skipped the rest.
+	streamedMRs, streamedIssues := 0, 0
+	streamProject := func() {
+		streamProjectItems(activities[streamedMRs:], issueActivities[streamedIssues:])
+		streamedMRs, streamedIssues = len(activities), len(issueActivities)
+	}
+
+	// Listing runs on its own goroutine, ahead of label derivation, so the
+	// notes and approvals of one project are fetched while the next project's
+	// merge requests and issues are listed. Returning early stops the lister
+	// and waits for it before config.progress goes away.
+	// Each labeled item advances its project's checkpoint; a run that stops
+	// early leaves them behind for the next one to resume from.
+	checkpoints := loadSyncCheckpoints(db, time.Now())
+	// Keeps the progress of a fetch that stops with an error.
+	defer checkpoints.flush()
+
+	listCtx, stopListing := context.WithCancel(ctx)
+	listed := listGitLabProjectsAhead(listCtx, client, projects, cutoff, checkpoints)
+	defer func() {
+		stopListing()
+		for range listed {
+		}
+	}()
+
+projects:
+	for listing := range listed {
+		streamProject()
+		project := listing.project
+		projectCutoff := repoCutoff(project.PathWithNamespace, cutoff)
+		// Merge request listings only name their labels; the project's label
+		// colors are looked up once, when the first uncolored label shows up.
+		var labelColors map[string]string
+		colorLabels := func(labels []ProjectLabel) {
+			for i := range labels {
+				if labels[i].Color != "" {
+					continue
+				}
+				if labelColors == nil {
+					labelColors = fetchGitLabProjectLabelColors(ctx, client, project.ID)
+				}
+				labels[i].Color = labelColors[strings.ToLower(labels[i].Name)]
+			}
+		}
+		projectMergeRequests, projectIssues, prefetched, err := listing.mergeRequests, listing.issues, listing.prefetched, listing.err
+		// The later chunks of a project skipped in an earlier one are dropped.
+		if breaker.isOpen(project.PathWithNamespace) {
+			continue
+		}
 		if err != nil {
-			return nil, nil, fmt.Errorf("list merge requests for %s: %w", project.PathWithNamespace, err)
+			if breaker.skip(project.PathWithNamespace, err) {
+				continue
+			}
+			return nil, nil, err
+		}
+		progress.addToTotal(len(projectMergeRequests) + len(projectIssues))
+		if listing.first {
+			if checkpoint, ok := checkpoints.resumeFrom(project.PathWithNamespace, projectCutoff); ok {
+				logResume(project.PathWithNamespace, checkpoint)
+			}
+			checkpoints.start(project.PathWithNamespace, projectCutoff)
 		}
+		mergeRequestCutoff, issueCutoff := checkpoints.listingCutoffs(project.PathWithNamespace, projectCutoff)
+		sortGitLabItemsByUpdate(projectMergeRequests, projectIssues)
 
 		for _, item := range projectMergeRequests {
+			progress.increment()
+			progress.display()
+			// Items before a resumed project's checkpoint come from the cache
+			// below, so they are not marked as seen here.
+			model := toMergeRequestModelFromGitLab(item)
+			if model.UpdatedAt.IsZero() || model.UpdatedAt.Before(mergeRequestCutoff) {
+				continue
+			}
 			key := buildGitLabDedupKey(project.PathWithNamespace, "mr", item.IID)
 			if _, exists := seenMergeRequests[key]; exists {
 				continue
 			}
 			seenMergeRequests[key] = struct{}{}
-
-			model := toMergeRequestModelFromGitLab(item)
-			if model.UpdatedAt.IsZero() || model.UpdatedAt.Before(cutoff) {
-				continue
+			model.MergeMethod = project.MergeMethod
+			colorLabels(model.ProjectLabels)
+			// Project-scoped listings return MRs by target project, so keys and
+			// cross-references stay on the target; only the fork path is shown.
+			if item.SourceProjectID != 0 && item.SourceProjectID != project.ID {
+				model.SourceProject = resolveGitLabProjectPathByID(ctx, client, item.SourceProjectID, projectPathByID)
 			}
 
-			label, notes, err := deriveGitLabMergeRequestLabel(ctx, client, project.ID, item, currentUsername, currentUserID)
+			label, notes, err := deriveGitLabMergeRequestLabel(ctx, client, project, item, currentUsername, currentUserID, prefetched, noteCache, db)
 			if err != nil {
-				return nil, nil, fmt.Errorf("derive merge request label for %s!%d: %w", project.PathWithNamespace, item.IID, err)
+				err = fmt.Errorf("derive merge request label for %s!%d: %w", project.PathWithNamespace, item.IID, err)
+				if breaker.skip(project.PathWithNamespace, err) {
+					continue projects
+				}
+				return nil, nil, err
+			}
+			if prefetchedNotes, ok := prefetched.mergeRequestNotes(item.IID); ok && notes == nil {
+				notes = prefetchedNotes
+			}
+			// Assigned and review-requested items skip the note listing; an
+			// SLA on their label needs it for the time of the request.
+			if _, tracked := slaTarget(project.PathWithNamespace, label); tracked && notes == nil {
+				if notes, err = noteCache.mergeRequestNotes(ctx, client, project.ID, item.IID); err != nil {
+					warnGitLabInvolvedAt(project.PathWithNamespace, "!", item.IID, err)
+				}
 			}
+			model.InvolvedAt = gitLabInvolvedAt(notes, currentUsername)
 
 			if db != nil {
-				if err := db.SaveGitLabMergeRequestWithLabel(project.PathWithNamespace, model, label, config.debugMode); err != nil {
-					config.dbErrorCount.Add(1)
-					if config.debugMode {
-						fmt.Printf("  [DB] Warning: Failed to save GitLab MR %s!%d: %v\n", project.PathWithNamespace, item.IID, err)
-					}
+				saveErr := db.SaveGitLabMergeRequestWithLabel(project.PathWithNamespace, model, label, config.debugMode)
+				if saveErr != nil {
+					recordDBWarning("Failed to save GitLab MR %s!%d: %v", project.PathWithNamespace, item.IID, saveErr)
 				}
 				if err := persistGitLabNotes(db, project.PathWithNamespace, "mr", int(item.IID), notes); err != nil {
-					config.dbErrorCount.Add(1)
-					if config.debugMode {
-						fmt.Printf("  [DB] Warning: Failed to save GitLab MR notes %s!%d: %v\n", project.PathWithNamespace, item.IID, err)
-					}
+					recordDBWarning("Failed to save GitLab MR notes %s!%d: %v", project.PathWithNamespace, item.IID, err)
+				} else if saveErr == nil {
+					checkpoints.advance(project.PathWithNamespace, "mr", model.UpdatedAt)
 				}
 			}
 
-			mrNotesByKey[buildGitLabMergeRequestKey(project.PathWithNamespace, model.Number)] = notes
+			// nil means the notes were never fetched; an empty list is a result.
+			if notes != nil {
+				noteCache.storeMergeRequestNotes(project.ID, item.IID, notes)
+			}
 
 			owner, repo, ok := splitGitLabPathWithNamespace(project.PathWithNamespace)
 			if !ok {
@@ -516,40 +757,47 @@ func fetchGitLabProjectActivities(
 			})
 		}
 
-		projectIssues, err := listGitLabProjectIssues(ctx, client, project.ID, cutoff)
-		if err != nil {
-			return nil, nil, fmt.Errorf("list issues for %s: %w", project.PathWithNamespace, err)
-		}
-
 		for _, item := range projectIssues {
+			progress.increment()
+			progress.display()
+			model := toIssueModelFromGitLab(item)
+			if model.UpdatedAt.IsZero() || model.UpdatedAt.Before(issueCutoff) {
+				continue
+			}
 			key := buildGitLabDedupKey(project.PathWithNamespace, "issue", item.IID)
 			if _, exists := seenIssues[key]; exists {
 				continue
 			}
 			seenIssues[key] = struct{}{}
+			colorLabels(model.ProjectLabels)
 
-			model := toIssueModelFromGitLab(item)
-			if model.UpdatedAt.IsZero() || model.UpdatedAt.Before(cutoff) {
-				continue
-			}
-
-			label, notes, err := deriveGitLabIssueLabel(ctx, client, project.ID, item, currentUsername, currentUserID)
+			label, notes, err := deriveGitLabIssueLabel(ctx, client, project.ID, item, currentUsername, currentUserID, prefetched, noteCache)
 			if err != nil {
-				return nil, nil, fmt.Errorf("derive issue label for %s#%d: %w", project.PathWithNamespace, item.IID, err)
+				err = fmt.Errorf("derive issue label for %s#%d: %w", project.PathWithNamespace, item.IID, err)
+				if breaker.skip(project.PathWithNamespace, err) {
+					continue projects
+				}
+				return nil, nil, err
 			}
+			if prefetchedNotes, ok := prefetched.issueNotes(item.IID); ok && notes == nil {
+				notes = prefetchedNotes
+			}
+			if _, tracked := slaTarget(project.PathWithNamespace, label); tracked && notes == nil {
+				if notes, err = noteCache.issueNotes(ctx, client, project.ID, item.IID); err != nil {
+					warnGitLabInvolvedAt(project.PathWithNamespace, "#", item.IID, err)
+				}
+			}
+			model.InvolvedAt = gitLabInvolvedAt(notes, currentUsername)
 
 			if db != nil {
-				if err := db.SaveGitLabIssueWithLabel(project.PathWithNamespace, model, label, config.debugMode); err != nil {
-					config.dbErrorCount.Add(1)
-					if config.debugMode {
-						fmt.Printf("  [DB] Warning: Failed to save GitLab issue %s#%d: %v\n", project.PathWithNamespace, item.IID, err)
-					}
+				saveErr := db.SaveGitLabIssueWithLabel(project.PathWithNamespace, model, label, config.debugMode)
+				if saveErr != nil {
+					recordDBWarning("Failed to save GitLab issue %s#%d: %v", project.PathWithNamespace, item.IID, saveErr)
 				}
 				if err := persistGitLabNotes(db, project.PathWithNamespace, "issue", int(item.IID), notes); err != nil {
-					config.dbErrorCount.Add(1)
-					if config.debugMode {
-						fmt.Printf("  [DB] Warning: Failed to save GitLab issue notes %s#%d: %v\n", project.PathWithNamespace, item.IID, err)
-					}
+					recordDBWarning("Failed to save GitLab issue notes %s#%d: %v", project.PathWithNamespace, item.IID, err)
+				} else if saveErr == nil {
+					checkpoints.advance(project.PathWithNamespace, "issue", model.UpdatedAt)
 				}
 			}
 
@@ -567,12 +815,41 @@ func fetchGitLabProjectActivities(
 				UpdatedAt: model.UpdatedAt,
 			})
 		}
-	}
 
-	activities, issueActivities, err = linkGitLabCrossReferencesOnline(ctx, client, activities, issueActivities, projectIDByPath, mrNotesByKey, db)
-	if err != nil {
+		checkpoints.flush()
+
+		// The items labeled before the checkpoint come from the cache.
+		if !listing.last {
+			continue
+		}
+		resumedMRs, resumedIssues := checkpoints.resumedItems(project.PathWithNamespace, projectCutoff)
+		for _, activity := range resumedMRs {
+			key := buildGitLabDedupKey(project.PathWithNamespace, "mr", int64(activity.MR.Number))
+			if _, exists := seenMergeRequests[key]; exists {
+				continue
+			}
+			seenMergeRequests[key] = struct{}{}
+			activities = append(activities, activity)
+		}
+		for _, issue := range resumedIssues {
+			key := buildGitLabDedupKey(project.PathWithNamespace, "issue", int64(issue.Issue.Number))
+			if _, exists := seenIssues[key]; exists {
+				continue
+			}
+			seenIssues[key] = struct{}{}
+			issueActivities = append(issueActivities, issue)
+		}
+	}
+	// The lister stops quietly on cancellation; don't pass a partial feed off
+	// as complete.
+	if err := ctx.Err(); err != nil {
 		return nil, nil, err
 	}
+	if err := breaker.allFailed(requested); err != nil {
+		return nil, nil, err
+	}
+	checkpoints.finish(breaker.failures())
+	streamProject()
 
 	return activities, issueActivities, nil
 }
@@ -580,10 +857,13 @@ func fetchGitLabProjectActivities(
 func deriveGitLabMergeRequestLabel(
 	ctx context.Context,
 	client *gitlab.Client,
-	projectID int64,
+	project gitLabProject,
 	item *gitlab.BasicMergeRequest,
 	currentUsername string,
 	currentUserID int64,
+	prefetched *gitLabPrefetched,
+	noteCache *gitLabNoteCache,
+	db Database,
 ) (string, []*gitlab.Note, error) {
 	if item == nil {
 		return "Involved", nil, nil
@@ -601,14 +881,20 @@ func deriveGitLabMergeRequestLabel(
 		return currentLabel, nil, nil
 	}
 
-	var approvalState *gitlab.MergeRequestApprovalState
-	err := retryWithBackoff(func() error {
-		var apiErr error
-		approvalState, _, apiErr = client.MergeRequestApprovals.GetApprovalState(projectID, item.IID, gitlab.WithContext(ctx))
-		return apiErr
-	}, fmt.Sprintf("GitLabGetApprovalState %d!%d", projectID, item.IID))
-	if err != nil {
-		return "", nil, err
+	approvalState, ok := prefetched.approvalState(item.IID)
+	if !ok {
+		approvalState, ok = cachedGitLabApprovalState(db, project.PathWithNamespace, item)
+	}
+	if !ok {
+		err := retryWithBackoff(func() error {
+			var apiErr error
+			approvalState, _, apiErr = client.MergeRequestApprovals.GetApprovalState(project.ID, item.IID, gitlab.WithContext(ctx))
+			return apiErr
+		}, fmt.Sprintf("GitLabGetApprovalState %d!%d", project.ID, item.IID))
+		if err != nil {
+			return "", nil, err
+		}
+		storeGitLabApprovalState(db, project.PathWithNamespace, item, approvalState)
 	}
 	if gitLabApprovalStateReviewedByCurrentUser(approvalState, currentUsername, currentUserID) {
 		currentLabel = mergeLabelWithPriority(currentLabel, "Reviewed", true)
@@ -616,6 +902,8 @@ func deriveGitLabMergeRequestLabel(
 
 	if gitLabBasicUserListContains(item.Reviewers, currentUsername, currentUserID) {
 		currentLabel = mergeLabelWithPriority(currentLabel, "Review Requested", true)
+	} else if gitLabApprovalStateRequestsCurrentUser(approvalState, currentUsername, currentUserID) {
+		currentLabel = mergeLabelWithPriority(currentLabel, "Approval Requested", true)
 	}
 
 	if !needsLowerPriorityPRChecks(currentLabel) {
@@ -625,9 +913,13 @@ func deriveGitLabMergeRequestLabel(
 		return currentLabel, nil, nil
 	}
 
-	notes, err := listAllGitLabMergeRequestNotes(ctx, client, projectID, item.IID)
-	if err != nil {
-		return "", nil, err
+	notes, ok := prefetched.mergeRequestNotes(item.IID)
+	if !ok {
+		var err error
+		notes, err = noteCache.mergeRequestNotes(ctx, client, project.ID, item.IID)
+		if err != nil {
+			return "", nil, err
+		}
 	}
 
 	commented, mentioned := gitLabNotesInvolvement(notes, item.Description, currentUsername, currentUserID)
@@ -651,6 +943,8 @@ func deriveGitLabIssueLabel(
 	item *gitlab.Issue,
 	currentUsername string,
 	currentUserID int64,
+	prefetched *gitLabPrefetched,
+	noteCache *gitLabNoteCache,
 ) (string, []*gitlab.Note, error) {
 	if item == nil {
 		return "Involved", nil, nil
@@ -668,9 +962,13 @@ func deriveGitLabIssueLabel(
 		return currentLabel, nil, nil
 	}
 
-	notes, err := listAllGitLabIssueNotes(ctx, client, projectID, item.IID)
-	if err != nil {
-		return "", nil, err
+	notes, ok := prefetched.issueNotes(item.IID)
+	if !ok {
+		var err error
+		notes, err = noteCache.issueNotes(ctx, client, projectID, item.IID)
+		if err != nil {
+			return "", nil, err
+		}
 	}
 
 	commented, mentioned := gitLabNotesInvolvement(notes, item.Description, currentUsername, currentUserID)
@@ -687,7 +985,7 @@ func deriveGitLabIssueLabel(
 	return currentLabel, notes, nil
 }
 
-func persistGitLabNotes(db *Database, projectPath, itemType string, itemIID int, notes []*gitlab.Note) error {
+func persistGitLabNotes(db Database, projectPath, itemType string, itemIID int, notes []*gitlab.Note) error {
 	if db == nil || len(notes) == 0 {
 		return nil
 	}
@@ -710,8 +1008,15 @@ func persistGitLabNotes(db *Database, projectPath, itemType string, itemIID int,
 			NoteID:         int64(note.ID),
 			Body:           note.Body,
 			AuthorUsername: authorUsername,
+			AuthorName:     strings.TrimSpace(author.Name),
 			AuthorID:       authorID,
 		}
+		if note.CreatedAt != nil {
+			record.CreatedAt = *note.CreatedAt
+		}
+		if note.UpdatedAt != nil {
+			record.UpdatedAt = *note.UpdatedAt
+		}
 
 		if err := db.SaveGitLabNote(record, config.debugMode); err != nil {
 			return err
@@ -721,12 +1026,22 @@ func persistGitLabNotes(db *Database, projectPath, itemType string, itemIID int,
 	return nil
 }
 
+// loadGitLabCachedActivities reads the feed from the cache and links it the
+// way --local does.
 func loadGitLabCachedActivities(cutoff time.Time) ([]PRActivity, []IssueActivity, error) {
-	if config.db == nil {
+	activities, issueActivities, err := loadGitLabCachedItems(config.db, cutoff)
+	if err != nil {
+		return nil, nil, err
+	}
+	return linkGitLabCrossReferencesOffline(config.db, activities, issueActivities)
+}
+
+func loadGitLabCachedItems(db Database, cutoff time.Time) ([]PRActivity, []IssueActivity, error) {
+	if db == nil {
 		return []PRActivity{}, []IssueActivity{}, nil
 	}
 
-	allMRs, mrLabels, err := config.db.GetAllGitLabMergeRequestsWithLabels(config.debugMode)
+	allMRs, mrLabels, err := db.GetAllGitLabMergeRequestsWithLabels(config.debugMode)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -757,7 +1072,7 @@ func loadGitLabCachedActivities(cutoff time.Time) ([]PRActivity, []IssueActivity
 		})
 	}
 
-	allIssues, issueLabels, err := config.db.GetAllGitLabIssuesWithLabels(config.debugMode)
+	allIssues, issueLabels, err := db.GetAllGitLabIssuesWithLabels(config.debugMode)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -788,11 +1103,6 @@ func loadGitLabCachedActivities(cutoff time.Time) ([]PRActivity, []IssueActivity
 		})
 	}
 
-	activities, issueActivities, err = linkGitLabCrossReferencesOffline(config.db, activities, issueActivities)
-	if err != nil {
-		return nil, nil, err
-	}
-
 	return activities, issueActivities, nil
 }
 
@@ -809,15 +1119,19 @@ func linkGitLabCrossReferencesOnline(
 	activities []PRActivity,
 	issueActivities []IssueActivity,
 	projectIDByPath map[string]int64,
-	mrNotesByKey map[string][]*gitlab.Note,
-	db *Database,
+	noteCache *gitLabNoteCache,
+	db Database,
+	breaker *projectCircuitBreaker,
 ) ([]PRActivity, []IssueActivity, error) {
 	mrToIssueKeys := make(map[string]map[string]struct{}, len(activities))
+	config.progress.addToTotal(len(activities))
 
 	for _, activity := range activities {
+		config.progress.increment()
+		config.progress.display()
 		projectPath := normalizeProjectPathWithNamespace(gitLabProjectPath(activity.Owner, activity.Repo))
 		projectID, ok := projectIDByPath[projectPath]
-		if !ok {
+		if !ok || breaker.isOpen(projectPath) {
 			continue
 		}
 
@@ -837,21 +1151,27 @@ func linkGitLabCrossReferencesOnline(
 			}
 			continue
 		}
+		config.apiErrorCount.Add(1)
+		if config.debugMode {
+			fmt.Printf("  [GitLab] Could not list issues closed by %s!%d, falling back to references: %v\n", projectPath, activity.MR.Number, err)
+		}
 
 		fallbackKeys := gitLabIssueReferenceKeysFromText(activity.MR.Body, projectPath)
 		if len(fallbackKeys) == 0 {
-			notes := mrNotesByKey[mrKey]
-			if len(notes) == 0 {
-				notes, err = listAllGitLabMergeRequestNotes(ctx, client, projectID, int64(activity.MR.Number))
-				if err == nil {
-					mrNotesByKey[mrKey] = notes
-					if db != nil {
-						if persistErr := persistGitLabNotes(db, projectPath, "mr", activity.MR.Number, notes); persistErr != nil {
-							config.dbErrorCount.Add(1)
-							if config.debugMode {
-								fmt.Printf("  [DB] Warning: Failed to save GitLab MR notes %s!%d: %v\n", projectPath, activity.MR.Number, persistErr)
-							}
-						}
+			notes, fetched := noteCache.cachedMergeRequestNotes(projectID, int64(activity.MR.Number))
+			if !fetched {
+				notes, err = noteCache.mergeRequestNotes(ctx, client, projectID, int64(activity.MR.Number))
+				if breaker.trip(projectPath, err) {
+					continue
+				}
+				if err != nil {
+					config.apiErrorCount.Add(1)
+					if config.debugMode {
+						fmt.Printf("  [GitLab] Could not list notes for %s!%d: %v\n", projectPath, activity.MR.Number, err)
+					}
+				} else if db != nil {
+					if persistErr := persistGitLabNotes(db, projectPath, "mr", activity.MR.Number, notes); persistErr != nil {
+						recordDBWarning("Failed to save GitLab MR notes %s!%d: %v", projectPath, activity.MR.Number, persistErr)
 					}
 				}
 			}
@@ -875,7 +1195,7 @@ func linkGitLabCrossReferencesOnline(
 	return nestedActivities, filterStandaloneGitLabIssues(nestedActivities, issueActivities), nil
 }
 
-func linkGitLabCrossReferencesOffline(db *Database, activities []PRActivity, issueActivities []IssueActivity) ([]PRActivity, []IssueActivity, error) {
+func linkGitLabCrossReferencesOffline(db Database, activities []PRActivity, issueActivities []IssueActivity) ([]PRActivity, []IssueActivity, error) {
 	mrToIssueKeys := make(map[string]map[string]struct{}, len(activities))
 
 	for _, activity := range activities {
@@ -905,7 +1225,7 @@ func linkGitLabCrossReferencesOffline(db *Database, activities []PRActivity, iss
 
 func listGitLabIssuesClosedOnMergeRequest(ctx context.Context, client *gitlab.Client, projectID int64, mergeRequestIID int64) ([]*gitlab.Issue, error) {
 	allIssues := make([]*gitlab.Issue, 0)
-	opts := &gitlab.GetIssuesClosedOnMergeOptions{ListOptions: gitlab.ListOptions{PerPage: 100, Page: 1}}
+	opts := &gitlab.GetIssuesClosedOnMergeOptions{ListOptions: gitlab.ListOptions{PerPage: int64(pageSize()), Page: 1}}
 
 	for {
 		issues, resp, err := client.MergeRequests.GetIssuesClosedOnMerge(projectID, mergeRequestIID, opts, gitlab.WithContext(ctx))
@@ -1087,6 +1407,9 @@ func parseGitLabIssueProjectPath(key string) (string, bool) {
 }
 
 func isGitLabProjectAllowed(projectPath string) bool {
+	if isRepoExcluded(projectPath) {
+		return false
+	}
 	if config.allowedRepos == nil || len(config.allowedRepos) == 0 {
 		return true
 	}
@@ -1113,62 +1436,160 @@ func mergeLabelWithPriority(currentLabel, candidateLabel string, isPR bool) stri
 }
 
 func listAllGitLabMergeRequestNotes(ctx context.Context, client *gitlab.Client, projectID int64, mrIID int64) ([]*gitlab.Note, error) {
-	allNotes := make([]*gitlab.Note, 0)
-	options := &gitlab.ListMergeRequestNotesOptions{
-		ListOptions: gitlab.ListOptions{PerPage: 100, Page: 1},
-	}
-
-	for {
-		var (
-			notes    []*gitlab.Note
-			response *gitlab.Response
-		)
-		err := retryWithBackoff(func() error {
-			var apiErr error
-			notes, response, apiErr = client.Notes.ListMergeRequestNotes(projectID, mrIID, options, gitlab.WithContext(ctx))
-			return apiErr
-		}, fmt.Sprintf("GitLabListMergeRequestNotes %d!%d page %d", projectID, mrIID, options.Page))
-		if err != nil {
-			return nil, err
+	return listAllGitLabNotePages(func(page int64) ([]*gitlab.Note, *gitlab.Response, error) {
+		options := &gitlab.ListMergeRequestNotesOptions{
+			ListOptions: gitlab.ListOptions{PerPage: int64(pageSize()), Page: page},
 		}
-		allNotes = append(allNotes, notes...)
+		return client.Notes.ListMergeRequestNotes(projectID, mrIID, options, gitlab.WithContext(ctx))
+	}, fmt.Sprintf("GitLabListMergeRequestNotes %d!%d", projectID, mrIID))
+}
 
-		if response == nil || response.NextPage == 0 {
-			break
+func listAllGitLabIssueNotes(ctx context.Context, client *gitlab.Client, projectID int64, issueIID int64) ([]*gitlab.Note, error) {
+	return listAllGitLabNotePages(func(page int64) ([]*gitlab.Note, *gitlab.Response, error) {
+		options := &gitlab.ListIssueNotesOptions{
+			ListOptions: gitlab.ListOptions{PerPage: int64(pageSize()), Page: page},
 		}
-		options.Page = response.NextPage
+		return client.Notes.ListIssueNotes(projectID, issueIID, options, gitlab.WithContext(ctx))
+	}, fmt.Sprintf("GitLabListIssueNotes %d#%d", projectID, issueIID))
+}
+
+// gitLabNoteCache keeps the notes fetched during a run, so label derivation,
+// cross-reference linking and each --users member fetch an item's notes only
+// once. An item missing from the cache was never fetched; an empty list is a
+// result. A nil cache fetches without keeping anything. It is not safe for
+// concurrent use.
+type gitLabNoteCache struct {
+	mergeRequests map[gitLabItemRef][]*gitlab.Note
+	issues        map[gitLabItemRef][]*gitlab.Note
+}
+
+// gitLabItemRef identifies a merge request or issue by project ID and IID.
+type gitLabItemRef struct {
+	projectID int64
+	iid       int64
+}
+
+func newGitLabNoteCache() *gitLabNoteCache {
+	return &gitLabNoteCache{
+		mergeRequests: make(map[gitLabItemRef][]*gitlab.Note),
+		issues:        make(map[gitLabItemRef][]*gitlab.Note),
 	}
+}
 
-	return allNotes, nil
+func (c *gitLabNoteCache) cachedMergeRequestNotes(projectID, iid int64) ([]*gitlab.Note, bool) {
+	if c == nil {
+		return nil, false
+	}
+	notes, ok := c.mergeRequests[gitLabItemRef{projectID, iid}]
+	return notes, ok
 }
 
-func listAllGitLabIssueNotes(ctx context.Context, client *gitlab.Client, projectID int64, issueIID int64) ([]*gitlab.Note, error) {
-	allNotes := make([]*gitlab.Note, 0)
-	options := &gitlab.ListIssueNotesOptions{
-		ListOptions: gitlab.ListOptions{PerPage: 100, Page: 1},
+// storeMergeRequestNotes adds notes obtained elsewhere, e.g. prefetched by the
+// GraphQL backend.
+func (c *gitLabNoteCache) storeMergeRequestNotes(projectID, iid int64, notes []*gitlab.Note) {
+	if c == nil {
+		return
 	}
+	c.mergeRequests[gitLabItemRef{projectID, iid}] = notes
+}
 
-	for {
+// mergeRequestNotes returns the notes of a merge request, fetching them on
+// first use.
+func (c *gitLabNoteCache) mergeRequestNotes(ctx context.Context, client *gitlab.Client, projectID, iid int64) ([]*gitlab.Note, error) {
+	if notes, ok := c.cachedMergeRequestNotes(projectID, iid); ok {
+		return notes, nil
+	}
+	notes, err := listAllGitLabMergeRequestNotes(ctx, client, projectID, iid)
+	if err != nil {
+		return nil, err
+	}
+	c.storeMergeRequestNotes(projectID, iid, notes)
+	return notes, nil
+}
+
+// issueNotes returns the notes of an issue, fetching them on first use.
+func (c *gitLabNoteCache) issueNotes(ctx context.Context, client *gitlab.Client, projectID, iid int64) ([]*gitlab.Note, error) {
+	if c != nil {
+		if notes, ok := c.issues[gitLabItemRef{projectID, iid}]; ok {
+			return notes, nil
+		}
+	}
+	notes, err := listAllGitLabIssueNotes(ctx, client, projectID, iid)
+	if err != nil {
+		return nil, err
+	}
+	if c != nil {
+		c.issues[gitLabItemRef{projectID, iid}] = notes
+	}
+	return notes, nil
+}
+
+// maxConcurrentNotePages bounds how many note pages of one item are fetched at
+// the same time.
+const maxConcurrentNotePages = 4
+
+// listAllGitLabNotePages fetches the first page of notes, then the remaining
+// pages in parallel when GitLab reports the total page count. Without
+// X-Total-Pages (very large result sets) it follows X-Next-Page one page at a
+// time. Notes are returned in page order either way.
+func listAllGitLabNotePages(fetchPage func(page int64) ([]*gitlab.Note, *gitlab.Response, error), operationName string) ([]*gitlab.Note, error) {
+	fetch := func(page int64) ([]*gitlab.Note, *gitlab.Response, error) {
 		var (
 			notes    []*gitlab.Note
 			response *gitlab.Response
 		)
 		err := retryWithBackoff(func() error {
 			var apiErr error
-			notes, response, apiErr = client.Notes.ListIssueNotes(projectID, issueIID, options, gitlab.WithContext(ctx))
+			notes, response, apiErr = fetchPage(page)
 			return apiErr
-		}, fmt.Sprintf("GitLabListIssueNotes %d#%d page %d", projectID, issueIID, options.Page))
-		if err != nil {
-			return nil, err
-		}
-		allNotes = append(allNotes, notes...)
+		}, fmt.Sprintf("%s page %d", operationName, page))
+		return notes, response, err
+	}
 
-		if response == nil || response.NextPage == 0 {
-			break
+	allNotes, response, err := fetch(1)
+	if err != nil {
+		return nil, err
+	}
+	if response == nil || response.NextPage == 0 {
+		return allNotes, nil
+	}
+
+	if response.TotalPages <= 1 {
+		for page := response.NextPage; page != 0; page = response.NextPage {
+			var notes []*gitlab.Note
+			notes, response, err = fetch(page)
+			if err != nil {
+				return nil, err
+			}
+			allNotes = append(allNotes, notes...)
+			if response == nil {
+				break
+			}
 		}
-		options.Page = response.NextPage
+		return allNotes, nil
+	}
+
+	pages := make([][]*gitlab.Note, response.TotalPages+1)
+	errs := make([]error, response.TotalPages+1)
+	sem := make(chan struct{}, maxConcurrentNotePages)
+	var wg sync.WaitGroup
+	for page := int64(2); page <= response.TotalPages; page++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			pages[page], _, errs[page] = fetch(page)
+		}()
 	}
+	wg.Wait()
 
+	for page := int64(2); page <= response.TotalPages; page++ {
+		if errs[page] != nil {
+			return nil, errs[page]
+		}
+		allNotes = append(allNotes, pages[page]...)
+	}
 	return allNotes, nil
 }
 
@@ -1194,22 +1615,68 @@ func gitLabNotesInvolvement(notes []*gitlab.Note, description, currentUsername s
 	return commented, mentioned
 }
 
+// gitLabInvolvedAt returns when the current user was last asked to review or
+// assigned, from system notes such as "requested review from @alice and @bob"
+// or "assigned to @alice and unassigned @carol"; zero when no note says so.
+func gitLabInvolvedAt(notes []*gitlab.Note, username string) time.Time {
+	var involvedAt time.Time
+	for _, note := range notes {
+		if note == nil || !note.System || note.CreatedAt == nil || !note.CreatedAt.After(involvedAt) {
+			continue
+		}
+		body := strings.ToLower(note.Body)
+		for _, phrase := range []string{"requested review from ", "assigned to "} {
+			_, users, ok := strings.Cut(body, phrase)
+			if !ok {
+				continue
+			}
+			// The rest of the note may remove other users again.
+			for _, removal := range []string{"unassigned ", "removed review request"} {
+				users, _, _ = strings.Cut(users, removal)
+			}
+			for _, field := range strings.Fields(users) {
+				name, ok := strings.CutPrefix(strings.TrimRight(field, ",."), "@")
+				if ok && matchesGitLabUsername(name, username) {
+					involvedAt = *note.CreatedAt
+				}
+			}
+		}
+	}
+	return involvedAt
+}
+
+func warnGitLabInvolvedAt(projectPath, separator string, iid int64, err error) {
+	config.apiErrorCount.Add(1)
+	if config.debugMode {
+		fmt.Printf("  [GitLab] Warning: failed to read notes of %s%s%d for its SLA: %v\n", projectPath, separator, iid, err)
+	}
+}
+
 func containsGitLabUserMention(text, username string) bool {
-	if text == "" || username == "" {
+	if text == "" {
 		return false
 	}
-	needle := "@" + strings.ToLower(strings.TrimSpace(username))
-	if needle == "@" {
-		return false
+	lowerText := strings.ToLower(text)
+	for _, name := range append([]string{username}, userAliasList()...) {
+		needle := "@" + strings.ToLower(strings.TrimSpace(name))
+		if needle != "@" && strings.Contains(lowerText, needle) {
+			return true
+		}
 	}
-	return strings.Contains(strings.ToLower(text), needle)
+	return false
+}
+
+// matchesGitLabUsername compares usernames case-insensitively and counts the
+// configured user aliases as the current user too.
+func matchesGitLabUsername(candidate, username string) bool {
+	return strings.EqualFold(strings.TrimSpace(candidate), strings.TrimSpace(username)) || isUserAlias(candidate)
 }
 
 func matchesGitLabNoteAuthor(author gitlab.NoteAuthor, username string, userID int64) bool {
 	if userID > 0 && author.ID == userID {
 		return true
 	}
-	return strings.EqualFold(strings.TrimSpace(author.Username), strings.TrimSpace(username))
+	return matchesGitLabUsername(author.Username, username)
 }
 
 func matchesGitLabBasicUser(user *gitlab.BasicUser, username string, userID int64) bool {
@@ -1219,7 +1686,7 @@ func matchesGitLabBasicUser(user *gitlab.BasicUser, username string, userID int6
 	if userID > 0 && user.ID == userID {
 		return true
 	}
-	return strings.EqualFold(strings.TrimSpace(user.Username), strings.TrimSpace(username))
+	return matchesGitLabUsername(user.Username, username)
 }
 
 func matchesGitLabIssueAuthor(author *gitlab.IssueAuthor, username string, userID int64) bool {
@@ -1229,7 +1696,7 @@ func matchesGitLabIssueAuthor(author *gitlab.IssueAuthor, username string, userI
 	if userID > 0 && author.ID == userID {
 		return true
 	}
-	return strings.EqualFold(strings.TrimSpace(author.Username), strings.TrimSpace(username))
+	return matchesGitLabUsername(author.Username, username)
 }
 
 func matchesGitLabIssueAssignee(assignee *gitlab.IssueAssignee, username string, userID int64) bool {
@@ -1239,7 +1706,7 @@ func matchesGitLabIssueAssignee(assignee *gitlab.IssueAssignee, username string,
 	if userID > 0 && assignee.ID == userID {
 		return true
 	}
-	return strings.EqualFold(strings.TrimSpace(assignee.Username), strings.TrimSpace(username))
+	return matchesGitLabUsername(assignee.Username, username)
 }
 
 func gitLabIssueAssigneeListContains(assignees []*gitlab.IssueAssignee, username string, userID int64) bool {
@@ -1275,7 +1742,59 @@ func gitLabApprovalStateReviewedByCurrentUser(state *gitlab.MergeRequestApproval
 	return false
 }
 
-func resolveAllowedGitLabProjects(ctx context.Context, client *gitlab.Client, allowedRepos map[string]bool) ([]gitLabProject, error) {
+func gitLabApprovalStateRequestsCurrentUser(state *gitlab.MergeRequestApprovalState, username string, userID int64) bool {
+	if state == nil {
+		return false
+	}
+	for _, rule := range state.Rules {
+		if rule == nil || rule.Approved {
+			continue
+		}
+		if gitLabBasicUserListContains(rule.ApprovedBy, username, userID) {
+			continue
+		}
+		if gitLabBasicUserListContains(rule.EligibleApprovers, username, userID) || gitLabBasicUserListContains(rule.Users, username, userID) {
+			return true
+		}
+	}
+	return false
+}
+
+// cachedGitLabApprovalState returns the approval state saved for item, as long
+// as the MR has not been updated since. Read errors count as a miss.
+func cachedGitLabApprovalState(db Database, pathWithNamespace string, item *gitlab.BasicMergeRequest) (*gitlab.MergeRequestApprovalState, bool) {
+	if db == nil || item.UpdatedAt == nil {
+		return nil, false
+	}
+	record, found, err := db.GetGitLabApprovalState(pathWithNamespace, item.IID)
+	if err != nil {
+		recordDBWarning("Failed to read cached approval state for %s!%d: %v", pathWithNamespace, item.IID, err)
+		return nil, false
+	}
+	if !found || !record.UpdatedAt.Equal(*item.UpdatedAt) {
+		return nil, false
+	}
+	return record.State, true
+}
+
+func storeGitLabApprovalState(db Database, pathWithNamespace string, item *gitlab.BasicMergeRequest, state *gitlab.MergeRequestApprovalState) {
+	if db == nil || item.UpdatedAt == nil {
+		return
+	}
+	record := GitLabApprovalRecord{UpdatedAt: *item.UpdatedAt, State: state}
+	if err := db.SaveGitLabApprovalState(pathWithNamespace, item.IID, record); err != nil {
+		recordDBWarning("Failed to save approval state for %s!%d: %v", pathWithNamespace, item.IID, err)
+	}
+}
+
+// gitLabProjectCacheTTL is how long a cached GetProject result is reused.
+// Topics, merge method and renames show up after at most this long.
+const gitLabProjectCacheTTL = 7 * 24 * time.Hour
+
+// resolveAllowedGitLabProjects looks up the IDs of the allowed projects. With
+// a breaker, projects that fail to resolve are skipped through it; without
+// one, the first failure is returned.
+func resolveAllowedGitLabProjects(ctx context.Context, client *gitlab.Client, allowedRepos map[string]bool, db Database, breaker *projectCircuitBreaker) ([]gitLabProject, error) {
 	if client == nil {
 		return nil, fmt.Errorf("gitlab client is not configured")
 	}
@@ -1287,7 +1806,7 @@ func resolveAllowedGitLabProjects(ctx context.Context, client *gitlab.Client, al
 	repoPaths := make([]string, 0, len(allowedRepos))
 	for repo := range allowedRepos {
 		normalized := normalizeProjectPathWithNamespace(repo)
-		if normalized != "" {
+		if normalized != "" && !isRepoExcluded(normalized) {
 			repoPaths = append(repoPaths, normalized)
 		}
 	}
@@ -1301,30 +1820,357 @@ func resolveAllowedGitLabProjects(ctx context.Context, client *gitlab.Client, al
 			continue
 		}
 
-		var project *gitlab.Project
+		resolved, ok := cachedGitLabProject(db, pathWithNamespace, time.Now())
+		if !ok {
+			var project *gitlab.Project
+			err := retryWithBackoff(func() error {
+				var apiErr error
+				project, _, apiErr = client.Projects.GetProject(pathWithNamespace, nil, gitlab.WithContext(ctx))
+				return apiErr
+			}, fmt.Sprintf("GitLabGetProject %s", pathWithNamespace))
+			if err != nil {
+				err = fmt.Errorf("resolve project %s: %w", pathWithNamespace, err)
+				if breaker.skip(pathWithNamespace, err) {
+					continue
+				}
+				return nil, err
+			}
+
+			resolved = gitLabProject{
+				PathWithNamespace: pathWithNamespace,
+				ID:                project.ID,
+				Topics:            project.Topics,
+				MergeMethod:       string(project.MergeMethod),
+			}
+			storeGitLabProject(db, pathWithNamespace, project, time.Now())
+		}
+
+		projectIDCache[pathWithNamespace] = resolved.ID
+		if config.groupBy == "project" {
+			resolved.Language = fetchGitLabProjectPrimaryLanguage(ctx, client, resolved.ID)
+		}
+		projects = append(projects, resolved)
+	}
+
+	return projects, nil
+}
+
+// cachedGitLabProject returns the project resolved on an earlier run, unless
+// it is older than gitLabProjectCacheTTL.
+func cachedGitLabProject(db Database, pathWithNamespace string, now time.Time) (gitLabProject, bool) {
+	if db == nil {
+		return gitLabProject{}, false
+	}
+	record, found, err := db.GetGitLabProject(pathWithNamespace)
+	if err != nil {
+		recordDBWarning("Failed to read cached project %s: %v", pathWithNamespace, err)
+		return gitLabProject{}, false
+	}
+	if !found || record.ID == 0 || now.Sub(record.ResolvedAt) > gitLabProjectCacheTTL {
+		return gitLabProject{}, false
+	}
+	return gitLabProject{
+		PathWithNamespace: pathWithNamespace,
+		ID:                record.ID,
+		Topics:            record.Topics,
+		MergeMethod:       record.MergeMethod,
+	}, true
+}
+
+// storeGitLabProject caches a GetProject result under the requested path and,
+// for a renamed or moved project, under its current path as well, so either
+// spelling in the allowed repos resolves without the API.
+func storeGitLabProject(db Database, pathWithNamespace string, project *gitlab.Project, now time.Time) {
+	if db == nil || project == nil {
+		return
+	}
+	record := GitLabProjectRecord{
+		ID:                project.ID,
+		PathWithNamespace: normalizeProjectPathWithNamespace(project.PathWithNamespace),
+		Topics:            project.Topics,
+		MergeMethod:       string(project.MergeMethod),
+		ResolvedAt:        now,
+	}
+	paths := []string{pathWithNamespace}
+	if record.PathWithNamespace != "" && !strings.EqualFold(record.PathWithNamespace, pathWithNamespace) {
+		paths = append(paths, record.PathWithNamespace)
+		if config.debugMode {
+			fmt.Printf("  [GitLab] %s has moved to %s\n", pathWithNamespace, record.PathWithNamespace)
+		}
+	}
+	for _, path := range paths {
+		if err := db.SaveGitLabProject(path, record); err != nil {
+			recordDBWarning("Failed to cache project %s: %v", path, err)
+		}
+	}
+}
+
+func resolveGitLabProjectPathByID(ctx context.Context, client *gitlab.Client, projectID int64, cache map[int64]string) string {
+	if path, ok := cache[projectID]; ok {
+		return path
+	}
+
+	var project *gitlab.Project
+	err := retryWithBackoff(func() error {
+		var apiErr error
+		project, _, apiErr = client.Projects.GetProject(projectID, nil, gitlab.WithContext(ctx))
+		return apiErr
+	}, fmt.Sprintf("GitLabGetProject %d", projectID))
+	if err != nil || project == nil {
+		config.apiErrorCount.Add(1)
+		if config.debugMode {
+			fmt.Printf("  [GitLab] Could not resolve source project %d: %v\n", projectID, err)
+		}
+		cache[projectID] = ""
+		return ""
+	}
+
+	path := normalizeProjectPathWithNamespace(project.PathWithNamespace)
+	cache[projectID] = path
+	return path
+}
+
+// fetchGitLabProjectLabelColors maps the lowercased names of a project's
+// labels, group labels included, to their colors. On failure the labels are
+// shown uncolored.
+func fetchGitLabProjectLabelColors(ctx context.Context, client *gitlab.Client, projectID int64) map[string]string {
+	colors := make(map[string]string)
+	options := &gitlab.ListLabelsOptions{
+		ListOptions:           gitlab.ListOptions{PerPage: int64(pageSize()), Page: 1},
+		IncludeAncestorGroups: gitlab.Ptr(true),
+	}
+	for {
+		var (
+			labels   []*gitlab.Label
+			response *gitlab.Response
+		)
 		err := retryWithBackoff(func() error {
 			var apiErr error
-			project, _, apiErr = client.Projects.GetProject(pathWithNamespace, nil, gitlab.WithContext(ctx))
+			labels, response, apiErr = client.Labels.ListLabels(projectID, options, gitlab.WithContext(ctx))
 			return apiErr
-		}, fmt.Sprintf("GitLabGetProject %s", pathWithNamespace))
+		}, fmt.Sprintf("GitLabListLabels %d page %d", projectID, options.Page))
 		if err != nil {
-			return nil, fmt.Errorf("resolve project %s: %w", pathWithNamespace, err)
+			config.apiErrorCount.Add(1)
+			if config.debugMode {
+				fmt.Printf("  [GitLab] Could not list labels for project %d: %v\n", projectID, err)
+			}
+			return colors
+		}
+		for _, label := range labels {
+			if label != nil {
+				colors[strings.ToLower(label.Name)] = label.Color
+			}
 		}
 
-		projectIDCache[pathWithNamespace] = project.ID
-		projects = append(projects, gitLabProject{PathWithNamespace: pathWithNamespace, ID: project.ID})
+		if response == nil || response.NextPage == 0 {
+			return colors
+		}
+		options.Page = response.NextPage
 	}
+}
 
-	return projects, nil
+func fetchGitLabProjectPrimaryLanguage(ctx context.Context, client *gitlab.Client, projectID int64) string {
+	var languages *gitlab.ProjectLanguages
+	err := retryWithBackoff(func() error {
+		var apiErr error
+		languages, _, apiErr = client.Projects.GetProjectLanguages(projectID, gitlab.WithContext(ctx))
+		return apiErr
+	}, fmt.Sprintf("GitLabGetProjectLanguages %d", projectID))
+	if err != nil || languages == nil {
+		config.apiErrorCount.Add(1)
+		if config.debugMode {
+			fmt.Printf("  [GitLab] Could not fetch languages for project %d: %v\n", projectID, err)
+		}
+		return ""
+	}
+
+	primary := ""
+	var share float32
+	for language, percentage := range *languages {
+		if percentage > share || (percentage == share && language < primary) {
+			primary = language
+			share = percentage
+		}
+	}
+	return primary
+}
+
+func gitLabProjectBadges(project gitLabProject) []string {
+	badges := make([]string, 0, len(project.Topics)+1)
+	if project.Language != "" {
+		badges = append(badges, strings.ToLower(project.Language))
+	}
+	for _, topic := range project.Topics {
+		if topic = strings.TrimSpace(topic); topic != "" {
+			badges = append(badges, topic)
+		}
+	}
+	return badges
+}
+
+// maxListedProjectsAhead bounds how many listed projects (or chunks of one)
+// wait for label derivation, so a slow derivation stage does not hold every
+// listing in memory.
+const maxListedProjectsAhead = 2
+
+// gitLabChunkedWindow is the listing window above which a project's items are
+// listed in monthly chunks, so a long backfill such as --time 1y holds about a
+// month of listings at a time and its checkpoints advance month by month.
+const gitLabChunkedWindow = 90 * 24 * time.Hour
+
+// listedGitLabProject is the listing of one project, or of one chunk of it,
+// passed from the listing stage of fetchProjectItems to label derivation.
+type listedGitLabProject struct {
+	project       gitLabProject
+	mergeRequests []*gitlab.BasicMergeRequest
+	issues        []*gitlab.Issue
+	prefetched    *gitLabPrefetched
+	err           error
+	// first and last mark the project's first and last chunk; both are set
+	// for a project listed in one go.
+	first, last bool
+}
+
+// gitLabListingChunks returns the start of each chunk a project's listing
+// from `from` is split into, oldest first: one per month when the run's
+// window and this listing are longer than gitLabChunkedWindow, otherwise just
+// from. Each chunk ends where the next
+// one starts and the last has no end, so items updated while fetching are
+// still listed. GraphQL and --max-items listings (which keep the newest items
+// of the whole window) are not chunked.
+func gitLabListingChunks(from, now time.Time) []time.Time {
+	starts := []time.Time{from}
+	if !config.chunkedListings || config.apiBackend == "graphql" || config.maxItems > 0 || now.Sub(from) <= gitLabChunkedWindow {
+		return starts
+	}
+	for months := 1; from.AddDate(0, months, 0).Before(now); months++ {
+		starts = append(starts, from.AddDate(0, months, 0))
+	}
+	return starts
+}
+
+// listGitLabProjectsAhead lists the projects in order on a goroutine and sends
+// each listing, failures included, on the returned channel. The channel is
+// closed after the last project or once ctx is cancelled. Projects resumed from
+// a checkpoint are only listed from there, and long windows are sent chunk by
+// chunk, oldest first; a failed chunk ends its project's listing.
+func listGitLabProjectsAhead(ctx context.Context, client *gitlab.Client, projects []gitLabProject, cutoff time.Time, checkpoints *syncCheckpoints) <-chan listedGitLabProject {
+	listed := make(chan listedGitLabProject, maxListedProjectsAhead)
+	go func() {
+		defer close(listed)
+		for _, project := range projects {
+			mergeRequestCutoff, issueCutoff := checkpoints.listingCutoffs(project.PathWithNamespace, repoCutoff(project.PathWithNamespace, cutoff))
+			starts := gitLabListingChunks(earlierTime(mergeRequestCutoff, issueCutoff), time.Now())
+			// Every chunk past the first lists its own first pages.
+			config.progress.addToTotal(2 * (len(starts) - 1))
+			for i, start := range starts {
+				listing := listedGitLabProject{project: project, first: i == 0, last: i == len(starts)-1}
+				var end time.Time
+				if !listing.last {
+					end = starts[i+1]
+				}
+				listing.mergeRequests, listing.issues, listing.prefetched, listing.err = listGitLabProjectItems(ctx, client, project,
+					laterTime(mergeRequestCutoff, start), laterTime(issueCutoff, start), end)
+				select {
+				case listed <- listing:
+				case <-ctx.Done():
+					return
+				}
+				if listing.err != nil {
+					break
+				}
+			}
+		}
+	}()
+	return listed
+}
+
+// listGitLabProjectItems lists a project's merge requests updated after
+// mergeRequestCutoff and issues updated after issueCutoff, up to end unless it
+// is zero. With --api graphql, approvals and notes come back in the same
+// queries (from the earlier cutoff, never chunked) and are returned as
+// prefetched data; over REST prefetched is nil and they are fetched per item
+// while labeling.
+func listGitLabProjectItems(ctx context.Context, client *gitlab.Client, project gitLabProject, mergeRequestCutoff, issueCutoff, end time.Time) ([]*gitlab.BasicMergeRequest, []*gitlab.Issue, *gitLabPrefetched, error) {
+	if config.apiBackend == "graphql" {
+		mergeRequests, issues, prefetched, err := fetchGitLabProjectGraphQL(ctx, client, project.PathWithNamespace, earlierTime(mergeRequestCutoff, issueCutoff))
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("query %s via GraphQL: %w", project.PathWithNamespace, err)
+		}
+		config.progress.increment()
+		config.progress.display()
+		logMaxItems(project.PathWithNamespace, len(mergeRequests), len(issues))
+		return mergeRequests, issues, prefetched, nil
+	}
+
+	// A chunk that ends before a resumed checkpoint has nothing left to list.
+	var mergeRequests []*gitlab.BasicMergeRequest
+	var issues []*gitlab.Issue
+	var err error
+	if end.IsZero() || mergeRequestCutoff.Before(end) {
+		mergeRequests, err = listGitLabProjectMergeRequests(ctx, client, project.ID, mergeRequestCutoff, end)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("list merge requests for %s: %w", project.PathWithNamespace, err)
+		}
+	} else {
+		config.progress.increment()
+		config.progress.display()
+	}
+	if end.IsZero() || issueCutoff.Before(end) {
+		issues, err = listGitLabProjectIssues(ctx, client, project.ID, issueCutoff, end)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("list issues for %s: %w", project.PathWithNamespace, err)
+		}
+	} else {
+		config.progress.increment()
+		config.progress.display()
+	}
+	logMaxItems(project.PathWithNamespace, len(mergeRequests), len(issues))
+	return mergeRequests, issues, nil, nil
+}
+
+// reachedMaxItems reports whether a listing has collected the --max-items
+// items a project is capped at. Capped listings are ordered newest update
+// first, so the items kept are the most recently updated ones.
+func reachedMaxItems(listed int) bool {
+	return config.maxItems > 0 && listed >= config.maxItems
+}
+
+// capGitLabItems drops the items a listing collected past --max-items.
+func capGitLabItems[T any](items []T) []T {
+	if reachedMaxItems(len(items)) {
+		return items[:config.maxItems]
+	}
+	return items
+}
+
+// logMaxItems reports a project whose merge requests or issues were cut off
+// at --max-items.
+func logMaxItems(projectPath string, mergeRequests, issues int) {
+	if !reachedMaxItems(mergeRequests) && !reachedMaxItems(issues) {
+		return
+	}
+	if config.debugMode {
+		fmt.Printf("  [GitLab] %s: kept the %d most recently updated merge requests and %d issues (--max-items %d)\n",
+			projectPath, mergeRequests, issues, config.maxItems)
+	}
+	logRun(slog.LevelInfo, "project capped", "project", projectPath,
+		"merge_requests", mergeRequests, "issues", issues, "max_items", config.maxItems)
 }
 
-func listGitLabProjectMergeRequests(ctx context.Context, client *gitlab.Client, projectID int64, cutoff time.Time) ([]*gitlab.BasicMergeRequest, error) {
+func listGitLabProjectMergeRequests(ctx context.Context, client *gitlab.Client, projectID int64, cutoff, before time.Time) ([]*gitlab.BasicMergeRequest, error) {
 	allItems := make([]*gitlab.BasicMergeRequest, 0)
 	options := &gitlab.ListProjectMergeRequestsOptions{
-		ListOptions:  gitlab.ListOptions{PerPage: 100, Page: 1},
+		ListOptions:  gitlab.ListOptions{PerPage: int64(pageSize()), Page: 1},
 		State:        gitlab.Ptr("all"),
 		UpdatedAfter: &cutoff,
 	}
+	if !before.IsZero() {
+		options.UpdatedBefore = &before
+	}
+	if config.maxItems > 0 {
+		options.OrderBy, options.Sort = gitlab.Ptr("updated_at"), gitlab.Ptr("desc")
+	}
 
 	for {
 		var (
@@ -1339,23 +2185,31 @@ func listGitLabProjectMergeRequests(ctx context.Context, client *gitlab.Client,
 		if err != nil {
 			return nil, err
 		}
+		trackGitLabListPage(config.progress, response, options.Page)
 		allItems = append(allItems, items...)
 
-		if response == nil || response.NextPage == 0 {
+		if response == nil || response.NextPage == 0 || reachedMaxItems(len(allItems)) {
 			break
 		}
 		options.Page = response.NextPage
 	}
 
-	return allItems, nil
+	return capGitLabItems(allItems), nil
 }
 
-func listGitLabProjectIssues(ctx context.Context, client *gitlab.Client, projectID int64, cutoff time.Time) ([]*gitlab.Issue, error) {
+func listGitLabProjectIssues(ctx context.Context, client *gitlab.Client, projectID int64, cutoff, before time.Time) ([]*gitlab.Issue, error) {
 	allItems := make([]*gitlab.Issue, 0)
 	options := &gitlab.ListProjectIssuesOptions{
-		ListOptions:  gitlab.ListOptions{PerPage: 100, Page: 1},
-		State:        gitlab.Ptr("all"),
-		UpdatedAfter: &cutoff,
+		ListOptions:      gitlab.ListOptions{PerPage: int64(pageSize()), Page: 1},
+		State:            gitlab.Ptr("all"),
+		UpdatedAfter:     &cutoff,
+		WithLabelDetails: gitlab.Ptr(true),
+	}
+	if !before.IsZero() {
+		options.UpdatedBefore = &before
+	}
+	if config.maxItems > 0 {
+		options.OrderBy, options.Sort = gitlab.Ptr("updated_at"), gitlab.Ptr("desc")
 	}
 
 	for {
@@ -1371,15 +2225,34 @@ func listGitLabProjectIssues(ctx context.Context, client *gitlab.Client, project
 		if err != nil {
 			return nil, err
 		}
+		trackGitLabListPage(config.progress, response, options.Page)
 		allItems = append(allItems, items...)
 
-		if response == nil || response.NextPage == 0 {
+		if response == nil || response.NextPage == 0 || reachedMaxItems(len(allItems)) {
 			break
 		}
 		options.Page = response.NextPage
 	}
 
-	return allItems, nil
+	return capGitLabItems(allItems), nil
+}
+
+// trackGitLabListPage counts a fetched list page on the progress bar. The
+// first page announces the remaining ones when GitLab sends X-Total-Pages
+// (omitted for large result sets); otherwise each next page is added once it
+// is known to exist.
+func trackGitLabListPage(progress *Progress, response *gitlab.Response, page int64) {
+	switch {
+	case response == nil || response.NextPage == 0:
+	case response.TotalPages > 0:
+		if page == 1 {
+			progress.addToTotal(int(response.TotalPages) - 1)
+		}
+	default:
+		progress.addToTotal(1)
+	}
+	progress.increment()
+	progress.display()
 }
 
 func normalizeProjectPathWithNamespace(repo string) string {
@@ -1424,25 +2297,49 @@ func toMergeRequestModelFromGitLab(item *gitlab.BasicMergeRequest) MergeRequestM
 		normalizedState = "closed"
 	}
 
+	createdAt := time.Time{}
+	if item.CreatedAt != nil {
+		createdAt = *item.CreatedAt
+	}
+
 	updatedAt := time.Time{}
 	if item.UpdatedAt != nil {
 		updatedAt = *item.UpdatedAt
 	}
 
+	mergedAt := time.Time{}
+	if item.MergedAt != nil {
+		mergedAt = *item.MergedAt
+	}
+
+	closedAt := time.Time{}
+	if item.ClosedAt != nil {
+		closedAt = *item.ClosedAt
+	}
+
 	userLogin := ""
 	if item.Author != nil {
 		userLogin = item.Author.Username
 	}
 
 	return MergeRequestModel{
-		Number:    int(item.IID),
-		Title:     item.Title,
-		Body:      item.Description,
-		State:     normalizedState,
-		UpdatedAt: updatedAt,
-		WebURL:    item.WebURL,
-		UserLogin: userLogin,
-		Merged:    merged,
+		Number:        int(item.IID),
+		Title:         item.Title,
+		Body:          item.Description,
+		State:         normalizedState,
+		CreatedAt:     createdAt,
+		UpdatedAt:     updatedAt,
+		WebURL:        item.WebURL,
+		UserLogin:     userLogin,
+		Merged:        merged,
+		MergedAt:      mergedAt,
+		ClosedAt:      closedAt,
+		Squash:        item.Squash || item.SquashOnMerge,
+		Assignees:     gitLabBasicUsernames(item.Assignees, item.Assignee),
+		Reviewers:     gitLabBasicUsernames(item.Reviewers, nil),
+		SourceBranch:  item.SourceBranch,
+		TargetBranch:  item.TargetBranch,
+		ProjectLabels: gitLabProjectLabels(item.Labels, item.LabelDetails),
 	}
 }
 
@@ -1457,23 +2354,90 @@ func toIssueModelFromGitLab(item *gitlab.Issue) IssueModel {
 		normalizedState = "closed"
 	}
 
+	createdAt := time.Time{}
+	if item.CreatedAt != nil {
+		createdAt = *item.CreatedAt
+	}
+
 	updatedAt := time.Time{}
 	if item.UpdatedAt != nil {
 		updatedAt = *item.UpdatedAt
 	}
 
+	closedAt := time.Time{}
+	if item.ClosedAt != nil {
+		closedAt = *item.ClosedAt
+	}
+
 	userLogin := ""
 	if item.Author != nil {
 		userLogin = item.Author.Username
 	}
 
+	dueDate := time.Time{}
+	if item.DueDate != nil {
+		dueDate = time.Time(*item.DueDate)
+	}
+
 	return IssueModel{
-		Number:    int(item.IID),
-		Title:     item.Title,
-		Body:      item.Description,
-		State:     normalizedState,
-		UpdatedAt: updatedAt,
-		WebURL:    item.WebURL,
-		UserLogin: userLogin,
+		Number:        int(item.IID),
+		Title:         item.Title,
+		Body:          item.Description,
+		State:         normalizedState,
+		CreatedAt:     createdAt,
+		UpdatedAt:     updatedAt,
+		ClosedAt:      closedAt,
+		WebURL:        item.WebURL,
+		UserLogin:     userLogin,
+		DueDate:       dueDate,
+		Weight:        int(item.Weight),
+		Assignees:     gitLabIssueAssigneeUsernames(item.Assignees, item.Assignee),
+		ProjectLabels: gitLabProjectLabels(item.Labels, item.LabelDetails),
+	}
+}
+
+// gitLabProjectLabels keeps the label names in GitLab's order, with colors
+// when the listing included label details.
+func gitLabProjectLabels(names gitlab.Labels, details []*gitlab.LabelDetails) []ProjectLabel {
+	colors := make(map[string]string, len(details))
+	for _, detail := range details {
+		if detail != nil {
+			colors[detail.Name] = detail.Color
+		}
+	}
+	var labels []ProjectLabel
+	for _, name := range names {
+		if name != "" {
+			labels = append(labels, ProjectLabel{Name: name, Color: colors[name]})
+		}
+	}
+	return labels
+}
+
+// gitLabBasicUsernames lists the assignees' usernames; the single assignee
+// field is only used when the list is empty (older instances).
+func gitLabBasicUsernames(users []*gitlab.BasicUser, single *gitlab.BasicUser) []string {
+	if len(users) == 0 && single != nil {
+		users = []*gitlab.BasicUser{single}
+	}
+	var usernames []string
+	for _, user := range users {
+		if user != nil && user.Username != "" {
+			usernames = append(usernames, user.Username)
+		}
+	}
+	return usernames
+}
+
+func gitLabIssueAssigneeUsernames(assignees []*gitlab.IssueAssignee, single *gitlab.IssueAssignee) []string {
+	if len(assignees) == 0 && single != nil {
+		assignees = []*gitlab.IssueAssignee{single}
+	}
+	var usernames []string
+	for _, assignee := range assignees {
+		if assignee != nil && assignee.Username != "" {
+			usernames = append(usernames, assignee.Username)
+		}
 	}
+	return usernames
 }
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// resolveProfile returns the --profile name, falling back to GIT_FEED_PROFILE.
+// Profile names are case-insensitive, like every other .env section, and end
+// up in the cache file name, so only letters, digits, '-' and '_' are allowed.
+func resolveProfile(profileFlag string) (string, error) {
+	name := strings.TrimSpace(profileFlag)
+	if name == "" {
+		name = strings.TrimSpace(os.Getenv("GIT_FEED_PROFILE"))
+	}
+	name = strings.ToLower(name)
+
+	for _, r := range name {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' && r != '_' {
+			return "", fmt.Errorf("invalid profile name %q (use letters, digits, '-' and '_')", name)
+		}
+	}
+	return name, nil
+}
+
+// applyEnvFileProfile exports the KEY=value entries of the [profile.<name>]
+// section and reports whether the section exists. It runs before loadEnvFile,
+// so profile settings beat the top-level .env values while real environment
+// variables still win over both.
+func applyEnvFileProfile(path, profile string) (bool, error) {
+	entries, err := loadEnvFileSection(path, "profile."+profile)
+	if err != nil {
+		return false, err
+	}
+
+	for key, value := range entries {
+		if _, exists := os.LookupEnv(key); exists {
+			continue
+		}
+		os.Setenv(key, value)
+	}
+	return len(entries) > 0, nil
+}
+
+// profileDBFileName keeps each profile's cache apart from the default one, e.g.
+// gitlab-work.db next to gitlab.db.
+func profileDBFileName(platform, profile string) string {
+	if profile == "" {
+		return platform + ".db"
+	}
+	return platform + "-" + profile + ".db"
+}
+
+// hostDBFileName adds the GitLab host to a cache file name, e.g.
+// gitlab@gitlab.example.com.db or gitlab-work@gitlab.example.com.db. gitlab.com
+// keeps the plain name, so existing caches stay where they are. Ports are kept
+// with '_' instead of ':', which is not allowed in Windows file names.
+func hostDBFileName(dbFileName, gitlabBaseURL string) string {
+	parsed, err := url.Parse(gitlabBaseURL)
+	if err != nil || parsed.Host == "" {
+		return dbFileName
+	}
+	host := strings.ToLower(parsed.Host)
+	if host == "gitlab.com" {
+		return dbFileName
+	}
+	host = strings.ReplaceAll(host, ":", "_")
+	return strings.TrimSuffix(dbFileName, ".db") + "@" + host + ".db"
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// defaultCacheRetention is how long cached items are kept after their last
+// update when CACHE_RETENTION is not set.
+const defaultCacheRetention = "90d"
+
+// autoPruneInterval limits the automatic prune to once a day per cache file.
+const autoPruneInterval = 24 * time.Hour
+
+var lastPruneKey = []byte("last_prune")
+
+type pruneStats struct {
+	Items int
+	Notes int
+}
+
+// resolveCacheRetention parses CACHE_RETENTION (a --time style range such as
+// 90d or 6m). "off" or "0" disable the automatic prune.
+func resolveCacheRetention() (time.Duration, bool, error) {
+	value := strings.ToLower(strings.TrimSpace(os.Getenv("CACHE_RETENTION")))
+	switch value {
+	case "":
+		value = defaultCacheRetention
+	case "off", "0":
+		return 0, false, nil
+	}
+	retention, err := parseTimeRange(value)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid CACHE_RETENTION: %w", err)
+	}
+	return retention, true, nil
+}
+
+// Prune deletes the cached merge requests, pull requests and issues last
+// updated before cutoff, together with their notes, review comments and
+// approval states, and records when it ran. On a cache locked by another run
+// only the snapshot is pruned; deletions are not queued.
+func (d *boltDatabase) Prune(cutoff, now time.Time) (pruneStats, error) {
+	var stats pruneStats
+	err := d.db.Update(func(tx *bolt.Tx) error {
+		pruned := make(map[string]bool)
+		for _, bucket := range cachedItemBuckets {
+			keys, err := deleteMatchingKeys(tx.Bucket(bucket), func(v []byte) (bool, error) {
+				v, err := d.decode(v)
+				if err != nil {
+					return false, err
+				}
+				updatedAt, err := cachedItemUpdatedAt(v)
+				if err != nil {
+					return false, err
+				}
+				return !updatedAt.IsZero() && updatedAt.Before(cutoff), nil
+			})
+			if err != nil {
+				return fmt.Errorf("prune %s: %w", bucket, err)
+			}
+			for _, key := range keys {
+				pruned[key] = true
+			}
+			stats.Items += len(keys)
+		}
+
+		notes, err := deleteMatchingKeys(tx.Bucket(gitlabNotesBkt), func(v []byte) (bool, error) {
+			v, err := d.decode(v)
+			if err != nil {
+				return false, err
+			}
+			var record GitLabNoteRecord
+			if err := json.Unmarshal(v, &record); err != nil {
+				return false, err
+			}
+			return pruned[gitLabNoteItemKey(record)], nil
+		})
+		if err != nil {
+			return fmt.Errorf("prune %s: %w", gitlabNotesBkt, err)
+		}
+		stats.Notes += len(notes)
+
+		comments, err := deleteMatchingKeys(tx.Bucket(githubCommentsBkt), func(v []byte) (bool, error) {
+			v, err := d.decode(v)
+			if err != nil {
+				return false, err
+			}
+			var record GitHubPRReviewCommentRecord
+			if err := json.Unmarshal(v, &record); err != nil {
+				return false, err
+			}
+			return pruned[buildGitHubItemKey(record.Owner, record.Repo, record.PRNumber)], nil
+		})
+		if err != nil {
+			return fmt.Errorf("prune %s: %w", githubCommentsBkt, err)
+		}
+		stats.Notes += len(comments)
+
+		// Approval states are keyed like their merge request.
+		approvals := tx.Bucket(gitlabApprovalsBkt)
+		for key := range pruned {
+			if err := approvals.Delete([]byte(key)); err != nil {
+				return fmt.Errorf("prune %s: %w", gitlabApprovalsBkt, err)
+			}
+		}
+
+		return tx.Bucket(metaBkt).Put(lastPruneKey, []byte(now.UTC().Format(time.RFC3339)))
+	})
+	if err != nil {
+		return pruneStats{}, err
+	}
+	return stats, nil
+}
+
+// LastPrune reports when Prune last ran on this cache file; zero if never.
+func (d *boltDatabase) LastPrune() (time.Time, error) {
+	return d.metaTime(lastPruneKey)
+}
+
+// deleteMatchingKeys deletes the entries of b whose value matches and returns
+// their keys. Keys are collected first since a bucket cannot be modified while
+// it is being iterated.
+func deleteMatchingKeys(b *bolt.Bucket, match func(v []byte) (bool, error)) ([]string, error) {
+	if b == nil {
+		return nil, nil
+	}
+
+	var keys []string
+	err := b.ForEach(func(k, v []byte) error {
+		ok, err := match(v)
+		if err != nil {
+			return fmt.Errorf("%s: %w", k, err)
+		}
+		if ok {
+			keys = append(keys, string(k))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		if err := b.Delete([]byte(key)); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+// cachedItemUpdatedAt reads UpdatedAt from any cached merge request, pull
+// request or issue, whether wrapped with its label or in the legacy unwrapped
+// GitHub form.
+func cachedItemUpdatedAt(value []byte) (time.Time, error) {
+	var record struct {
+		UpdatedAt time.Time
+		MR        *struct{ UpdatedAt time.Time }
+		PR        *struct{ UpdatedAt time.Time }
+		Issue     *struct{ UpdatedAt time.Time }
+	}
+	if err := json.Unmarshal(value, &record); err != nil {
+		return time.Time{}, err
+	}
+
+	switch {
+	case record.MR != nil:
+		return record.MR.UpdatedAt, nil
+	case record.PR != nil:
+		return record.PR.UpdatedAt, nil
+	case record.Issue != nil:
+		return record.Issue.UpdatedAt, nil
+	}
+	return record.UpdatedAt, nil
+}
+
+// startAutoPrune prunes the cache in the background at most once per
+// autoPruneInterval. Items inside the requested activity window are always
+// kept, even when the window reaches further back than the retention. The
+// returned channel is closed once the prune has finished or was skipped. It
+// runs while main is still filling in config, hence the debugMode parameter.
+func startAutoPrune(db Database, retention time.Duration, windowStart, now time.Time, debugMode bool) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		lastPrune, err := db.LastPrune()
+		if err != nil {
+			config.dbErrorCount.Add(1)
+			if debugMode {
+				fmt.Printf("  [DB] Warning: Failed to read last prune time: %v\n", err)
+			}
+			logRun(slog.LevelWarn, "cache warning", "message", fmt.Sprintf("Failed to read last prune time: %v", err))
+			return
+		}
+		if now.Sub(lastPrune) < autoPruneInterval {
+			return
+		}
+
+		cutoff := now.Add(-retention)
+		if windowStart.Before(cutoff) {
+			cutoff = windowStart
+		}
+		stats, err := db.Prune(cutoff, now)
+		if err != nil {
+			config.dbErrorCount.Add(1)
+			if debugMode {
+				fmt.Printf("  [DB] Warning: Failed to prune cache: %v\n", err)
+			}
+			logRun(slog.LevelWarn, "cache warning", "message", fmt.Sprintf("Failed to prune cache: %v", err))
+			return
+		}
+		if debugMode {
+			fmt.Printf("  [DB] Pruned %d items and %d notes last updated before %s\n", stats.Items, stats.Notes, cutoff.Format("2006-01-02"))
+		}
+		logRun(slog.LevelInfo, "cache pruned", "items", stats.Items, "notes", stats.Notes, "cutoff", cutoff)
+	}()
+	return done
+}
+
+// runCleanCommand prunes the cache right away, using --older-than or the
+// configured retention.
+func runCleanCommand(db Database, args []string, now time.Time, out io.Writer) error {
+	flags := flag.NewFlagSet("clean", flag.ContinueOnError)
+	olderThan := flags.String("older-than", "", "Delete items last updated longer ago than this, e.g. 90d or 6m (default: CACHE_RETENTION or "+defaultCacheRetention+")")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [options] clean [--older-than 90d]\n\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return fmt.Errorf("clean does not take positional arguments (got %q)", flags.Args())
+	}
+	if db == nil {
+		return fmt.Errorf("the cache database is not available")
+	}
+
+	var retention time.Duration
+	if value := strings.TrimSpace(*olderThan); value != "" {
+		parsed, err := parseTimeRange(value)
+		if err != nil {
+			return fmt.Errorf("invalid --older-than: %w", err)
+		}
+		retention = parsed
+	} else {
+		parsed, enabled, err := resolveCacheRetention()
+		if err != nil {
+			return err
+		}
+		if !enabled {
+			return fmt.Errorf("CACHE_RETENTION is off; pass --older-than to choose a retention")
+		}
+		retention = parsed
+	}
+
+	cutoff := now.Add(-retention)
+	stats, err := db.Prune(cutoff, now)
+	if err != nil {
+		return fmt.Errorf("failed to prune cache: %w", err)
+	}
+	fmt.Fprintf(out, "Deleted %d items and %d notes last updated before %s\n", stats.Items, stats.Notes, cutoff.Format("2006-01-02"))
+	return nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// rateLimitLowShare is the share of the quota below which the progress bar
+// warns that the run is close to being throttled.
+const rateLimitLowShare = 0.2
+
+// rateLimitBudget is the API quota left, as reported by GitLab's
+// RateLimit-Remaining and RateLimit-Limit response headers. Requests run
+// concurrently, so the values are atomics; limit stays 0 until a response
+// carried the headers (instances with rate limits disabled never send them).
+type rateLimitBudget struct {
+	remaining atomic.Int64
+	limit     atomic.Int64
+	// reported is the tenth of the quota last announced with --debug, plus
+	// one so that 0 means nothing was announced yet.
+	reported atomic.Int64
+}
+
+// record updates the budget from a response's headers. With --debug it
+// reports the quota on the first response and whenever it crosses another
+// tenth of the limit.
+func (b *rateLimitBudget) record(header http.Header) {
+	remaining, ok := parseRateLimitHeader(header.Get("RateLimit-Remaining"))
+	if !ok {
+		return
+	}
+	limit, ok := parseRateLimitHeader(header.Get("RateLimit-Limit"))
+	if !ok || limit == 0 {
+		return
+	}
+	b.remaining.Store(remaining)
+	b.limit.Store(limit)
+
+	tenth := min(remaining*10/limit, 10) + 1
+	previous := b.reported.Load()
+	if tenth != previous && b.reported.CompareAndSwap(previous, tenth) && config.debugMode {
+		fmt.Printf("  [API] Rate limit: %d of %d requests left\n", remaining, limit)
+	}
+}
+
+// warning is the progress bar message once less than rateLimitLowShare of the
+// quota is left; empty otherwise.
+func (b *rateLimitBudget) warning() string {
+	limit := b.limit.Load()
+	if limit == 0 {
+		return ""
+	}
+	remaining := b.remaining.Load()
+	if float64(remaining) >= float64(limit)*rateLimitLowShare {
+		return ""
+	}
+	return fmt.Sprintf("Rate limit: %d/%d requests left", remaining, limit)
+}
+
+func parseRateLimitHeader(raw string) (int64, bool) {
+	value, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+	if err != nil || value < 0 {
+		return 0, false
+	}
+	return value, true
+}
+
+// rateLimitTransport feeds every response's rate-limit headers into
+// config.rateLimit.
+type rateLimitTransport struct {
+	next http.RoundTripper
+}
+
+func (t rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err == nil {
+		config.rateLimit.record(resp.Header)
+	}
+	return resp, err
+}
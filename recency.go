@@ -0,0 +1,61 @@
+package main
+
+import (
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// The headings are English, like the rest of the output; "local" only refers
+// to the calendar days of the display timezone (--tz).
+const (
+	recencyToday     = "Today"
+	recencyYesterday = "Yesterday"
+	recencyThisWeek  = "Earlier this week"
+	recencyOlder     = "Older"
+)
+
+// recencyBucket compares calendar days in the display timezone, so an item
+// updated at 23:30 is "Yesterday" shortly after midnight. Weeks start on Monday.
+func recencyBucket(t, now time.Time) string {
+	localNow := displayTime(now)
+	year, month, day := localNow.Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, localNow.Location())
+	weekStart := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+
+	local := displayTime(t)
+	switch {
+	case !local.Before(today):
+		return recencyToday
+	case !local.Before(today.AddDate(0, 0, -1)):
+		return recencyYesterday
+	case !local.Before(weekStart):
+		return recencyThisWeek
+	default:
+		return recencyOlder
+	}
+}
+
+// recencyHeadings emits a subheading whenever the bucket changes while walking
+// a section's items from newest to oldest.
+type recencyHeadings struct {
+	enabled bool
+	now     time.Time
+	last    string
+}
+
+func newRecencyHeadings() *recencyHeadings {
+	return &recencyHeadings{enabled: !config.noRecency, now: time.Now()}
+}
+
+func (h *recencyHeadings) next(updatedAt time.Time) (string, bool) {
+	if !h.enabled {
+		return "", false
+	}
+	bucket := recencyBucket(updatedAt, h.now)
+	if bucket == h.last {
+		return "", false
+	}
+	h.last = bucket
+	return color.New(color.Faint).Sprint(symbols.Heading + " " + bucket), true
+}
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// runGitLabReportCommand implements `report reviewers|latency`, overviews
+// across the allowed projects rather than the user's own feed.
+func runGitLabReportCommand(ctx context.Context, client *gitlab.Client, args []string, now time.Time, out io.Writer) error {
+	usage := fmt.Errorf("usage: %s --platform gitlab report reviewers|latency", filepath.Base(os.Args[0]))
+	if len(args) != 1 {
+		return usage
+	}
+	if len(config.allowedRepos) == 0 {
+		return fmt.Errorf("the report command needs --allowed-repos, repository arguments or ALLOWED_REPOS")
+	}
+
+	projects, err := resolveAllowedGitLabProjects(ctx, client, config.allowedRepos, config.db, nil)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "reviewers":
+		workloads, err := collectGitLabReviewerWorkloads(ctx, client, projects)
+		if err != nil {
+			return err
+		}
+		writeReviewerWorkloads(out, workloads, now)
+		return nil
+	case "latency":
+		cutoff := activityCutoff()
+		latency, err := collectGitLabReviewLatency(ctx, client, projects, cutoff)
+		if err != nil {
+			return err
+		}
+		writeReviewLatency(out, latency, cutoff)
+		return nil
+	default:
+		return usage
+	}
+}
+
+// reviewerWorkload is one reviewer's queue of open merge requests they have
+// not reviewed yet.
+type reviewerWorkload struct {
+	Username string
+	Pending  int
+	// Oldest is when the longest-waiting request was made, and OldestRef the
+	// merge request it was for (group/repo!iid).
+	Oldest    time.Time
+	OldestRef string
+}
+
+// pendingReviewerStates are the reviewer states GitLab uses before a review
+// has been submitted.
+var pendingReviewerStates = map[string]bool{
+	"unreviewed":     true,
+	"review_started": true,
+}
+
+// collectGitLabReviewerWorkloads counts, per reviewer, the open non-draft
+// merge requests still waiting for their review. The reviewers endpoint says
+// when each reviewer was requested; the merge request creation time stands in
+// when it does not.
+func collectGitLabReviewerWorkloads(ctx context.Context, client *gitlab.Client, projects []gitLabProject) ([]reviewerWorkload, error) {
+	byUser := make(map[string]*reviewerWorkload)
+	for _, project := range projects {
+		mergeRequests, err := listGitLabOpenMergeRequests(ctx, client, project.ID)
+		if err != nil {
+			return nil, fmt.Errorf("list open merge requests for %s: %w", project.PathWithNamespace, err)
+		}
+		for _, mr := range mergeRequests {
+			if mr == nil || mr.Draft || len(mr.Reviewers) == 0 {
+				continue
+			}
+			var reviewers []*gitlab.MergeRequestReviewer
+			err := retryWithBackoff(func() error {
+				var apiErr error
+				reviewers, _, apiErr = client.MergeRequests.GetMergeRequestReviewers(project.ID, mr.IID, gitlab.WithContext(ctx))
+				return apiErr
+			}, fmt.Sprintf("GitLabGetMergeRequestReviewers %d!%d", project.ID, mr.IID))
+			if err != nil {
+				config.apiErrorCount.Add(1)
+				if config.debugMode {
+					fmt.Printf("  [GitLab] Warning: failed to read reviewers of %s!%d: %v\n", project.PathWithNamespace, mr.IID, err)
+				}
+				continue
+			}
+
+			for _, reviewer := range reviewers {
+				if reviewer == nil || reviewer.User == nil || !pendingReviewerStates[reviewer.State] {
+					continue
+				}
+				requestedAt := time.Time{}
+				if reviewer.CreatedAt != nil {
+					requestedAt = *reviewer.CreatedAt
+				} else if mr.CreatedAt != nil {
+					requestedAt = *mr.CreatedAt
+				}
+
+				workload, ok := byUser[reviewer.User.Username]
+				if !ok {
+					workload = &reviewerWorkload{Username: reviewer.User.Username}
+					byUser[reviewer.User.Username] = workload
+				}
+				workload.Pending++
+				if workload.OldestRef == "" || requestedAt.Before(workload.Oldest) {
+					workload.Oldest = requestedAt
+					workload.OldestRef = fmt.Sprintf("%s!%d", project.PathWithNamespace, mr.IID)
+				}
+			}
+		}
+	}
+
+	workloads := make([]reviewerWorkload, 0, len(byUser))
+	for _, workload := range byUser {
+		workloads = append(workloads, *workload)
+	}
+	sort.Slice(workloads, func(i, j int) bool {
+		if workloads[i].Pending != workloads[j].Pending {
+			return workloads[i].Pending > workloads[j].Pending
+		}
+		if !workloads[i].Oldest.Equal(workloads[j].Oldest) {
+			return workloads[i].Oldest.Before(workloads[j].Oldest)
+		}
+		return workloads[i].Username < workloads[j].Username
+	})
+	return workloads, nil
+}
+
+func listGitLabOpenMergeRequests(ctx context.Context, client *gitlab.Client, projectID int64) ([]*gitlab.BasicMergeRequest, error) {
+	allItems := make([]*gitlab.BasicMergeRequest, 0)
+	options := &gitlab.ListProjectMergeRequestsOptions{
+		ListOptions: gitlab.ListOptions{PerPage: int64(pageSize()), Page: 1},
+		State:       gitlab.Ptr("opened"),
+	}
+
+	for {
+		var (
+			items    []*gitlab.BasicMergeRequest
+			response *gitlab.Response
+		)
+		err := retryWithBackoff(func() error {
+			var apiErr error
+			items, response, apiErr = client.MergeRequests.ListProjectMergeRequests(projectID, options, gitlab.WithContext(ctx))
+			return apiErr
+		}, fmt.Sprintf("GitLabListOpenMergeRequests %d page %d", projectID, options.Page))
+		if err != nil {
+			return nil, err
+		}
+		allItems = append(allItems, items...)
+
+		if response == nil || response.NextPage == 0 {
+			break
+		}
+		options.Page = response.NextPage
+	}
+
+	return allItems, nil
+}
+
+func writeReviewerWorkloads(out io.Writer, workloads []reviewerWorkload, now time.Time) {
+	if len(workloads) == 0 {
+		fmt.Fprintln(out, "No open merge requests are waiting for a review")
+		return
+	}
+
+	total := 0
+	for _, workload := range workloads {
+		total += workload.Pending
+	}
+	fmt.Fprintf(out, "Pending reviews: %d across %d reviewers\n\n", total, len(workloads))
+	fmt.Fprintf(out, "  %-24s %7s  %s\n", "REVIEWER", "PENDING", "OLDEST REQUEST")
+	for _, workload := range workloads {
+		oldest := "unknown"
+		if !workload.Oldest.IsZero() {
+			oldest = formatSLADuration(now.Sub(workload.Oldest)) + " ago"
+		}
+		fmt.Fprintf(out, "  %-24s %7d  %s (%s)\n", "@"+workload.Username, workload.Pending, oldest, workload.OldestRef)
+	}
+}
+
+// reviewLatency holds the durations measured for merge requests updated in
+// the activity window.
+type reviewLatency struct {
+	MergeRequests int
+	// FirstReview is the time from the first review request to the first
+	// approval or comment by someone other than the author.
+	FirstReview []time.Duration
+	// Merge is the time from creation to merge, for merge requests merged in
+	// the window.
+	Merge []time.Duration
+}
+
+// collectGitLabReviewLatency measures review and merge latency from each merge
+// request's notes. GitLab records review requests ("requested review from
+// @user") and approvals ("approved this merge request") as system notes, so
+// one note listing per merge request covers both.
+func collectGitLabReviewLatency(ctx context.Context, client *gitlab.Client, projects []gitLabProject, cutoff time.Time) (reviewLatency, error) {
+	var latency reviewLatency
+	for _, project := range projects {
+		mergeRequests, err := listGitLabProjectMergeRequests(ctx, client, project.ID, cutoff, time.Time{})
+		if err != nil {
+			return reviewLatency{}, fmt.Errorf("list merge requests for %s: %w", project.PathWithNamespace, err)
+		}
+		for _, mr := range mergeRequests {
+			if mr == nil {
+				continue
+			}
+			latency.MergeRequests++
+			if mr.MergedAt != nil && mr.CreatedAt != nil && !mr.MergedAt.Before(cutoff) {
+				latency.Merge = append(latency.Merge, mr.MergedAt.Sub(*mr.CreatedAt))
+			}
+
+			notes, err := listAllGitLabMergeRequestNotes(ctx, client, project.ID, mr.IID)
+			if err != nil {
+				config.apiErrorCount.Add(1)
+				if config.debugMode {
+					fmt.Printf("  [GitLab] Warning: failed to read notes of %s!%d: %v\n", project.PathWithNamespace, mr.IID, err)
+				}
+				continue
+			}
+			author := ""
+			if mr.Author != nil {
+				author = mr.Author.Username
+			}
+			if waited, ok := firstReviewLatency(notes, author); ok {
+				latency.FirstReview = append(latency.FirstReview, waited)
+			}
+		}
+	}
+	return latency, nil
+}
+
+// firstReviewLatency returns how long the first review request waited for an
+// approval or a comment from someone other than the author or a bot. Merge
+// requests without a review request, or still waiting, report false.
+func firstReviewLatency(notes []*gitlab.Note, author string) (time.Duration, bool) {
+	var requestedAt, reviewedAt time.Time
+	for _, note := range notes {
+		if note == nil || note.CreatedAt == nil {
+			continue
+		}
+		body := strings.ToLower(strings.TrimSpace(note.Body))
+		if note.System && strings.HasPrefix(body, "requested review from") {
+			if requestedAt.IsZero() || note.CreatedAt.Before(requestedAt) {
+				requestedAt = *note.CreatedAt
+			}
+		}
+	}
+	if requestedAt.IsZero() {
+		return 0, false
+	}
+
+	for _, note := range notes {
+		if note == nil || note.CreatedAt == nil || note.CreatedAt.Before(requestedAt) {
+			continue
+		}
+		// Only people count: CI and tooling post as bot users, and system
+		// notes other than an approval (pushes, label or pipeline changes) are
+		// not a review.
+		username := note.Author.Username
+		if username == "" || strings.EqualFold(username, author) || isGitLabBotUsername(username) {
+			continue
+		}
+		reviewed := !note.System || strings.HasPrefix(strings.ToLower(strings.TrimSpace(note.Body)), "approved this merge request")
+		if reviewed && (reviewedAt.IsZero() || note.CreatedAt.Before(reviewedAt)) {
+			reviewedAt = *note.CreatedAt
+		}
+	}
+	if reviewedAt.IsZero() {
+		return 0, false
+	}
+	return reviewedAt.Sub(requestedAt), true
+}
+
+// isGitLabBotUsername reports whether username belongs to a bot. Note authors
+// carry no bot flag, so this goes by GitLab's naming: project and group access
+// tokens act as project_<id>_bot_<hash> / group_<id>_bot_<hash> users, and
+// GitLab's internal users and most integrations are named like alert-bot,
+// support-bot or renovate-bot.
+func isGitLabBotUsername(username string) bool {
+	name := strings.ToLower(username)
+	return strings.Contains(name, "_bot_") || strings.HasSuffix(name, "-bot") || strings.HasSuffix(name, "_bot") || strings.HasSuffix(name, "[bot]")
+}
+
+// durationPercentile returns the nearest-rank percentile of sorted durations.
+func durationPercentile(sorted []time.Duration, percentile float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(percentile/100*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+func writeReviewLatency(out io.Writer, latency reviewLatency, cutoff time.Time) {
+	fmt.Fprintf(out, "Review latency for %d merge requests updated since %s\n\n", latency.MergeRequests, displayTime(cutoff).Format("2006-01-02"))
+	fmt.Fprintf(out, "  %-24s %6s %7s %7s %7s\n", "METRIC", "COUNT", "MEDIAN", "P90", "MAX")
+	for _, metric := range []struct {
+		name      string
+		durations []time.Duration
+	}{
+		{"Request to first review", latency.FirstReview},
+		{"Time to merge", latency.Merge},
+	} {
+		if len(metric.durations) == 0 {
+			fmt.Fprintf(out, "  %-24s %6d %7s %7s %7s\n", metric.name, 0, "-", "-", "-")
+			continue
+		}
+		sorted := append([]time.Duration(nil), metric.durations...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		fmt.Fprintf(out, "  %-24s %6d %7s %7s %7s\n", metric.name, len(sorted),
+			formatSLADuration(durationPercentile(sorted, 50)),
+			formatSLADuration(durationPercentile(sorted, 90)),
+			formatSLADuration(sorted[len(sorted)-1]))
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// runLogger appends JSON lines to --log-file; nil without the flag. Scheduled
+// runs (sync, --output) have nobody watching --debug, so the log keeps the API
+// calls, retries and cache warnings instead.
+var runLogger *slog.Logger
+
+// openRunLog opens path for appending (creating it and its directory) and
+// points runLogger at it. A leading ~/ is expanded, since the flag value is
+// often quoted in crontabs.
+func openRunLog(path string) (io.Closer, error) {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("could not expand %s: %w", path, err)
+		}
+		path = filepath.Join(home, rest)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+	runLogger = slog.New(slog.NewJSONHandler(file, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	return file, nil
+}
+
+func logRun(level slog.Level, msg string, args ...any) {
+	if runLogger == nil {
+		return
+	}
+	runLogger.Log(context.Background(), level, msg, args...)
+}
+
+// recordDBWarning counts a failed cache read or write, which never fails the
+// run, and reports it with --debug and in the run log.
+func recordDBWarning(format string, args ...any) {
+	config.dbErrorCount.Add(1)
+	message := fmt.Sprintf(format, args...)
+	if config.debugMode {
+		fmt.Printf("  [DB] Warning: %s\n", message)
+	}
+	logRun(slog.LevelWarn, "cache warning", "message", message)
+}
+
+// loggingTransport logs every API request with its status and duration.
+type loggingTransport struct {
+	next http.RoundTripper
+}
+
+func (t loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	args := []any{
+		"method", req.Method,
+		"host", req.URL.Host,
+		"path", req.URL.Path,
+		"duration_ms", time.Since(start).Milliseconds(),
+	}
+	if config.debugHTTP {
+		args = append(args, "url", redactURL(req.URL))
+		if resp != nil && resp.Header.Get("X-Request-Id") != "" {
+			args = append(args, "request_id", resp.Header.Get("X-Request-Id"))
+		}
+	}
+	if err != nil {
+		logRun(slog.LevelWarn, "api request failed", append(args, "error", err.Error())...)
+		return resp, err
+	}
+	level := slog.LevelInfo
+	if resp.StatusCode >= http.StatusBadRequest {
+		level = slog.LevelWarn
+	}
+	if cached := resp.Header.Get(httpCacheHeader); cached != "" {
+		args = append(args, "cache", cached)
+	}
+	logRun(level, "api request", append(args, "status", resp.StatusCode)...)
+	return resp, nil
+}
+
+// httpDebugTransport implements --debug-http without --log-file: it writes a
+// line per request to out. Headers are never printed, since they carry the
+// token; the URL is redacted.
+type httpDebugTransport struct {
+	next http.RoundTripper
+	out  io.Writer
+}
+
+func (t httpDebugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	duration := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		fmt.Fprintf(t.out, "  [HTTP] %s %s failed after %s: %v\n", req.Method, redactURL(req.URL), duration, err)
+		return resp, err
+	}
+	line := fmt.Sprintf("  [HTTP] %s %s %d %s", req.Method, redactURL(req.URL), resp.StatusCode, duration)
+	if requestID := resp.Header.Get("X-Request-Id"); requestID != "" {
+		line += " request_id=" + requestID
+	}
+	if cached := resp.Header.Get(httpCacheHeader); cached != "" {
+		line += " cache=" + cached
+	}
+	fmt.Fprintln(t.out, line)
+	return resp, nil
+}
+
+// sensitiveQueryKeys are the query parameters redactURL blanks out. The API
+// clients send tokens in headers, but OAuth flows and hand-written base URLs
+// may carry them in the query.
+var sensitiveQueryKeys = map[string]bool{
+	"private_token": true,
+	"access_token":  true,
+	"job_token":     true,
+	"token":         true,
+	"client_secret": true,
+	"password":      true,
+	"refresh_token": true,
+}
+
+// redactURL returns u for logging, with credentials in the user info and in
+// sensitive query parameters replaced by REDACTED.
+func redactURL(u *url.URL) string {
+	redacted := *u
+	if redacted.User != nil {
+		redacted.User = url.User("REDACTED")
+	}
+	if redacted.RawQuery != "" {
+		query := redacted.Query()
+		for key, values := range query {
+			if sensitiveQueryKeys[strings.ToLower(key)] {
+				for i := range values {
+					values[i] = "REDACTED"
+				}
+			}
+		}
+		redacted.RawQuery = query.Encode()
+	}
+	return redacted.String()
+}
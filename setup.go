@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// setupAttempts is how often the setup wizard asks again for a value the
+// GitLab instance rejected before giving up.
+const setupAttempts = 3
+
+// gitLabSetup is what the first-run setup wizard collected and verified.
+type gitLabSetup struct {
+	Host          string
+	BaseURL       string
+	Token         string
+	Username      string
+	AllowedRepos  []string
+	TokenInConfig bool
+}
+
+// offerGitLabSetup asks whether to run the setup wizard when no GitLab token
+// is configured. It is only offered on a terminal, so cron runs still fail
+// with the usual configuration error.
+func offerGitLabSetup(in *os.File, interactive bool) bool {
+	if !interactive {
+		return false
+	}
+	return confirmPrompt(in, "No GitLab token is configured. Set up git-feed now?")
+}
+
+// runGitLabSetupWizard asks for the GitLab host, a token (read without echo)
+// and the projects to follow, checks each against the instance, and saves
+// them to config.yaml at configPath (under profiles.NAME with a profile). The
+// token goes to the system keyring, or into config.yaml when no keyring is
+// available.
+func runGitLabSetupWizard(ctx context.Context, in *os.File, w io.Writer, configPath, profile, defaultHost, defaultRepos string) (gitLabSetup, error) {
+	lines := bufio.NewReader(in)
+	fmt.Fprintln(w, "Setting up git-feed for GitLab. Press Ctrl+C to cancel.")
+
+	var setup gitLabSetup
+	var client *gitlab.Client
+	for attempt := 1; ; attempt++ {
+		host, err := promptLine(lines, w, "GitLab host", defaultHost)
+		if err != nil {
+			return gitLabSetup{}, err
+		}
+		if !strings.Contains(host, "://") {
+			host = "https://" + host
+		}
+		token, err := readToken(in, lines, w, "Personal access token (read_api scope, input hidden): ")
+		if err != nil {
+			return gitLabSetup{}, err
+		}
+
+		client, setup.BaseURL, err = newGitLabClient(token, host)
+		if err == nil {
+			var user *gitlab.User
+			user, err = verifyGitLabToken(ctx, client)
+			if err == nil {
+				setup.Host, setup.Token, setup.Username = host, token, user.Username
+				fmt.Fprintf(w, "Signed in to %s as %s\n", host, user.Username)
+				break
+			}
+		}
+		fmt.Fprintf(w, "%v\n", err)
+		if attempt == setupAttempts {
+			return gitLabSetup{}, errors.New("setup cancelled: the host and token could not be verified")
+		}
+		defaultHost = host
+	}
+
+	for attempt := 1; ; attempt++ {
+		answer, err := promptLine(lines, w, "Projects to follow (comma-separated group[/subgroup]/repo)", defaultRepos)
+		if err != nil {
+			return gitLabSetup{}, err
+		}
+		repos := make([]string, 0)
+		for repo := range parseRepoList(answer) {
+			repos = append(repos, normalizeProjectPathWithNamespace(repo))
+		}
+		unreadable := unreadableGitLabProjects(ctx, client, repos)
+		if len(repos) > 0 && len(unreadable) == 0 {
+			sort.Strings(repos)
+			setup.AllowedRepos = repos
+			break
+		}
+		if len(repos) == 0 {
+			fmt.Fprintln(w, "Enter at least one project.")
+		}
+		for _, failure := range unreadable {
+			fmt.Fprintf(w, "Cannot read %s: %v\n", failure.Project, failure.Err)
+		}
+		if attempt == setupAttempts {
+			return gitLabSetup{}, errors.New("setup cancelled: no readable projects were given")
+		}
+		defaultRepos = answer
+	}
+
+	if err := saveGitLabSetup(configPath, profile, &setup); err != nil {
+		return gitLabSetup{}, err
+	}
+	if setup.TokenInConfig {
+		fmt.Fprintf(w, "No system keyring is available, so the token was saved in %s (readable only by you)\n", configPath)
+	} else {
+		fmt.Fprintln(w, "Stored the token in the system keyring")
+	}
+	fmt.Fprintf(w, "Saved the GitLab settings to %s\n\n", configPath)
+	return setup, nil
+}
+
+// verifyGitLabToken checks the token with a live call that every token scope
+// that can read the feed allows.
+func verifyGitLabToken(ctx context.Context, client *gitlab.Client) (*gitlab.User, error) {
+	user, resp, err := client.Users.CurrentUser(gitlab.WithContext(ctx))
+	if resp != nil && resp.StatusCode == http.StatusUnauthorized {
+		return nil, fmt.Errorf("GitLab rejected the token (401 Unauthorized); create one with the read_api scope at %s", personalAccessTokensURL(client))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not reach GitLab with this host and token: %w", err)
+	}
+	return user, nil
+}
+
+// unreadableGitLabProjects looks up each project and returns those the token
+// cannot read.
+func unreadableGitLabProjects(ctx context.Context, client *gitlab.Client, repos []string) []projectFailure {
+	var failures []projectFailure
+	for _, repo := range repos {
+		if _, _, err := client.Projects.GetProject(repo, nil, gitlab.WithContext(ctx)); err != nil {
+			if errors.Is(err, gitlab.ErrNotFound) {
+				err = errors.New("not found, or the token has no access")
+			}
+			failures = append(failures, projectFailure{Project: repo, Err: err})
+		}
+	}
+	return failures
+}
+
+// saveGitLabSetup writes the wizard's settings to config.yaml, keeping the
+// rest of the file. The token is stored in the keyring entry `auth login`
+// uses when possible; otherwise it goes into config.yaml, which
+// writeConfigDocument replaces with a 0600 file even when the existing one was
+// readable by others.
+func saveGitLabSetup(configPath, profile string, setup *gitLabSetup) error {
+	doc, err := readConfigDocument(configPath)
+	if err != nil {
+		return err
+	}
+	prefix := ""
+	if profile != "" {
+		prefix = "profiles." + profile + "."
+	}
+	values := [][2]string{
+		{"gitlab.host", setup.Host},
+		{"gitlab.username", setup.Username},
+		{"gitlab.allowed_repos", strings.Join(setup.AllowedRepos, ",")},
+	}
+	if err := keyring.Set(keyringService, keyringAccount("gitlab", setup.BaseURL), setup.Token); err != nil {
+		values = append(values, [2]string{"gitlab.token", setup.Token})
+		setup.TokenInConfig = true
+	}
+	for _, entry := range values {
+		segments, leaf, err := resolveConfigKey(prefix + entry[0])
+		if err != nil {
+			return err
+		}
+		value, err := configValueNode(leaf, entry[1])
+		if err != nil {
+			return fmt.Errorf("%s: %w", entry[0], err)
+		}
+		setConfigNode(doc.Content[0], segments, value)
+	}
+	return writeConfigDocument(configPath, doc)
+}
+
+// promptLine asks for one line of input, offering fallback for an empty
+// answer.
+func promptLine(lines *bufio.Reader, w io.Writer, prompt, fallback string) (string, error) {
+	if fallback != "" {
+		fmt.Fprintf(w, "%s [%s]: ", prompt, fallback)
+	} else {
+		fmt.Fprintf(w, "%s: ", prompt)
+	}
+	answer, err := lines.ReadString('\n')
+	if err != nil && (!errors.Is(err, io.EOF) || answer == "") {
+		return "", fmt.Errorf("setup cancelled: %w", err)
+	}
+	if answer = strings.TrimSpace(answer); answer == "" {
+		return fallback, nil
+	}
+	return answer, nil
+}
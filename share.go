@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+func runGitLabShareCommand(ctx context.Context, client *gitlab.Client, args []string) error {
+	flags := flag.NewFlagSet("share", flag.ContinueOnError)
+	visibility := flags.String("visibility", "private", "Snippet visibility (private|internal|public)")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s --platform gitlab share [--visibility private|internal|public]\n\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return fmt.Errorf("share does not take positional arguments (got %q)", flags.Args())
+	}
+
+	snippetVisibility := gitlab.VisibilityValue(strings.ToLower(strings.TrimSpace(*visibility)))
+	switch snippetVisibility {
+	case gitlab.PrivateVisibility, gitlab.InternalVisibility, gitlab.PublicVisibility:
+	default:
+		return fmt.Errorf("invalid --visibility value %q (allowed: private|internal|public)", *visibility)
+	}
+
+	activities, issueActivities, err := fetchActivities("gitlab")
+	if err != nil {
+		return fmt.Errorf("failed to fetch GitLab activity: %w", err)
+	}
+
+	now := time.Now()
+	content := renderActivitiesMarkdown("gitlab", activities, issueActivities, now)
+
+	// Not retried: a POST that timed out may still have created the snippet.
+	snippet, _, err := client.Snippets.CreateSnippet(&gitlab.CreateSnippetOptions{
+		Title:       gitlab.Ptr(fmt.Sprintf("git-feed: %s (%s)", config.gitlabUsername, displayTime(now).Format("2006-01-02 15:04"))),
+		Description: gitlab.Ptr("Merge requests and issues from " + describeActivityWindow()),
+		FileName:    gitlab.Ptr("git-feed.md"),
+		Content:     gitlab.Ptr(content),
+		Visibility:  gitlab.Ptr(snippetVisibility),
+	}, gitlab.WithContext(ctx), gitLabNoRetry())
+	if err != nil {
+		return fmt.Errorf("failed to create snippet: %w", err)
+	}
+
+	fmt.Println(snippet.WebURL)
+	return nil
+}
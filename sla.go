@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+type slaStatus struct {
+	Target  time.Duration
+	Elapsed time.Duration
+}
+
+func (s slaStatus) Overdue() bool {
+	return s.Elapsed > s.Target
+}
+
+func normalizeLabelKey(label string) string {
+	label = strings.ToLower(strings.TrimSpace(label))
+	label = strings.NewReplacer("-", " ", "_", " ").Replace(label)
+	return strings.Join(strings.Fields(label), " ")
+}
+
+// parseSLATargets parses "Review Requested=24h,assigned=3d" into per-label
+// response targets. Durations use the same units as --time.
+func parseSLATargets(value string) (map[string]time.Duration, error) {
+	targets := make(map[string]time.Duration)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		label, rawDuration, ok := strings.Cut(entry, "=")
+		label = normalizeLabelKey(label)
+		if !ok || label == "" {
+			return nil, fmt.Errorf("invalid SLA %q (expected label=duration, e.g. review-requested=24h)", entry)
+		}
+		duration, err := parseTimeRange(strings.TrimSpace(rawDuration))
+		if err != nil {
+			return nil, fmt.Errorf("invalid SLA duration for %q: %w", label, err)
+		}
+		targets[label] = duration
+	}
+	return targets, nil
+}
+
+// slaTarget returns the response target for a label, preferring the repos:
+// entry of config.yaml for the item's project over the global targets.
+func slaTarget(project, label string) (time.Duration, bool) {
+	key := normalizeLabelKey(label)
+	if options, ok := repoOptionsFor(project); ok {
+		if target, ok := options.slaTargets[key]; ok {
+			return target, true
+		}
+	}
+	target, ok := config.slaTargets[key]
+	return target, ok
+}
+
+// evaluateSLA reports how long an open item has been waiting against the
+// target for its label. The clock starts when the user was asked to review or
+// assigned (involvedAt). When that is unknown it starts at creation, and for
+// cache entries written before CreatedAt existed at the last update.
+func evaluateSLA(project, label, state string, involvedAt, createdAt, updatedAt, now time.Time) (slaStatus, bool) {
+	if state == "closed" {
+		return slaStatus{}, false
+	}
+	target, ok := slaTarget(project, label)
+	if !ok {
+		return slaStatus{}, false
+	}
+
+	start := involvedAt
+	if start.IsZero() {
+		start = createdAt
+	}
+	if start.IsZero() {
+		start = updatedAt
+	}
+	if start.IsZero() {
+		return slaStatus{}, false
+	}
+
+	return slaStatus{Target: target, Elapsed: now.Sub(start)}, true
+}
+
+func formatSLADuration(d time.Duration) string {
+	if d < 0 {
+		d = -d
+	}
+	switch {
+	case d >= 48*time.Hour:
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	default:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	}
+}
+
+func slaBadge(status slaStatus) string {
+	if status.Overdue() {
+		return color.New(color.FgRed, color.Bold).Sprintf("[overdue %s]", formatSLADuration(status.Elapsed-status.Target))
+	}
+
+	remaining := status.Target - status.Elapsed
+	badgeColor := color.New(color.Faint)
+	if remaining*4 < status.Target {
+		badgeColor = color.New(color.FgYellow)
+	}
+	return badgeColor.Sprintf("[due in %s]", formatSLADuration(remaining))
+}
+
+type slaCompliance struct {
+	Tracked        int
+	Overdue        int
+	OverdueByLabel []countEntry
+}
+
+func summarizeSLA(activities []PRActivity, issueActivities []IssueActivity, now time.Time) slaCompliance {
+	var compliance slaCompliance
+	overdueByLabel := make(map[string]int)
+
+	track := func(project, label, state string, involvedAt, createdAt, updatedAt time.Time) {
+		status, ok := evaluateSLA(project, label, state, involvedAt, createdAt, updatedAt, now)
+		if !ok {
+			return
+		}
+		compliance.Tracked++
+		if status.Overdue() {
+			compliance.Overdue++
+			overdueByLabel[label]++
+		}
+	}
+
+	for _, activity := range activities {
+		track(projectDisplayPath(activity.Owner, activity.Repo), activity.Label, activity.MR.State, activity.MR.InvolvedAt, activity.MR.CreatedAt, activity.MR.UpdatedAt)
+		for _, issue := range activity.Issues {
+			track(projectDisplayPath(issue.Owner, issue.Repo), issue.Label, issue.Issue.State, issue.Issue.InvolvedAt, issue.Issue.CreatedAt, issue.Issue.UpdatedAt)
+		}
+	}
+	for _, issue := range issueActivities {
+		track(projectDisplayPath(issue.Owner, issue.Repo), issue.Label, issue.Issue.State, issue.Issue.InvolvedAt, issue.Issue.CreatedAt, issue.Issue.UpdatedAt)
+	}
+
+	for label, count := range overdueByLabel {
+		compliance.OverdueByLabel = append(compliance.OverdueByLabel, countEntry{Name: label, Count: count})
+	}
+	sort.Slice(compliance.OverdueByLabel, func(i, j int) bool {
+		return labelGroupRank(compliance.OverdueByLabel[i].Name) < labelGroupRank(compliance.OverdueByLabel[j].Name)
+	})
+
+	return compliance
+}
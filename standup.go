@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// standupWindow is the previous working day in location: Monday's standup
+// covers Friday.
+func standupWindow(now time.Time, location *time.Location) (time.Time, time.Time) {
+	if location == nil {
+		location = time.Local
+	}
+	now = now.In(location)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+	return subtractBusinessDays(today, 1), today
+}
+
+// standupItem is one bullet of the standup report.
+type standupItem struct {
+	Author    string
+	Line      string
+	UpdatedAt time.Time
+}
+
+// renderStandup lists the feed as plain bullets grouped by author, ready to
+// paste into a chat thread. Nested issues get their own bullets under their
+// author, and each bullet says what happened to the item in the window.
+// Items updated again since until are left out, since their last update is
+// not part of the window.
+func renderStandup(platform string, activities []PRActivity, issueActivities []IssueActivity, since, until time.Time) string {
+	activities, issueActivities = filterActivitiesByState(activities, issueActivities, config.states)
+
+	mrSeparator := "#"
+	if platform == "gitlab" {
+		mrSeparator = "!"
+	}
+
+	var items []standupItem
+	inWindow := func(updatedAt time.Time) bool {
+		return until.IsZero() || updatedAt.Before(until)
+	}
+	addIssue := func(issue IssueActivity) {
+		if !inWindow(issue.UpdatedAt) {
+			return
+		}
+		state := issue.Issue.State
+		if state != "closed" {
+			state = "open"
+		}
+		ref := fmt.Sprintf("%s#%d", projectDisplayPath(issue.Owner, issue.Repo), issue.Issue.Number)
+		items = append(items, standupItem{
+			Author:    issue.Issue.UserLogin,
+			Line:      standupLine(state, issue.Issue.CreatedAt, time.Time{}, since, ref, issue.Issue.Title, issue.Issue.WebURL),
+			UpdatedAt: issue.UpdatedAt,
+		})
+	}
+	for _, activity := range activities {
+		if inWindow(activity.UpdatedAt) {
+			ref := fmt.Sprintf("%s%s%d", projectDisplayPath(activity.Owner, activity.Repo), mrSeparator, activity.MR.Number)
+			items = append(items, standupItem{
+				Author:    activity.MR.UserLogin,
+				Line:      standupLine(activityState(activity.MR), activity.MR.CreatedAt, activity.MR.MergedAt, since, ref, activity.MR.Title, activity.MR.WebURL),
+				UpdatedAt: activity.UpdatedAt,
+			})
+		}
+		for _, issue := range activity.Issues {
+			addIssue(issue)
+		}
+	}
+	for _, issue := range issueActivities {
+		addIssue(issue)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Yesterday (%s)\n", displayTime(since).Format("Mon 2006-01-02"))
+	if len(items) == 0 {
+		b.WriteString("\nNothing happened.\n")
+		return b.String()
+	}
+
+	byAuthor := make(map[string][]standupItem)
+	for _, item := range items {
+		byAuthor[item.Author] = append(byAuthor[item.Author], item)
+	}
+	authors := make([]string, 0, len(byAuthor))
+	for author := range byAuthor {
+		authors = append(authors, author)
+	}
+	sort.Slice(authors, func(i, j int) bool {
+		return strings.ToLower(authors[i]) < strings.ToLower(authors[j])
+	})
+
+	for _, author := range authors {
+		name := "@" + author
+		if author == "" {
+			name = "Unknown author"
+		}
+		fmt.Fprintf(&b, "\n%s\n", name)
+		authorItems := byAuthor[author]
+		sort.SliceStable(authorItems, func(i, j int) bool {
+			return authorItems[i].UpdatedAt.After(authorItems[j].UpdatedAt)
+		})
+		for _, item := range authorItems {
+			b.WriteString(item.Line)
+		}
+	}
+	return b.String()
+}
+
+// standupLine describes an item as "Merged" (in the window, or at an unknown
+// time), "Closed", "Opened" (created in the window) or "Updated", followed by
+// its reference and title.
+func standupLine(state string, createdAt, mergedAt, since time.Time, ref, title, url string) string {
+	verb := "Updated"
+	switch {
+	case state == "merged":
+		// An item merged before the window was only updated in it.
+		if mergedAt.IsZero() || !mergedAt.Before(since) {
+			verb = "Merged"
+		}
+	case state == "closed":
+		verb = "Closed"
+	case !createdAt.Before(since):
+		verb = "Opened"
+	}
+	line := fmt.Sprintf("- %s %s: %s", verb, ref, title)
+	if config.showLinks && url != "" {
+		line += " " + url
+	}
+	return line + "\n"
+}
+
+// fetchAndDisplayStandup prints the standup report for the window set up by
+// --standup and reports whether the feed has actionable items.
+func fetchAndDisplayStandup(platform string, out io.Writer) (bool, error) {
+	activities, issueActivities, err := fetchActivities(platform)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch activity: %w", err)
+	}
+
+	fmt.Fprint(out, renderStandup(platform, activities, issueActivities, activityCutoff(), config.until))
+	return hasActionableItems(activities, issueActivities), nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// streamProjectItems prints the items of a project as soon as its fetch is
+// done (--stream), newest first, so long fetches show results before the
+// sorted feed. They go through the same filters as the feed, but are not
+// nested yet. Streamed items are not numbered; `open N` follows the feed.
+func streamProjectItems(activities []PRActivity, issueActivities []IssueActivity) {
+	if !config.stream {
+		return
+	}
+	activities, issueActivities = filterActivitiesByState(activities, issueActivities, config.states)
+	activities, issueActivities = filterActivitiesByRepoCutoff(activities, issueActivities, activityCutoff())
+	activities, issueActivities = filterActivitiesByWindow(activities, issueActivities, config.until)
+	activities, issueActivities = filterActivitiesByDueSoon(activities, issueActivities, config.dueSoon, time.Now())
+	activities, issueActivities = filterActivitiesByMinWeight(activities, issueActivities, config.minWeight)
+	if len(activities) == 0 && len(issueActivities) == 0 {
+		return
+	}
+	lines := streamLines(activities, issueActivities)
+
+	// The lines replace the progress bar, which is redrawn below them.
+	if config.interactive {
+		clearProgressLine()
+	}
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+	config.streamedItems += len(activities) + len(issueActivities)
+	config.progress.display()
+}
+
+// streamLines formats a project's merge requests and issues, each newest
+// first, without touching the caller's slices.
+func streamLines(activities []PRActivity, issueActivities []IssueActivity) []string {
+	activities = append([]PRActivity(nil), activities...)
+	issueActivities = append([]IssueActivity(nil), issueActivities...)
+	sort.SliceStable(activities, func(i, j int) bool {
+		return activities[i].UpdatedAt.After(activities[j].UpdatedAt)
+	})
+	sort.SliceStable(issueActivities, func(i, j int) bool {
+		return issueActivities[i].UpdatedAt.After(issueActivities[j].UpdatedAt)
+	})
+
+	var lines []string
+	for _, activity := range activities {
+		cfg := mergeRequestDisplayConfig(activity.Label, activity.Owner, activity.Repo, activity.MR, activity.HasUpdates)
+		cfg.MaxWidth = config.lineWidth
+		lines = append(lines, formatItem(cfg)...)
+	}
+	for _, issue := range issueActivities {
+		cfg := issueDisplayConfig(issue.Label, issue.Owner, issue.Repo, issue.Issue, false, issue.HasUpdates)
+		cfg.MaxWidth = config.lineWidth
+		lines = append(lines, formatItem(cfg)...)
+	}
+	return lines
+}
+
+// displayStreamDivider separates the streamed items from the sorted feed
+// that follows them.
+func displayStreamDivider(out io.Writer) {
+	if config.streamedItems == 0 {
+		return
+	}
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, color.New(color.Faint).Sprint(symbols.Heading+" Sorted feed"))
+	fmt.Fprintln(out)
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+type countEntry struct {
+	Name  string
+	Count int
+}
+
+type activitySummary struct {
+	OpenPRs      int
+	MergedPRs    int
+	ClosedPRs    int
+	OpenIssues   int
+	ClosedIssues int
+	WithUpdates  int
+	Labels       []countEntry
+	Repos        []countEntry
+	SLA          slaCompliance
+}
+
+const summaryMaxRepos = 5
+
+// summarizeActivities counts every displayed item, including issues nested
+// under their merge requests.
+func summarizeActivities(activities []PRActivity, issueActivities []IssueActivity) activitySummary {
+	var summary activitySummary
+	labelCounts := make(map[string]int)
+	repoCounts := make(map[string]int)
+
+	countIssue := func(issue IssueActivity) {
+		if issue.Issue.State == "closed" {
+			summary.ClosedIssues++
+		} else {
+			summary.OpenIssues++
+		}
+		if issue.HasUpdates {
+			summary.WithUpdates++
+		}
+		labelCounts[issue.Label]++
+		repoCounts[projectDisplayPath(issue.Owner, issue.Repo)]++
+	}
+
+	for _, activity := range activities {
+		switch activityState(activity.MR) {
+		case "merged":
+			summary.MergedPRs++
+		case "closed":
+			summary.ClosedPRs++
+		default:
+			summary.OpenPRs++
+		}
+		if activity.HasUpdates {
+			summary.WithUpdates++
+		}
+		labelCounts[activity.Label]++
+		repoCounts[projectDisplayPath(activity.Owner, activity.Repo)]++
+		for _, issue := range activity.Issues {
+			countIssue(issue)
+		}
+	}
+	for _, issue := range issueActivities {
+		countIssue(issue)
+	}
+
+	for label, count := range labelCounts {
+		summary.Labels = append(summary.Labels, countEntry{Name: label, Count: count})
+	}
+	sort.Slice(summary.Labels, func(i, j int) bool {
+		rankI, rankJ := labelGroupRank(summary.Labels[i].Name), labelGroupRank(summary.Labels[j].Name)
+		if rankI != rankJ {
+			return rankI < rankJ
+		}
+		return summary.Labels[i].Name < summary.Labels[j].Name
+	})
+
+	for repo, count := range repoCounts {
+		summary.Repos = append(summary.Repos, countEntry{Name: repo, Count: count})
+	}
+	sort.Slice(summary.Repos, func(i, j int) bool {
+		if summary.Repos[i].Count != summary.Repos[j].Count {
+			return summary.Repos[i].Count > summary.Repos[j].Count
+		}
+		return strings.ToLower(summary.Repos[i].Name) < strings.ToLower(summary.Repos[j].Name)
+	})
+
+	summary.SLA = summarizeSLA(activities, issueActivities, time.Now())
+
+	return summary
+}
+
+func displaySummary(out io.Writer, summary activitySummary) {
+	titleColor := color.New(color.Bold)
+	faint := color.New(color.Faint)
+
+	prTotal := summary.OpenPRs + summary.MergedPRs + summary.ClosedPRs
+	issueTotal := summary.OpenIssues + summary.ClosedIssues
+	line := fmt.Sprintf("%d pull requests (%d open, %d merged, %d closed) %s %d issues (%d open, %d closed)",
+		prTotal, summary.OpenPRs, summary.MergedPRs, summary.ClosedPRs, symbols.Separator,
+		issueTotal, summary.OpenIssues, summary.ClosedIssues)
+	if summary.WithUpdates > 0 {
+		line += fmt.Sprintf(" %s %d with updates", symbols.Separator, summary.WithUpdates)
+	}
+	fmt.Fprintln(out, titleColor.Sprint("SUMMARY: ")+line)
+
+	var labels []string
+	for _, entry := range summary.Labels {
+		labels = append(labels, fmt.Sprintf("%s %d", getLabelColor(entry.Name).Sprint(entry.Name), entry.Count))
+	}
+	fmt.Fprintln(out, faint.Sprint("  Labels: ")+strings.Join(labels, " "+symbols.Separator+" "))
+
+	var repos []string
+	for i, entry := range summary.Repos {
+		if i == summaryMaxRepos {
+			repos = append(repos, fmt.Sprintf("+%d more", len(summary.Repos)-summaryMaxRepos))
+			break
+		}
+		repos = append(repos, fmt.Sprintf("%s %d", entry.Name, entry.Count))
+	}
+	fmt.Fprintln(out, faint.Sprint("  Repos:  ")+strings.Join(repos, " "+symbols.Separator+" "))
+
+	if summary.SLA.Tracked > 0 {
+		sla := fmt.Sprintf("%d/%d within target", summary.SLA.Tracked-summary.SLA.Overdue, summary.SLA.Tracked)
+		if summary.SLA.Overdue > 0 {
+			var overdue []string
+			for _, entry := range summary.SLA.OverdueByLabel {
+				overdue = append(overdue, fmt.Sprintf("%s %d", entry.Name, entry.Count))
+			}
+			sla += " " + symbols.Separator + " " + color.New(color.FgRed).Sprintf("%d overdue", summary.SLA.Overdue) + " (" + strings.Join(overdue, ", ") + ")"
+		}
+		fmt.Fprintln(out, faint.Sprint("  SLA:    ")+sla)
+	}
+	fmt.Fprintln(out)
+}
+
+type countPhrase struct {
+	Label  string
+	Kind   string
+	Phrase string
+	Count  int
+}
+
+// countOnlyPhrase names a label/kind pair for --count-only output. Review,
+// approval and mention labels are counted across kinds since they always
+// mean "someone is waiting on you".
+func countOnlyPhrase(label, kind string) (string, string) {
+	switch label {
+	case "Review Requested":
+		return "", "review request"
+	case "Approval Requested":
+		return "", "approval request"
+	case "Mentioned":
+		return "", "mention"
+	}
+	return kind, strings.ToLower(label) + " " + kind
+}
+
+func formatCountOnly(activities []PRActivity, issueActivities []IssueActivity, mrKind string) string {
+	counts := make(map[string]*countPhrase)
+	var order []*countPhrase
+	add := func(label, kind string) {
+		kind, phrase := countOnlyPhrase(label, kind)
+		key := label + "\x00" + kind
+		entry, ok := counts[key]
+		if !ok {
+			entry = &countPhrase{Label: label, Kind: kind, Phrase: phrase}
+			counts[key] = entry
+			order = append(order, entry)
+		}
+		entry.Count++
+	}
+
+	for _, activity := range activities {
+		add(activity.Label, mrKind)
+		for _, issue := range activity.Issues {
+			add(issue.Label, "issue")
+		}
+	}
+	for _, issue := range issueActivities {
+		add(issue.Label, "issue")
+	}
+
+	if len(order) == 0 {
+		return "0 items"
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		rankI, rankJ := labelGroupRank(order[i].Label), labelGroupRank(order[j].Label)
+		if rankI != rankJ {
+			return rankI < rankJ
+		}
+		if order[i].Label != order[j].Label {
+			return order[i].Label < order[j].Label
+		}
+		return order[i].Kind != "issue" && order[j].Kind == "issue"
+	})
+
+	parts := make([]string, 0, len(order))
+	for _, entry := range order {
+		phrase := entry.Phrase
+		if entry.Count != 1 {
+			phrase += "s"
+		}
+		parts = append(parts, fmt.Sprintf("%d %s", entry.Count, phrase))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// displayCountOnly prints the --count-only line. Without an explicit --state
+// only open items are counted, since closed work is not waiting on anyone.
+func displayCountOnly(out io.Writer, activities []PRActivity, issueActivities []IssueActivity) {
+	states := config.states
+	if len(states) == 0 {
+		states = map[string]bool{"open": true}
+	}
+	activities, issueActivities = filterActivitiesByState(activities, issueActivities, states)
+
+	mrKind := "PR"
+	if config.platform == "gitlab" {
+		mrKind = "MR"
+	}
+	fmt.Fprintln(out, formatCountOnly(activities, issueActivities, mrKind))
+}
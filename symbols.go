@@ -0,0 +1,48 @@
+package main
+
+// symbolSet holds the non-ASCII characters used in terminal output so --ascii
+// can swap them for plain fallbacks in one place.
+type symbolSet struct {
+	Update    string
+	Link      string
+	Ellipsis  string
+	Separator string
+	Rule      string
+	Heading   string
+	Dash      string
+	Blocked   string
+	Passed    string
+	Pending   string
+	Arrow     string
+}
+
+var (
+	unicodeSymbols = symbolSet{
+		Update:    "●",
+		Link:      "🔗",
+		Ellipsis:  "…",
+		Separator: "·",
+		Rule:      "│",
+		Heading:   "──",
+		Dash:      "—",
+		Blocked:   "✗",
+		Passed:    "✓",
+		Pending:   "●",
+		Arrow:     "→",
+	}
+	asciiSymbols = symbolSet{
+		Update:    "*",
+		Link:      "->",
+		Ellipsis:  "...",
+		Separator: "|",
+		Rule:      "|",
+		Heading:   "==",
+		Dash:      "-",
+		Blocked:   "x",
+		Passed:    "+",
+		Pending:   "o",
+		Arrow:     "->",
+	}
+
+	symbols = unicodeSymbols
+)
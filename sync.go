@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// runSyncCommand fetches the feed online only to update the cache, so a cron
+// job can keep interactive --local runs current. It prints nothing on success
+// unless --debug is set; failures end up on stderr via the error budget or the
+// returned error.
+func runSyncCommand(platform string, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("sync", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [options] sync\n\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return fmt.Errorf("sync does not take positional arguments (got %q)", flags.Args())
+	}
+	if config.localMode {
+		return fmt.Errorf("sync needs API access, which this token does not have")
+	}
+	if config.db == nil {
+		return fmt.Errorf("the cache database is not available")
+	}
+
+	activities, issueActivities, err := fetchActivities(platform)
+	if err != nil {
+		return fmt.Errorf("failed to sync: %w", err)
+	}
+	if config.debugMode {
+		fmt.Fprintf(out, "Synced %d merge/pull requests and %d issues into the cache\n", len(activities), len(issueActivities))
+	}
+	return nil
+}
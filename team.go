@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// parseTeamUsers splits the --users value, dropping blanks, leading @ and
+// duplicates while keeping the given order.
+func parseTeamUsers(value string) []string {
+	var users []string
+	seen := make(map[string]bool)
+	for _, user := range strings.Split(value, ",") {
+		user = strings.TrimPrefix(strings.TrimSpace(user), "@")
+		if user == "" || seen[strings.ToLower(user)] {
+			continue
+		}
+		seen[strings.ToLower(user)] = true
+		users = append(users, user)
+	}
+	return users
+}
+
+// fetchAndDisplayTeamActivity renders one feed section per user, with labels
+// derived for that user, and reports whether any section has actionable
+// items. The runs bypass the cache, whose labels belong to the token's own
+// user, and ignore the user aliases for the same reason.
+func fetchAndDisplayTeamActivity(platform string, users []string, out io.Writer) (bool, error) {
+	db, aliases := config.db, config.userAliases
+	githubUsername, gitlabUsername, gitlabUserID := config.githubUsername, config.gitlabUsername, config.gitlabUserID
+	defer func() {
+		config.db, config.userAliases = db, aliases
+		config.githubUsername, config.gitlabUsername, config.gitlabUserID = githubUsername, gitlabUsername, gitlabUserID
+	}()
+	config.db, config.userAliases = nil, nil
+
+	actionable := false
+	for i, user := range users {
+		// Matching falls back to usernames when there is no user ID.
+		config.githubUsername, config.gitlabUsername, config.gitlabUserID = user, user, 0
+
+		activities, issueActivities, err := fetchActivities(platform)
+		if err != nil {
+			return actionable, fmt.Errorf("failed to fetch activity for %s: %w", user, err)
+		}
+
+		if config.countOnly {
+			fmt.Fprintf(out, "%s: ", user)
+		} else {
+			if i > 0 {
+				fmt.Fprintln(out)
+			}
+			displayTeamMemberHeading(out, user)
+		}
+		displayActivities(out, activities, issueActivities)
+		if hasActionableItems(activities, issueActivities) {
+			actionable = true
+		}
+	}
+	recordItemNumbers(db)
+	return actionable, nil
+}
+
+func displayTeamMemberHeading(out io.Writer, user string) {
+	fmt.Fprintln(out, color.New(color.FgHiCyan, color.Bold).Sprint(user))
+	fmt.Fprintln(out, strings.Repeat("=", 42))
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// colorTheme holds overrides from the [colors] section of the config file.
+// Unset entries fall back to the built-in palette.
+type colorTheme struct {
+	labels map[string][]color.Attribute
+	states map[string][]color.Attribute
+	users  [][]color.Attribute
+}
+
+var colorAttributes = map[string]color.Attribute{
+	"black":      color.FgBlack,
+	"red":        color.FgRed,
+	"green":      color.FgGreen,
+	"yellow":     color.FgYellow,
+	"blue":       color.FgBlue,
+	"magenta":    color.FgMagenta,
+	"cyan":       color.FgCyan,
+	"white":      color.FgWhite,
+	"hi-black":   color.FgHiBlack,
+	"hi-red":     color.FgHiRed,
+	"hi-green":   color.FgHiGreen,
+	"hi-yellow":  color.FgHiYellow,
+	"hi-blue":    color.FgHiBlue,
+	"hi-magenta": color.FgHiMagenta,
+	"hi-cyan":    color.FgHiCyan,
+	"hi-white":   color.FgHiWhite,
+	"bold":       color.Bold,
+	"faint":      color.Faint,
+	"italic":     color.Italic,
+	"underline":  color.Underline,
+}
+
+// parseColorSpec parses "hi-red bold" into color attributes.
+func parseColorSpec(spec string) ([]color.Attribute, error) {
+	fields := strings.Fields(strings.ToLower(spec))
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty color")
+	}
+
+	attrs := make([]color.Attribute, 0, len(fields))
+	for _, field := range fields {
+		attr, ok := colorAttributes[field]
+		if !ok {
+			names := make([]string, 0, len(colorAttributes))
+			for name := range colorAttributes {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("unknown color %q (allowed: %s)", field, strings.Join(names, ", "))
+		}
+		attrs = append(attrs, attr)
+	}
+	return attrs, nil
+}
+
+// parseColorTheme reads entries such as
+//
+//	label.involved = black
+//	state.open = hi-green bold
+//	users = blue, magenta, cyan
+func parseColorTheme(entries map[string]string) (colorTheme, error) {
+	theme := colorTheme{
+		labels: make(map[string][]color.Attribute),
+		states: make(map[string][]color.Attribute),
+	}
+
+	for key, value := range entries {
+		normalizedKey := strings.ToLower(strings.TrimSpace(key))
+		if normalizedKey == "users" {
+			for _, spec := range strings.Split(value, ",") {
+				attrs, err := parseColorSpec(spec)
+				if err != nil {
+					return colorTheme{}, fmt.Errorf("[colors] users: %w", err)
+				}
+				theme.users = append(theme.users, attrs)
+			}
+			continue
+		}
+
+		kind, name, ok := strings.Cut(normalizedKey, ".")
+		if !ok || (kind != "label" && kind != "state") {
+			return colorTheme{}, fmt.Errorf("[colors] unknown key %q (expected label.<name>, state.<open|closed|merged> or users)", key)
+		}
+		attrs, err := parseColorSpec(value)
+		if err != nil {
+			return colorTheme{}, fmt.Errorf("[colors] %s: %w", key, err)
+		}
+		if kind == "label" {
+			theme.labels[normalizeLabelKey(name)] = attrs
+		} else {
+			theme.states[strings.TrimSpace(name)] = attrs
+		}
+	}
+
+	return theme, nil
+}
+
+// loadEnvFileSection returns the key/value pairs under [section] in the .env
+// file. loadEnvFile skips section contents, so they never reach the environment.
+func loadEnvFileSection(path, section string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	entries := make(map[string]string)
+	current := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if name, ok := envFileSectionHeader(line); ok {
+			current = name
+			continue
+		}
+		if current != section {
+			continue
+		}
+		if key, value, ok := strings.Cut(line, "="); ok {
+			entries[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+
+	return entries, scanner.Err()
+}
+
+func envFileSectionHeader(line string) (string, bool) {
+	if len(line) < 2 || line[0] != '[' || line[len(line)-1] != ']' {
+		return "", false
+	}
+	return strings.ToLower(strings.TrimSpace(line[1 : len(line)-1])), true
+}
+
+// mustLoadColorTheme combines the .env [colors] section with the colors: block
+// of config.yaml; entries from config.yaml win.
+func mustLoadColorTheme(envPath string, fileColors map[string]string) colorTheme {
+	entries, err := loadEnvFileSection(envPath, "colors")
+	if err != nil {
+		entries = make(map[string]string)
+	}
+	for key, value := range fileColors {
+		entries[key] = value
+	}
+	theme, err := parseColorTheme(entries)
+	if err != nil {
+		fmt.Printf("Error: %s: %v\n", envPath, err)
+		os.Exit(1)
+	}
+	return theme
+}
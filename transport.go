@@ -0,0 +1,104 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+type transportOptions struct {
+	proxy              string
+	caCert             string
+	insecureSkipVerify bool
+}
+
+// newHTTPTransport builds the transport shared by the GitLab and GitHub
+// clients. Without --proxy it keeps Go's default behaviour of honoring
+// HTTP_PROXY, HTTPS_PROXY and NO_PROXY; an explicit proxy is used for every
+// request and ignores those variables.
+func newHTTPTransport(opts transportOptions) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+
+	if err := configureTLS(transport, opts); err != nil {
+		return nil, err
+	}
+
+	proxy := strings.TrimSpace(opts.proxy)
+	if proxy == "" {
+		return transport, nil
+	}
+
+	proxyURL, err := url.Parse(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --proxy %q: %w", proxy, err)
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("invalid --proxy %q: scheme must be http, https or socks5", proxy)
+	}
+	if proxyURL.Host == "" {
+		return nil, fmt.Errorf("invalid --proxy %q: missing host", proxy)
+	}
+	transport.Proxy = http.ProxyURL(proxyURL)
+	return transport, nil
+}
+
+// configureTLS trusts the PEM certificates in opts.caCert in addition to the
+// system roots, which is what self-managed instances behind a private CA need.
+func configureTLS(transport *http.Transport, opts transportOptions) error {
+	if opts.caCert == "" && !opts.insecureSkipVerify {
+		return nil
+	}
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if path := strings.TrimSpace(opts.caCert); path != "" {
+		pem, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read CA certificate: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no PEM certificates found in %s", path)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	tlsConfig.InsecureSkipVerify = opts.insecureSkipVerify
+	transport.TLSClientConfig = tlsConfig
+	return nil
+}
+
+// httpClient returns the client the API clients are built on; tests that never
+// set config.transport get Go's defaults. Every request carries userAgent and
+// is counted in config.apiCalls, every response updates config.rateLimit, and
+// with --log-file every request is logged. --debug-http adds the redacted URL
+// to those log entries, or prints them to stderr without --log-file. With
+// config.httpCacheDir set, repeated GETs are answered from httpCacheTransport
+// before they are counted.
+func httpClient() *http.Client {
+	var transport http.RoundTripper = http.DefaultTransport
+	if config.transport != nil {
+		transport = config.transport
+	}
+	transport = userAgentTransport{next: transport, agent: userAgent()}
+	transport = rateLimitTransport{next: transport}
+	transport = apiCallTransport{next: transport}
+	if config.httpCacheDir != "" {
+		transport = httpCacheTransport{next: transport, dir: config.httpCacheDir, key: config.cacheKey}
+	}
+	if runLogger != nil {
+		transport = loggingTransport{next: transport}
+	} else if config.debugHTTP {
+		transport = httpDebugTransport{next: transport, out: os.Stderr}
+	}
+	return &http.Client{Transport: transport}
+}
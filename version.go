@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"strings"
+)
+
+// version, commit and date are set at build time by GoReleaser
+// (-X main.version=... in .goreleaser.yml). Builds without ldflags fall back
+// to what the Go toolchain embeds: the module version for `go install` and
+// the VCS revision and time for builds from a checkout.
+var (
+	version string
+	commit  string
+	date    string
+)
+
+type buildMetadata struct {
+	Version string
+	Commit  string
+	Date    string
+	// Modified is set for builds from a checkout with uncommitted changes.
+	Modified bool
+}
+
+func resolveBuildMetadata() buildMetadata {
+	meta := buildMetadata{Version: version, Commit: commit, Date: date}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if meta.Version == "" && info.Main.Version != "" && info.Main.Version != "(devel)" {
+			meta.Version = info.Main.Version
+		}
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if meta.Commit == "" {
+					meta.Commit = setting.Value
+				}
+			case "vcs.time":
+				if meta.Date == "" {
+					meta.Date = setting.Value
+				}
+			case "vcs.modified":
+				meta.Modified = commit == "" && setting.Value == "true"
+			}
+		}
+	}
+	if meta.Version == "" {
+		meta.Version = "dev"
+	}
+	return meta
+}
+
+// formatVersion is the --version output, e.g.
+// "git-feed 1.4.0 (commit 3f2a9c1, built 2026-03-02T10:00:00Z)".
+func formatVersion(meta buildMetadata) string {
+	var details []string
+	if meta.Commit != "" {
+		commit := meta.Commit
+		if len(commit) > 12 {
+			commit = commit[:12]
+		}
+		if meta.Modified {
+			commit += "-dirty"
+		}
+		details = append(details, "commit "+commit)
+	}
+	if meta.Date != "" {
+		details = append(details, "built "+meta.Date)
+	}
+	if len(details) == 0 {
+		return "git-feed " + meta.Version
+	}
+	return fmt.Sprintf("git-feed %s (%s)", meta.Version, strings.Join(details, ", "))
+}
+
+// userAgent identifies the client to instance admins, e.g. "git-feed/1.4.0".
+func userAgent() string {
+	return "git-feed/" + strings.TrimPrefix(resolveBuildMetadata().Version, "v")
+}
+
+// userAgentTransport puts userAgent in front of the API library's own
+// User-Agent, so requests read "git-feed/1.4.0 go-github/v57.0.0".
+type userAgentTransport struct {
+	next  http.RoundTripper
+	agent string
+}
+
+func (t userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	agent := t.agent
+	if existing := req.Header.Get("User-Agent"); existing != "" {
+		agent += " " + existing
+	}
+	req.Header.Set("User-Agent", agent)
+	return t.next.RoundTrip(req)
+}
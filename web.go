@@ -0,0 +1,320 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+// webFeed is the dashboard's /feed.json: the export format plus when the
+// cache was last synced, so the page can show how stale it is.
+type webFeed struct {
+	exportFeed
+	LastSync *time.Time `json:"last_sync,omitempty"`
+}
+
+// webDashboard serves the cached feed. The cache is opened for each refresh
+// and closed again, so a `sync` cron job can write to it in between (the bolt
+// backend allows one process at a time).
+type webDashboard struct {
+	platform  string
+	openCache func() (Database, error)
+	refresh   time.Duration
+
+	// mu serializes refreshes; fetchActivities works on the global config.
+	mu sync.Mutex
+}
+
+func runWebCommand(platform string, args []string, openCache func() (Database, error), out io.Writer) error {
+	flags := flag.NewFlagSet("web", flag.ContinueOnError)
+	listen := flags.String("listen", "localhost:8080", "Address to serve the dashboard on; use :8080 to make it reachable from other machines")
+	refresh := flags.Duration("refresh", time.Minute, "How often the page reloads the feed from the cache")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [options] web [--listen :8080] [--refresh 1m]\n\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return fmt.Errorf("web does not take positional arguments (got %q)", flags.Args())
+	}
+	if *refresh < time.Second {
+		return fmt.Errorf("--refresh must be at least 1s (got %s)", *refresh)
+	}
+
+	dashboard := &webDashboard{platform: platform, openCache: openCache, refresh: *refresh}
+	server := &http.Server{
+		Addr:              *listen,
+		Handler:           dashboard.handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Fprintf(out, "Serving the cached feed on http://%s (Ctrl+C to stop); keep it current with `%s sync`\n", displayListenAddress(*listen), os.Args[0])
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// displayListenAddress turns ":8080" into a clickable "localhost:8080".
+func displayListenAddress(listen string) string {
+	if len(listen) > 0 && listen[0] == ':' {
+		return "localhost" + listen
+	}
+	return listen
+}
+
+func (d *webDashboard) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", d.serveIndex)
+	mux.HandleFunc("GET /feed.json", d.serveFeed)
+	mux.HandleFunc("GET /metrics", d.serveMetrics)
+	return mux
+}
+
+func (d *webDashboard) serveIndex(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	data := struct {
+		Platform      string
+		RefreshMillis int64
+	}{d.platform, d.refresh.Milliseconds()}
+	if err := webIndexTemplate.Execute(w, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// serveFeed reads the feed from the cache in the export format. A failed
+// refresh (e.g. while `sync` holds the cache) answers 503 and the page keeps
+// showing the previous feed.
+func (d *webDashboard) serveFeed(w http.ResponseWriter, r *http.Request) {
+	feed, err := d.loadFeed(time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(feed)
+}
+
+// serveMetrics answers a Prometheus scrape with the sync totals the cache
+// keeps (see syncMetrics). The dashboard itself reads the cache only, so the
+// numbers come from the `sync` runs that keep it current.
+func (d *webDashboard) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	metrics, err := d.loadMetrics()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	writePrometheusMetrics(w, metrics)
+}
+
+func (d *webDashboard) loadMetrics() (syncMetrics, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	db, err := d.openCache()
+	if err != nil {
+		return syncMetrics{}, fmt.Errorf("failed to open the cache: %w", err)
+	}
+	defer db.Close()
+	return db.SyncMetrics()
+}
+
+func (d *webDashboard) loadFeed(now time.Time) (webFeed, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	db, err := d.openCache()
+	if err != nil {
+		return webFeed{}, fmt.Errorf("failed to open the cache: %w", err)
+	}
+	defer db.Close()
+	config.db = db
+	defer func() { config.db = nil }()
+
+	activities, issueActivities, err := fetchActivities(d.platform)
+	if err != nil {
+		return webFeed{}, fmt.Errorf("failed to read the feed: %w", err)
+	}
+
+	feed := webFeed{exportFeed: buildExportFeed(d.platform, activities, issueActivities, now.UTC(), nil)}
+	if lastSync, err := db.LastSync(); err == nil && !lastSync.IsZero() {
+		lastSync = lastSync.UTC()
+		feed.LastSync = &lastSync
+	}
+	return feed, nil
+}
+
+// webIndexTemplate is the whole dashboard: it polls /feed.json and filters in
+// the browser, so it needs no assets and works offline on the LAN.
+var webIndexTemplate = template.Must(template.New("web").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>git-feed ({{.Platform}})</title>
+<style>
+  body { font-family: system-ui, sans-serif; margin: 0; background: #111; color: #ddd; }
+  header { position: sticky; top: 0; display: flex; gap: .75em; align-items: center; flex-wrap: wrap; padding: .75em 1em; background: #1c1c1c; border-bottom: 1px solid #333; }
+  header h1 { font-size: 1.1em; margin: 0 1em 0 0; }
+  input, select { background: #222; color: #ddd; border: 1px solid #444; border-radius: 4px; padding: .3em .5em; }
+  #status { margin-left: auto; color: #888; font-size: .9em; }
+  #status.error { color: #e55; }
+  main { padding: .5em 1em 2em; }
+  h2 { font-size: .95em; color: #888; text-transform: uppercase; margin: 1.5em 0 .5em; }
+  .item { display: flex; gap: .6em; align-items: baseline; padding: .3em 0; border-bottom: 1px solid #222; }
+  .item.nested { padding-left: 2em; }
+  .item.updated .title::before { content: "\25CF "; color: #e5c07b; }
+  .label { font-size: .75em; font-weight: bold; min-width: 11em; color: #61afef; }
+  .state { font-size: .75em; min-width: 4.5em; }
+  .state.open { color: #98c379; } .state.closed { color: #e06c75; } .state.merged { color: #c678dd; }
+  .ref { color: #888; white-space: nowrap; }
+  a { color: #ddd; text-decoration: none; } a:hover { text-decoration: underline; }
+  .meta { color: #888; font-size: .85em; margin-left: auto; white-space: nowrap; }
+  .tag { font-size: .75em; border: 1px solid #555; border-radius: 3px; padding: 0 .3em; color: #aaa; }
+</style>
+</head>
+<body>
+<header>
+  <h1>git-feed</h1>
+  <input id="filter" type="search" placeholder="Filter by title, project, user or label" size="36" autofocus>
+  <select id="label"><option value="">All involvement</option></select>
+  <select id="state">
+    <option value="">All states</option>
+    <option value="open">Open</option>
+    <option value="merged">Merged</option>
+    <option value="closed">Closed</option>
+  </select>
+  <span id="status">Loading...</span>
+</header>
+<main id="feed"></main>
+<script>
+const refreshMillis = {{.RefreshMillis}};
+const mrSeparator = {{.Platform}} === "gitlab" ? "!" : "#";
+let feed = null;
+
+function el(tag, className, text) {
+  const node = document.createElement(tag);
+  if (className) node.className = className;
+  if (text !== undefined) node.textContent = text;
+  return node;
+}
+
+function ago(value) {
+  const minutes = Math.max(0, Math.round((Date.now() - new Date(value)) / 60000));
+  if (minutes < 60) return minutes + "m ago";
+  if (minutes < 48 * 60) return Math.round(minutes / 60) + "h ago";
+  return Math.round(minutes / 1440) + "d ago";
+}
+
+function matches(item, query, label, state) {
+  if (label && item.label !== label) return false;
+  if (state && item.state !== state) return false;
+  if (!query) return true;
+  const haystack = [item.title, item.project, item.author, item.label]
+    .concat(item.assignees || [], item.reviewers || [], item.labels || [])
+    .join(" ").toLowerCase();
+  return haystack.includes(query);
+}
+
+function row(item, separator, nested) {
+  const node = el("div", "item" + (nested ? " nested" : "") + (item.has_updates ? " updated" : ""));
+  node.append(el("span", "label", item.label.toUpperCase()));
+  node.append(el("span", "state " + item.state, item.state.toUpperCase()));
+  node.append(el("span", "ref", item.project + separator + item.number));
+  const title = el(item.url ? "a" : "span", "title", item.title);
+  if (item.url) { title.href = item.url; title.target = "_blank"; title.rel = "noopener"; }
+  node.append(title);
+  for (const name of item.labels || []) node.append(el("span", "tag", name));
+  node.append(el("span", "meta", "@" + item.author + " · " + ago(item.updated_at)));
+  return node;
+}
+
+function render() {
+  if (!feed) return;
+  const query = document.getElementById("filter").value.trim().toLowerCase();
+  const label = document.getElementById("label").value;
+  const state = document.getElementById("state").value;
+  const root = document.getElementById("feed");
+  root.replaceChildren();
+
+  const mrs = el("section");
+  for (const mr of feed.merge_requests) {
+    const issues = (mr.issues || []).filter(issue => matches(issue, query, label, state));
+    if (!matches(mr, query, label, state) && issues.length === 0) continue;
+    mrs.append(row(mr, mrSeparator, false));
+    for (const issue of issues) mrs.append(row(issue, "#", true));
+  }
+  const issues = el("section");
+  for (const issue of feed.issues) {
+    if (matches(issue, query, label, state)) issues.append(row(issue, "#", false));
+  }
+  root.append(el("h2", "", feed.platform === "gitlab" ? "Merge requests" : "Pull requests"), mrs);
+  root.append(el("h2", "", "Issues"), issues);
+  if (!mrs.childElementCount && !issues.childElementCount) root.append(el("p", "", "No matching activity."));
+}
+
+function updateLabels() {
+  const select = document.getElementById("label");
+  const current = select.value;
+  const labels = new Set();
+  for (const mr of feed.merge_requests) {
+    labels.add(mr.label);
+    for (const issue of mr.issues || []) labels.add(issue.label);
+  }
+  for (const issue of feed.issues) labels.add(issue.label);
+  select.replaceChildren(el("option", "", "All involvement"));
+  select.firstChild.value = "";
+  for (const name of Array.from(labels).sort()) {
+    const option = el("option", "", name);
+    option.value = name;
+    select.append(option);
+  }
+  select.value = labels.has(current) ? current : "";
+}
+
+async function load() {
+  const status = document.getElementById("status");
+  try {
+    const response = await fetch("feed.json", { cache: "no-store" });
+    if (!response.ok) throw new Error((await response.text()).trim() || response.statusText);
+    feed = await response.json();
+    updateLabels();
+    render();
+    status.className = "";
+    status.textContent = feed.last_sync ? "Synced " + ago(feed.last_sync) : "Never synced";
+  } catch (err) {
+    status.className = "error";
+    status.textContent = "Refresh failed: " + err.message;
+  }
+}
+
+for (const id of ["filter", "label", "state"]) document.getElementById(id).addEventListener("input", render);
+load();
+setInterval(load, refreshMillis);
+</script>
+</body>
+</html>
+`))